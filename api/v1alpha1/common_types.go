@@ -33,9 +33,15 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // CopyMethodType defines the methods for creating point-in-time copies of
 // volumes.
-// +kubebuilder:validation:Enum=Direct;None;Clone;Snapshot
+// +kubebuilder:validation:Enum=Direct;None;Clone;Snapshot;FreezeSnapshot
 type CopyMethodType string
 
 const (
@@ -48,11 +54,35 @@ const (
 	// CopyMethodSnapshot indicates a copy should be created using a volume
 	// snapshot.
 	CopyMethodSnapshot CopyMethodType = "Snapshot"
+	// CopyMethodFreezeSnapshot indicates a copy should be created using a
+	// volume snapshot, while the mounted filesystem is frozen so that the
+	// snapshot is application-consistent. Only valid for a source, and
+	// requires privileged movers to be allowed in the namespace.
+	CopyMethodFreezeSnapshot CopyMethodType = "FreezeSnapshot"
 
 	// Namespace annotation to indicate that elevated permissions are ok for movers
 	PrivilegedMoversNamespaceAnnotation = "volsync.backube/privileged-movers"
 )
 
+// DeletionPolicyType defines what happens to the volumes and snapshots created
+// by VolSync when a ReplicationDestination is deleted.
+// +kubebuilder:validation:Enum=Delete;Retain;RetainLatest
+type DeletionPolicyType string
+
+const (
+	// DeletionPolicyDelete indicates the volumes and snapshots are deleted
+	// along with the object, except for snapshots with the do-not-delete label.
+	DeletionPolicyDelete DeletionPolicyType = "Delete"
+	// DeletionPolicyRetain indicates the volume receiving the data and the
+	// retained images are kept. Other objects, like caches and the temporary
+	// ones used during a synchronization, are deleted.
+	DeletionPolicyRetain DeletionPolicyType = "Retain"
+	// DeletionPolicyRetainLatest indicates the volume receiving the data and
+	// the latest image are kept, while the snapshots of previous
+	// synchronizations are deleted.
+	DeletionPolicyRetainLatest DeletionPolicyType = "RetainLatest"
+)
+
 const (
 	ConditionSynchronizing     string = "Synchronizing"
 	SynchronizingReasonSync    string = "SyncInProgress"
@@ -62,6 +92,12 @@ const (
 	SynchronizingReasonError   string = "Error"
 )
 
+const (
+	ConditionVolumeExpansion        string = "VolumeExpansion"
+	VolumeExpansionReasonExpanding  string = "Expanding"
+	VolumeExpansionReasonNotAllowed string = "ExpansionNotAllowed"
+)
+
 // SyncthingPeer Defines the necessary information needed by VolSync
 // to configure a given peer with the running Syncthing instance.
 type SyncthingPeer struct {
@@ -77,6 +113,107 @@ type SyncthingPeer struct {
 	Introducer bool `json:"introducer"`
 }
 
+// SyncthingFolderType determines in which direction changes to the synced
+// folder are exchanged with the peers.
+// +kubebuilder:validation:Enum=sendreceive;sendonly;receiveonly
+type SyncthingFolderType string
+
+const (
+	// SyncthingFolderTypeSendReceive sends local changes to the peers and
+	// receives theirs.
+	SyncthingFolderTypeSendReceive SyncthingFolderType = "sendreceive"
+	// SyncthingFolderTypeSendOnly sends local changes to the peers but
+	// ignores theirs.
+	SyncthingFolderTypeSendOnly SyncthingFolderType = "sendonly"
+	// SyncthingFolderTypeReceiveOnly receives the changes from the peers but
+	// never sends local ones.
+	SyncthingFolderTypeReceiveOnly SyncthingFolderType = "receiveonly"
+)
+
+// SyncthingVersioningType is the strategy used by Syncthing to keep the
+// previous versions of files.
+// +kubebuilder:validation:Enum=simple;staggered;trashcan
+type SyncthingVersioningType string
+
+const (
+	// SyncthingVersioningSimple keeps a fixed number of versions of each file.
+	SyncthingVersioningSimple SyncthingVersioningType = "simple"
+	// SyncthingVersioningStaggered keeps fewer versions the older they get.
+	SyncthingVersioningStaggered SyncthingVersioningType = "staggered"
+	// SyncthingVersioningTrashcan keeps only the latest version of each file.
+	SyncthingVersioningTrashcan SyncthingVersioningType = "trashcan"
+)
+
+// SyncthingVersioningSpec configures how Syncthing keeps the previous
+// versions of files that are replaced or deleted by a peer. The versions are
+// kept in the .stversions directory of the synced volume.
+type SyncthingVersioningSpec struct {
+	// type is the versioning strategy to use.
+	Type SyncthingVersioningType `json:"type"`
+	// keep is the number of versions of each file to keep with the simple
+	// strategy. Defaults to 5.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	Keep *int32 `json:"keep,omitempty"`
+	// retentionDays is the number of days after which versions are removed.
+	// Defaults to 0, meaning that versions are kept forever.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	RetentionDays *int32 `json:"retentionDays,omitempty"`
+}
+
+// SyncthingGlobalDiscoverySpec configures Syncthing's global discovery, which
+// announces the addresses of the device to discovery servers so that peers
+// using the "dynamic" address can find it.
+type SyncthingGlobalDiscoverySpec struct {
+	// enabled turns on global discovery.
+	Enabled bool `json:"enabled"`
+	// servers are the URLs of the discovery servers to use instead of the
+	// public ones, such as "https://discovery.example.com/?id=<server ID>".
+	//+optional
+	Servers []string `json:"servers,omitempty"`
+}
+
+// SyncthingRelaysSpec configures the relays through which Syncthing can be
+// reached when the peers can't connect to it directly.
+type SyncthingRelaysSpec struct {
+	// enabled turns on relaying.
+	Enabled bool `json:"enabled"`
+	// servers are the URIs of the relays to use instead of the public relay
+	// pool, such as "relay://relay.example.com:22067/?id=<relay ID>".
+	//+optional
+	Servers []string `json:"servers,omitempty"`
+}
+
+// SyncthingBandwidthSpec limits the rate at which Syncthing transfers data.
+type SyncthingBandwidthSpec struct {
+	// sendLimit is the maximum rate, in bytes per second, at which data is
+	// sent to the peers (e.g. "10Mi").
+	//+optional
+	SendLimit *resource.Quantity `json:"sendLimit,omitempty"`
+	// receiveLimit is the maximum rate, in bytes per second, at which data
+	// is received from the peers (e.g. "10Mi").
+	//+optional
+	ReceiveLimit *resource.Quantity `json:"receiveLimit,omitempty"`
+}
+
+// SyncthingConnectivityOptions control how Syncthing finds and connects to
+// the peers. By default, global discovery and relays are disabled so that the
+// device is never announced to public servers and only connects to the peers
+// directly.
+type SyncthingConnectivityOptions struct {
+	// globalDiscovery configures the announcement of the device to discovery
+	// servers.
+	//+optional
+	GlobalDiscovery *SyncthingGlobalDiscoverySpec `json:"globalDiscovery,omitempty"`
+	// relays configures the relays through which the device can be reached.
+	//+optional
+	Relays *SyncthingRelaysSpec `json:"relays,omitempty"`
+	// bandwidth limits the rate of the transfers with the peers.
+	//+optional
+	Bandwidth *SyncthingBandwidthSpec `json:"bandwidth,omitempty"`
+}
+
 // SyncthingPeerStatus Is a struct that contains information pertaining to
 // the status of a given Syncthing peer.
 type SyncthingPeerStatus struct {
@@ -90,6 +227,59 @@ type SyncthingPeerStatus struct {
 	IntroducedBy string `json:"introducedBy,omitempty"`
 	// A friendly name to associate the given device.
 	Name string `json:"name,omitempty"`
+	// completion is the percentage of the synced folder that the peer has
+	// in sync with the global state.
+	//+optional
+	Completion *int32 `json:"completion,omitempty"`
+	// outOfSyncBytes is the amount of data the peer still needs to receive
+	// to be in sync.
+	//+optional
+	OutOfSyncBytes *int64 `json:"outOfSyncBytes,omitempty"`
+}
+
+// SyncthingFileError is an error Syncthing encountered while syncing a file.
+type SyncthingFileError struct {
+	// path is the path of the file, relative to the root of the volume.
+	Path string `json:"path"`
+	// error is the error that prevented the file from being synced.
+	Error string `json:"error"`
+}
+
+// SyncthingFolderStatus describes how far along the folder holding the
+// volume is in syncing with the peers.
+type SyncthingFolderStatus struct {
+	// ID is the ID of the Syncthing folder.
+	ID string `json:"ID"`
+	// state is the current state of the folder as reported by Syncthing,
+	// such as idle, scanning, syncing or error.
+	//+optional
+	State string `json:"state,omitempty"`
+	// sequence is increased by Syncthing every time a file in the folder
+	// changes on any device.
+	//+optional
+	Sequence int64 `json:"sequence,omitempty"`
+	// completion is the percentage of the folder that is in sync locally.
+	Completion int32 `json:"completion"`
+	// outOfSyncBytes is the amount of data that still needs to be received
+	// for the volume to be in sync.
+	OutOfSyncBytes int64 `json:"outOfSyncBytes"`
+	// outOfSyncItems is the number of files and directories that still need
+	// to be received for the volume to be in sync.
+	OutOfSyncItems int64 `json:"outOfSyncItems"`
+	// errorCount is the number of files that could not be synced.
+	//+optional
+	ErrorCount int32 `json:"errorCount,omitempty"`
+	// errors lists the files that could not be synced. At most 10 are listed.
+	//+optional
+	Errors []SyncthingFileError `json:"errors,omitempty"`
+	// conflictCount is the number of conflict copies in the folder, created
+	// when a file was changed on more than one device at the same time.
+	//+optional
+	ConflictCount int32 `json:"conflictCount,omitempty"`
+	// conflicts lists the paths of the conflict copies. At most 10 are
+	// listed.
+	//+optional
+	Conflicts []string `json:"conflicts,omitempty"`
 }
 
 type MoverResult string
@@ -116,3 +306,123 @@ type CustomCASpec struct {
 	// The key within the Secret or ConfigMap containing the CA certificate
 	Key string `json:"key,omitempty"`
 }
+
+// MoverPodOptions can be used to set the resources and the scheduling of the
+// pods running the data mover, as well as additional labels and annotations.
+type MoverPodOptions struct {
+	// moverResources are the compute resources of the data mover containers.
+	//+optional
+	MoverResources *corev1.ResourceRequirements `json:"moverResources,omitempty"`
+	// moverNodeSelector is added to the node selector of the data mover pods.
+	// When the mover has to run on the node that uses the volume, the
+	// hostname label chosen by VolSync takes precedence.
+	//+optional
+	MoverNodeSelector map[string]string `json:"moverNodeSelector,omitempty"`
+	// moverTolerations are added to the tolerations of the data mover pods.
+	//+optional
+	MoverTolerations []corev1.Toleration `json:"moverTolerations,omitempty" yaml:",omitempty"`
+	// moverAffinity is the affinity of the data mover pods. It is combined
+	// with the affinity VolSync uses to place the mover with its volume.
+	//+optional
+	MoverAffinity *corev1.Affinity `json:"moverAffinity,omitempty"`
+	// moverPriorityClassName is the name of the PriorityClass of the data
+	// mover pods.
+	//+optional
+	MoverPriorityClassName *string `json:"moverPriorityClassName,omitempty"`
+	// moverPodLabels are added to the labels of the data mover pods. Labels
+	// set by VolSync take precedence.
+	//+optional
+	MoverPodLabels map[string]string `json:"moverPodLabels,omitempty"`
+	// moverPodAnnotations are added to the annotations of the data mover pods.
+	// Annotations set by VolSync take precedence.
+	//+optional
+	MoverPodAnnotations map[string]string `json:"moverPodAnnotations,omitempty"`
+}
+
+// RsyncTransferOptions can be used to tune how the rsync-based movers transfer
+// data. They apply to the side of the relationship that runs the transfer
+// client.
+type RsyncTransferOptions struct {
+	// bandwidthLimit is the maximum rate, in bytes per second, at which data
+	// will be transferred (e.g. "10Mi"). This also applies to block volumes.
+	//+optional
+	BandwidthLimit *resource.Quantity `json:"bandwidthLimit,omitempty"`
+	// compressionLevel sets the level of compression used during the transfer
+	// (1-9). A value of 0 disables compression. If not set, rsync's default
+	// level is used.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=9
+	//+optional
+	CompressionLevel *int32 `json:"compressionLevel,omitempty"`
+	// wholeFile, if true, copies changed files in their entirety instead of
+	// using the rsync delta-transfer algorithm.
+	//+optional
+	WholeFile *bool `json:"wholeFile,omitempty"`
+	// checksum, if true, compares files by their checksums instead of by size
+	// and modification time when deciding which files need to be transferred.
+	//+optional
+	Checksum *bool `json:"checksum,omitempty"`
+	// partial, if true, keeps partially transferred files so that an
+	// interrupted transfer can resume where it left off.
+	//+optional
+	Partial *bool `json:"partial,omitempty"`
+}
+
+// RsyncVerificationStatus is the result of comparing the contents of the
+// source and destination volumes without transferring any data.
+type RsyncVerificationStatus struct {
+	// tag is the value of the verify trigger that this result is for.
+	//+optional
+	Tag string `json:"tag,omitempty"`
+	// completionTime is the time the verification finished.
+	//+optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// inSync is true if no differences were found between the source and the
+	// destination.
+	//+optional
+	InSync *bool `json:"inSync,omitempty"`
+	// differingFiles is the number of files on the source that are either
+	// missing from or differ on the destination.
+	//+optional
+	DifferingFiles *int64 `json:"differingFiles,omitempty"`
+	// differingBytes is the total size of the differing files or, for block
+	// volumes, of the byte ranges that differ.
+	//+optional
+	DifferingBytes *int64 `json:"differingBytes,omitempty"`
+	// extraFiles is the number of files on the destination that do not exist
+	// on the source.
+	//+optional
+	ExtraFiles *int64 `json:"extraFiles,omitempty"`
+	// message contains additional information, such as the reason the
+	// verification could not be performed.
+	//+optional
+	Message string `json:"message,omitempty"`
+}
+
+// BlockTransferProgress is the progress of an ongoing transfer of a block
+// volume.
+type BlockTransferProgress struct {
+	// percent is how much of the volume has been synchronized so far.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=100
+	Percent int32 `json:"percent"`
+	// totalBytes is the size of the volume being transferred.
+	//+optional
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+	// bytesHashed is how much of the volume has been hashed to determine what
+	// needs to be transferred.
+	//+optional
+	BytesHashed int64 `json:"bytesHashed,omitempty"`
+	// bytesSynchronized is how much of the volume has been synchronized.
+	//+optional
+	BytesSynchronized int64 `json:"bytesSynchronized,omitempty"`
+	// bytesSent is how much data has been sent over the network.
+	//+optional
+	BytesSent int64 `json:"bytesSent,omitempty"`
+	// estimatedCompletionTime is when the transfer is expected to finish.
+	//+optional
+	EstimatedCompletionTime *metav1.Time `json:"estimatedCompletionTime,omitempty"`
+	// lastUpdateTime is when the mover reported this progress.
+	//+optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
@@ -30,6 +30,15 @@ const (
 	EvRPVCNotBound     = "PersistentVolumeClaimNotBound" // Warning
 	EvRSvcAddress      = "ServiceAddressAssigned"
 	EvRSvcNoAddress    = "NoServiceAddressAssigned" // Warning
+	EvRVerifyInSync    = "VerificationInSync"
+	EvRVerifyNotInSync = "VerificationNotInSync" // Warning
+	EvRCertRenewed     = "CertificateRenewed"
+	EvRFSFrozen        = "FilesystemFrozen"
+	EvRFSFreezeFailed  = "FilesystemFreezeFailed" // Warning
+	EvRPVCExpanding    = "PersistentVolumeClaimExpanding"
+	EvRPVCExpanded     = "PersistentVolumeClaimExpanded"
+	EvRPVCNotExpanded  = "PersistentVolumeClaimNotExpanded" // Warning
+	EvRPVCFull         = "PersistentVolumeClaimFull"        // Warning
 )
 
 // ReplicationSource/ReplicationDestination Event "action" strings: Things the controller "does"
@@ -39,6 +48,8 @@ const (
 	EvADeleteMover = "DeleteMover"
 	EvACreatePVC   = "CreatePersistentVolumeClaim"
 	EvACreateSnap  = "CreateVolumeSnapshot"
+	EvAFreezeFS    = "FreezeFilesystem"
+	EvAExpandPVC   = "ExpandPersistentVolumeClaim"
 )
 
 // Volume Populator Event "reason" strings
@@ -49,4 +60,6 @@ const (
 	EvRVolPopPVCReplicationDestNoLatestImage = "VolSyncPopulatorReplicationDestinationNoLatestImage"
 	EvRVolPopPVCCreationSuccess              = "VolSyncPopulatorPVCCreated"
 	EvRVolPopPVCCreationError                = "VolSyncPopulatorPVCCreationError"
+	EvRVolPopPVCRestoreComplete              = "VolSyncPopulatorRestoreComplete"
+	EvRVolPopPVCCopyComplete                 = "VolSyncPopulatorCopyComplete"
 )
@@ -84,6 +84,47 @@ type ReplicationDestinationVolumeOptions struct {
 	// accessModes must be specified.
 	//+optional
 	DestinationPVC *string `json:"destinationPVC,omitempty"`
+	// retain is the policy for keeping point-in-time images of previous
+	// syncs when copyMethod is Snapshot. If not set, only the latest image is
+	// kept.
+	//+optional
+	Retain *SnapshotRetainPolicy `json:"retain,omitempty"`
+}
+
+// SnapshotRetainPolicy describes which of the VolumeSnapshots taken at the end
+// of each sync are kept. The latest snapshot is always kept, and a snapshot is
+// kept if any of the rules selects it. Time periods are evaluated in UTC.
+type SnapshotRetainPolicy struct {
+	// last is the number of most recent snapshots to keep.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Last *int32 `json:"last,omitempty"`
+	// hourly is the number of hours for which the most recent snapshot is kept.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Hourly *int32 `json:"hourly,omitempty"`
+	// daily is the number of days for which the most recent snapshot is kept.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Daily *int32 `json:"daily,omitempty"`
+	// weekly is the number of weeks for which the most recent snapshot is kept.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Weekly *int32 `json:"weekly,omitempty"`
+	// monthly is the number of months for which the most recent snapshot is
+	// kept.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Monthly *int32 `json:"monthly,omitempty"`
+}
+
+// RetainedImage is a point-in-time image of a previous sync that is kept by
+// the retain policy.
+type RetainedImage struct {
+	// image is the object holding the replicated image.
+	Image corev1.TypedLocalObjectReference `json:"image"`
+	// creationTime is when the sync that produced the image completed.
+	CreationTime metav1.Time `json:"creationTime"`
 }
 
 type ReplicationDestinationRsyncSpec struct {
@@ -121,6 +162,8 @@ type ReplicationDestinationRsyncSpec struct {
 	// The service account needs to exist in the same namespace as the ReplicationDestination.
 	//+optional
 	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
 }
 
 // ReplicationDestinationRcloneSpec defines the field for rclone in replicationDestination.
@@ -143,6 +186,8 @@ type ReplicationDestinationRcloneSpec struct {
 	// The service account needs to exist in the same namespace as the ReplicationDestination.
 	//+optional
 	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
 }
 
 // ReplicationDestinationExternalSpec defines the configuration when using an
@@ -176,6 +221,9 @@ type ReplicationDestinationSpec struct {
 	// restic defines the configuration when using Restic-based replication.
 	//+optional
 	Restic *ReplicationDestinationResticSpec `json:"restic,omitempty"`
+	// syncthing defines the configuration when using Syncthing-based replication.
+	//+optional
+	Syncthing *ReplicationDestinationSyncthingSpec `json:"syncthing,omitempty"`
 	// external defines the configuration when using an external replication
 	// provider.
 	//+optional
@@ -183,6 +231,11 @@ type ReplicationDestinationSpec struct {
 	// paused can be used to temporarily stop replication. Defaults to "false".
 	//+optional
 	Paused bool `json:"paused,omitempty"`
+	// deletionPolicy determines whether the volume receiving the data and the
+	// images created by VolSync are kept when this object is deleted. Defaults
+	// to "Delete".
+	//+optional
+	DeletionPolicy DeletionPolicyType `json:"deletionPolicy,omitempty"`
 }
 
 type ReplicationDestinationRsyncStatus struct {
@@ -237,6 +290,65 @@ type ReplicationDestinationResticSpec struct {
 	// The service account needs to exist in the same namespace as the ReplicationDestination.
 	//+optional
 	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
+}
+
+// ReplicationDestinationSyncthingSpec defines the field for syncthing in
+// replicationDestination. The volume is shared with the peers as a receive-only
+// folder, so local changes to it are never sent back to them. A sync completes
+// each time the folder is idle and in sync with the peers, and with the
+// Snapshot copyMethod a new VolumeSnapshot of the volume is taken then.
+type ReplicationDestinationSyncthingSpec struct {
+	ReplicationDestinationVolumeOptions `json:",inline"`
+	// List of Syncthing peers to receive the data from
+	Peers []SyncthingPeer `json:"peers,omitempty"`
+	// Controls global discovery, relays and bandwidth limits.
+	SyncthingConnectivityOptions `json:",inline"`
+	// Type of service to be used when exposing the Syncthing peer
+	//+optional
+	ServiceType *corev1.ServiceType `json:"serviceType,omitempty"`
+	// Used to set the size of the Syncthing config volume.
+	//+optional
+	ConfigCapacity *resource.Quantity `json:"configCapacity,omitempty"`
+	// Used to set the StorageClass of the Syncthing config volume.
+	//+optional
+	ConfigStorageClassName *string `json:"configStorageClassName,omitempty"`
+	// Used to set the accessModes of Syncthing config volume.
+	//+optional
+	ConfigAccessModes []corev1.PersistentVolumeAccessMode `json:"configAccessModes,omitempty"`
+	// MoverSecurityContext allows specifying the PodSecurityContext that will
+	// be used by the data mover
+	MoverSecurityContext *corev1.PodSecurityContext `json:"moverSecurityContext,omitempty"`
+	// MoverServiceAccount allows specifying the name of the service account
+	// that will be used by the data mover. This should only be used by advanced
+	// users who want to override the service account normally used by the mover.
+	// The service account needs to exist in the same namespace as the ReplicationDestination.
+	//+optional
+	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
+}
+
+type ReplicationDestinationSyncthingStatus struct {
+	// List of the Syncthing nodes we are currently connected to.
+	Peers []SyncthingPeerStatus `json:"peers,omitempty"`
+	// Device ID of the current syncthing device
+	ID string `json:"ID,omitempty"`
+	// Service address where Syncthing is exposed to the rest of the world
+	Address string `json:"address,omitempty"`
+	// folder describes how far along the volume is in syncing with the peers.
+	//+optional
+	Folder *SyncthingFolderStatus `json:"folder,omitempty"`
+	// apiCertificateExpiry is when the certificate used to secure the
+	// Syncthing API expires. It is replaced ahead of that time.
+	//+optional
+	APICertificateExpiry *metav1.Time `json:"apiCertificateExpiry,omitempty"`
+	// lastSyncedSequence is the sequence number of the folder when the most
+	// recent sync completed. When no trigger is set, the next sync only
+	// completes once the folder has changed since then.
+	//+optional
+	LastSyncedSequence *int64 `json:"lastSyncedSequence,omitempty"`
 }
 
 // ReplicationDestinationStatus defines the observed state of ReplicationDestination
@@ -262,6 +374,10 @@ type ReplicationDestinationStatus struct {
 	// image.
 	//+optional
 	LatestImage *corev1.TypedLocalObjectReference `json:"latestImage,omitempty"`
+	// retainedImages lists the images kept by the retain policy, newest
+	// first. The first entry is the latestImage.
+	//+optional
+	RetainedImages []RetainedImage `json:"retainedImages,omitempty"`
 	// Logs/Summary from latest mover job
 	//+optional
 	LatestMoverStatus *MoverStatus `json:"latestMoverStatus,omitempty"`
@@ -269,6 +385,8 @@ type ReplicationDestinationStatus struct {
 	Rsync *ReplicationDestinationRsyncStatus `json:"rsync,omitempty"`
 	// rsyncTLS contains status information for Rsync-based replication over TLS.
 	RsyncTLS *ReplicationDestinationRsyncTLSStatus `json:"rsyncTLS,omitempty"`
+	// syncthing contains status information for Syncthing-based replication.
+	Syncthing *ReplicationDestinationSyncthingStatus `json:"syncthing,omitempty"`
 	// external contains provider-specific status information. For more details,
 	// please see the documentation of the specific replication provider being
 	// used.
@@ -286,6 +404,7 @@ type ReplicationDestinationStatus struct {
 // +kubebuilder:printcolumn:name="Last sync",type="string",format="date-time",JSONPath=`.status.lastSyncTime`
 // +kubebuilder:printcolumn:name="Duration",type="string",JSONPath=`.status.lastSyncDuration`
 // +kubebuilder:printcolumn:name="Next sync",type="string",format="date-time",JSONPath=`.status.nextSyncTime`
+// +kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=`.status.rsyncTLS.progress.percent`
 type ReplicationDestination struct {
 	metav1.TypeMeta `json:",inline"`
 	//+optional
@@ -38,6 +38,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // ReplicationSourceTriggerSpec defines when a volume will be synchronized with
@@ -88,7 +89,7 @@ type ReplicationSourceVolumeOptions struct {
 	//+optional
 	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
 	// volumeSnapshotClassName can be used to specify the VSC to be used if
-	// copyMethod is Snapshot. If not set, the default VSC is used.
+	// copyMethod is Snapshot or FreezeSnapshot. If not set, the default VSC is used.
 	//+optional
 	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
 }
@@ -117,12 +118,22 @@ type ReplicationSourceRsyncSpec struct {
 	// sshUser is the username for outgoing SSH connections. Defaults to "root".
 	//+optional
 	SSHUser *string `json:"sshUser,omitempty"`
+	// Options to tune how rsync transfers the data
+	RsyncTransferOptions `json:",inline"`
+	// verify is a string value that triggers a verification of the
+	// destination. When changed, the next iteration will compare the source
+	// and destination using checksums instead of transferring any data, and
+	// the result will be recorded in status.rsync.verification.
+	//+optional
+	Verify string `json:"verify,omitempty"`
 	// MoverServiceAccount allows specifying the name of the service account
 	// that will be used by the data mover. This should only be used by advanced
 	// users who want to override the service account normally used by the mover.
 	// The service account needs to exist in the same namespace as the ReplicationSource.
 	//+optional
 	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
 }
 
 // ReplicationSourceRcloneSpec defines the field for rclone in replicationSource.
@@ -145,6 +156,8 @@ type ReplicationSourceRcloneSpec struct {
 	// The service account needs to exist in the same namespace as the ReplicationSource.
 	//+optional
 	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
 }
 
 // ResticRetainPolicy defines the feilds for Restic backup
@@ -214,6 +227,8 @@ type ReplicationSourceResticSpec struct {
 	// The service account needs to exist in the same namespace as the ReplicationSource.
 	//+optional
 	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
 }
 
 // ReplicationSourceResticStatus defines the field for ReplicationSourceStatus in ReplicationSourceStatus
@@ -231,6 +246,20 @@ type ReplicationSourceResticStatus struct {
 type ReplicationSourceSyncthingSpec struct {
 	// List of Syncthing peers to be connected for syncing
 	Peers []SyncthingPeer `json:"peers,omitempty"`
+	// peerSelector selects other Syncthing ReplicationSources to be connected
+	// for syncing, in addition to the ones listed in peers. Their ID and
+	// address are read from their status, so they only become peers once
+	// their Syncthing instance is running. The selected ReplicationSources
+	// must select this one as well for the peers to connect.
+	//+optional
+	PeerSelector *metav1.LabelSelector `json:"peerSelector,omitempty"`
+	// peerNamespaceSelector selects the namespaces in which peerSelector
+	// looks for ReplicationSources. Defaults to the namespace of this
+	// ReplicationSource. ReplicationSources in other namespaces are only
+	// selected if they list the namespace of this ReplicationSource in their
+	// volsync.backube/syncthing-peer-namespaces annotation.
+	//+optional
+	PeerNamespaceSelector *metav1.LabelSelector `json:"peerNamespaceSelector,omitempty"`
 	// Type of service to be used when exposing the Syncthing peer
 	//+optional
 	ServiceType *corev1.ServiceType `json:"serviceType,omitempty"`
@@ -243,6 +272,22 @@ type ReplicationSourceSyncthingSpec struct {
 	// Used to set the accessModes of Syncthing config volume.
 	//+optional
 	ConfigAccessModes []corev1.PersistentVolumeAccessMode `json:"configAccessModes,omitempty"`
+	// folderType determines whether local changes are sent to the peers and
+	// whether their changes are received. Defaults to sendreceive.
+	//+optional
+	FolderType *SyncthingFolderType `json:"folderType,omitempty"`
+	// versioning keeps the previous versions of files that are replaced or
+	// deleted by a peer. No versions are kept if not set.
+	//+optional
+	Versioning *SyncthingVersioningSpec `json:"versioning,omitempty"`
+	// ignorePatterns lists the files that should not be synced, using the
+	// Syncthing ignore pattern syntax (https://docs.syncthing.net/users/ignoring.html).
+	// They take precedence over the patterns already in the volume's .stignore
+	// file.
+	//+optional
+	IgnorePatterns []string `json:"ignorePatterns,omitempty"`
+	// Controls global discovery, relays and bandwidth limits.
+	SyncthingConnectivityOptions `json:",inline"`
 	// MoverSecurityContext allows specifying the PodSecurityContext that will
 	// be used by the data mover
 	MoverSecurityContext *corev1.PodSecurityContext `json:"moverSecurityContext,omitempty"`
@@ -252,12 +297,42 @@ type ReplicationSourceSyncthingSpec struct {
 	// The service account needs to exist in the same namespace as the ReplicationSource.
 	//+optional
 	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
+}
+
+// ReplicationSourceSnapshotSpec selects existing VolumeSnapshots to replicate
+type ReplicationSourceSnapshotSpec struct {
+	// name is the name of the VolumeSnapshot to replicate.
+	//+optional
+	Name *string `json:"name,omitempty"`
+	// selector selects the VolumeSnapshots by label. The newest of the
+	// selected snapshots that is ready to use is replicated.
+	//+optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// ReplicationSourceSnapshotStatus identifies a VolumeSnapshot replicated via
+// sourceSnapshot
+type ReplicationSourceSnapshotStatus struct {
+	// name is the name of the VolumeSnapshot.
+	Name string `json:"name"`
+	// uid is the UID of the VolumeSnapshot, which tells it apart from a newer
+	// snapshot with the same name.
+	UID types.UID `json:"uid"`
 }
 
 // ReplicationSourceSpec defines the desired state of ReplicationSource
 type ReplicationSourceSpec struct {
 	// sourcePVC is the name of the PersistentVolumeClaim (PVC) to replicate.
 	SourcePVC string `json:"sourcePVC,omitempty"`
+	// sourceSnapshot selects existing VolumeSnapshots in the namespace of the
+	// ReplicationSource to replicate instead of sourcePVC. The data is
+	// replicated from a volume restored from the snapshot, so no point-in-time
+	// copy is taken and the copyMethod is ignored. Syncthing does not support
+	// it.
+	//+optional
+	SourceSnapshot *ReplicationSourceSnapshotSpec `json:"sourceSnapshot,omitempty"`
 	// trigger determines when the latest state of the volume will be captured
 	// (and potentially replicated to the destination).
 	//+optional
@@ -301,6 +376,10 @@ type ReplicationSourceRsyncStatus struct {
 	// connections.
 	//+optional
 	Port *int32 `json:"port,omitempty"`
+	// verification is the result of the most recent verification requested
+	// with .spec.rsync.verify.
+	//+optional
+	Verification *RsyncVerificationStatus `json:"verification,omitempty"`
 }
 
 type ReplicationSourceSyncthingStatus struct {
@@ -310,6 +389,13 @@ type ReplicationSourceSyncthingStatus struct {
 	ID string `json:"ID,omitempty"`
 	// Service address where Syncthing is exposed to the rest of the world
 	Address string `json:"address,omitempty"`
+	// folder describes how far along the volume is in syncing with the peers.
+	//+optional
+	Folder *SyncthingFolderStatus `json:"folder,omitempty"`
+	// apiCertificateExpiry is when the certificate used to secure the
+	// Syncthing API expires. It is replaced ahead of that time.
+	//+optional
+	APICertificateExpiry *metav1.Time `json:"apiCertificateExpiry,omitempty"`
 }
 
 // ReplicationSourceStatus defines the observed state of ReplicationSource
@@ -334,6 +420,12 @@ type ReplicationSourceStatus struct {
 	// Logs/Summary from latest mover job
 	//+optional
 	LatestMoverStatus *MoverStatus `json:"latestMoverStatus,omitempty"`
+	// lastSourceSnapshot is the VolumeSnapshot replicated by the most recent
+	// completed synchronization when sourceSnapshot is used. A
+	// synchronization completes without transferring any data if the
+	// selected snapshot is still the same.
+	//+optional
+	LastSourceSnapshot *ReplicationSourceSnapshotStatus `json:"lastSourceSnapshot,omitempty"`
 	// rsync contains status information for Rsync-based replication.
 	Rsync *ReplicationSourceRsyncStatus `json:"rsync,omitempty"`
 	// rsyncTLS contains status information for Rsync-based replication over TLS.
@@ -361,6 +453,7 @@ type ReplicationSourceStatus struct {
 // +kubebuilder:printcolumn:name="Last sync",type="string",format="date-time",JSONPath=`.status.lastSyncTime`
 // +kubebuilder:printcolumn:name="Duration",type="string",JSONPath=`.status.lastSyncDuration`
 // +kubebuilder:printcolumn:name="Next sync",type="string",format="date-time",JSONPath=`.status.nextSyncTime`
+// +kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=`.status.rsyncTLS.progress.percent`
 type ReplicationSource struct {
 	metav1.TypeMeta `json:",inline"`
 	//+optional
@@ -34,19 +34,41 @@ limitations under the License.
 // +kubebuilder:validation:Required
 package v1alpha1
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 /********************************************************************
  * Replication source types
  ********************************************************************/
 
+// ReplicationSourceRsyncTLSDestinationSpec defines one of several
+// destinations that a ReplicationSource will replicate to.
+type ReplicationSourceRsyncTLSDestinationSpec struct {
+	// address is the remote address to connect to for replication.
+	Address string `json:"address"`
+	// port is the port to connect to for replication. Defaults to 8000.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+	// keySecret is the name of a Secret that contains the TLS pre-shared key
+	// for this destination. If not provided, .spec.rsyncTLS.keySecret (or the
+	// generated key) will be used.
+	//+optional
+	KeySecret *string `json:"keySecret,omitempty"`
+}
+
 type ReplicationSourceRsyncTLSSpec struct {
 	ReplicationSourceVolumeOptions `json:",inline"`
 	// keySecret is the name of a Secret that contains the TLS pre-shared key to
 	// be used for authentication. If not provided, the key will be generated.
 	//+optional
 	KeySecret *string `json:"keySecret,omitempty"`
-	// address is the remote address to connect to for replication.
+	// address is the remote address to connect to for replication. If not
+	// provided, the source will instead create a Service and wait for the
+	// destination to connect to it.
 	//+optional
 	Address *string `json:"address,omitempty"`
 	// port is the port to connect to for replication. Defaults to 8000.
@@ -54,6 +76,28 @@ type ReplicationSourceRsyncTLSSpec struct {
 	//+kubebuilder:validation:Maximum=65535
 	//+optional
 	Port *int32 `json:"port,omitempty"`
+	// serviceType determines the Service type that will be created for incoming
+	// TLS connections when no address is provided.
+	//+optional
+	ServiceType *corev1.ServiceType `json:"serviceType,omitempty"`
+	// serviceAnnotations defines annotations that will be added to the
+	// service created for incoming TLS connections.  If set, these annotations
+	// will be used instead of any VolSync default values.
+	//+optional
+	ServiceAnnotations *map[string]string `json:"serviceAnnotations,omitempty"`
+	// destinations is a list of destinations to replicate to. If provided, the
+	// same point-in-time image will be sent to each destination in turn and
+	// address and port are ignored.
+	//+optional
+	Destinations []ReplicationSourceRsyncTLSDestinationSpec `json:"destinations,omitempty"`
+	// Options to tune how rsync transfers the data
+	RsyncTransferOptions `json:",inline"`
+	// verify is a string value that triggers a verification of the
+	// destination. When changed, the next iteration will compare the source
+	// and destination using checksums instead of transferring any data, and
+	// the result will be recorded in status.rsyncTLS.verification.
+	//+optional
+	Verify string `json:"verify,omitempty"`
 	// MoverSecurityContext allows specifying the PodSecurityContext that will
 	// be used by the data mover
 	MoverSecurityContext *corev1.PodSecurityContext `json:"moverSecurityContext,omitempty"`
@@ -63,6 +107,8 @@ type ReplicationSourceRsyncTLSSpec struct {
 	// The service account needs to exist in the same namespace as the ReplicationSource.
 	//+optional
 	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
 }
 
 type ReplicationSourceRsyncTLSStatus struct {
@@ -71,6 +117,46 @@ type ReplicationSourceRsyncTLSStatus struct {
 	// the key Secret will be generated and named here.
 	//+optional
 	KeySecret *string `json:"keySecret,omitempty"`
+	// address is the address to connect to for incoming TLS connections when
+	// the source is waiting for the destination to connect.
+	//+optional
+	Address *string `json:"address,omitempty"`
+	// port is the port to connect to for incoming replication connections.
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+	// destinations contains the status of each of the destinations listed in
+	// .spec.rsyncTLS.destinations.
+	//+optional
+	Destinations []ReplicationSourceRsyncTLSDestinationStatus `json:"destinations,omitempty"`
+	// verification is the result of the most recent verification requested
+	// with .spec.rsyncTLS.verify.
+	//+optional
+	Verification *RsyncVerificationStatus `json:"verification,omitempty"`
+	// progress is the progress of the ongoing transfer when the volume is a
+	// block volume.
+	//+optional
+	Progress *BlockTransferProgress `json:"progress,omitempty"`
+}
+
+// ReplicationSourceRsyncTLSDestinationStatus is the status of replication to
+// one of several destinations.
+type ReplicationSourceRsyncTLSDestinationStatus struct {
+	// address is the remote address of the destination.
+	Address string `json:"address"`
+	// port is the remote port of the destination, if one was given.
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+	// lastSyncTime is the time of the most recent successful synchronization
+	// to this destination.
+	//+optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// lastFailureTime is the time the most recent synchronization to this
+	// destination was given up on after the mover failed repeatedly.
+	//+optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+	// Logs/Summary from the latest mover job for this destination
+	//+optional
+	LatestMoverStatus *MoverStatus `json:"latestMoverStatus,omitempty"`
 }
 
 /********************************************************************
@@ -92,6 +178,20 @@ type ReplicationDestinationRsyncTLSSpec struct {
 	// will be used instead of any VolSync default values.
 	//+optional
 	ServiceAnnotations *map[string]string `json:"serviceAnnotations,omitempty"`
+	// address is the remote address of a listening ReplicationSource to
+	// connect to for replication. If provided, no Service will be created and
+	// the destination will make an outgoing connection to the source instead.
+	//+optional
+	Address *string `json:"address,omitempty"`
+	// port is the port to connect to for replication. Defaults to 8000.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+	// Other than the bandwidth limit for block volumes, the transfer options
+	// are only used when address is set since the destination is then the
+	// side that runs rsync.
+	RsyncTransferOptions `json:",inline"`
 	// MoverSecurityContext allows specifying the PodSecurityContext that will
 	// be used by the data mover
 	MoverSecurityContext *corev1.PodSecurityContext `json:"moverSecurityContext,omitempty"`
@@ -101,6 +201,8 @@ type ReplicationDestinationRsyncTLSSpec struct {
 	// The service account needs to exist in the same namespace as the ReplicationDestination.
 	//+optional
 	MoverServiceAccount *string `json:"moverServiceAccount,omitempty"`
+	// Resources, scheduling and metadata of the data mover pods
+	MoverPodOptions `json:",inline"`
 }
 
 type ReplicationDestinationRsyncTLSStatus struct {
@@ -115,4 +217,8 @@ type ReplicationDestinationRsyncTLSStatus struct {
 	// port is the port to connect to for incoming replication connections.
 	//+optional
 	Port *int32 `json:"port,omitempty"`
+	// progress is the progress of the ongoing transfer when the volume is a
+	// block volume.
+	//+optional
+	Progress *BlockTransferProgress `json:"progress,omitempty"`
 }
@@ -43,6 +43,29 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockTransferProgress) DeepCopyInto(out *BlockTransferProgress) {
+	*out = *in
+	if in.EstimatedCompletionTime != nil {
+		in, out := &in.EstimatedCompletionTime, &out.EstimatedCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockTransferProgress.
+func (in *BlockTransferProgress) DeepCopy() *BlockTransferProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockTransferProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomCASpec) DeepCopyInto(out *CustomCASpec) {
 	*out = *in
@@ -58,6 +81,64 @@ func (in *CustomCASpec) DeepCopy() *CustomCASpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MoverPodOptions) DeepCopyInto(out *MoverPodOptions) {
+	*out = *in
+	if in.MoverResources != nil {
+		in, out := &in.MoverResources, &out.MoverResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MoverNodeSelector != nil {
+		in, out := &in.MoverNodeSelector, &out.MoverNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MoverTolerations != nil {
+		in, out := &in.MoverTolerations, &out.MoverTolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MoverAffinity != nil {
+		in, out := &in.MoverAffinity, &out.MoverAffinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MoverPriorityClassName != nil {
+		in, out := &in.MoverPriorityClassName, &out.MoverPriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.MoverPodLabels != nil {
+		in, out := &in.MoverPodLabels, &out.MoverPodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MoverPodAnnotations != nil {
+		in, out := &in.MoverPodAnnotations, &out.MoverPodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MoverPodOptions.
+func (in *MoverPodOptions) DeepCopy() *MoverPodOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(MoverPodOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MoverStatus) DeepCopyInto(out *MoverStatus) {
 	*out = *in
@@ -188,6 +269,7 @@ func (in *ReplicationDestinationRcloneSpec) DeepCopyInto(out *ReplicationDestina
 		*out = new(string)
 		**out = **in
 	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationRcloneSpec.
@@ -255,6 +337,7 @@ func (in *ReplicationDestinationResticSpec) DeepCopyInto(out *ReplicationDestina
 		*out = new(string)
 		**out = **in
 	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationResticSpec.
@@ -317,6 +400,7 @@ func (in *ReplicationDestinationRsyncSpec) DeepCopyInto(out *ReplicationDestinat
 		*out = new(string)
 		**out = **in
 	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationRsyncSpec.
@@ -384,6 +468,17 @@ func (in *ReplicationDestinationRsyncTLSSpec) DeepCopyInto(out *ReplicationDesti
 			}
 		}
 	}
+	if in.Address != nil {
+		in, out := &in.Address, &out.Address
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	in.RsyncTransferOptions.DeepCopyInto(&out.RsyncTransferOptions)
 	if in.MoverSecurityContext != nil {
 		in, out := &in.MoverSecurityContext, &out.MoverSecurityContext
 		*out = new(v1.PodSecurityContext)
@@ -394,6 +489,7 @@ func (in *ReplicationDestinationRsyncTLSSpec) DeepCopyInto(out *ReplicationDesti
 		*out = new(string)
 		**out = **in
 	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationRsyncTLSSpec.
@@ -424,6 +520,11 @@ func (in *ReplicationDestinationRsyncTLSStatus) DeepCopyInto(out *ReplicationDes
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(BlockTransferProgress)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationRsyncTLSStatus.
@@ -464,6 +565,11 @@ func (in *ReplicationDestinationSpec) DeepCopyInto(out *ReplicationDestinationSp
 		*out = new(ReplicationDestinationResticSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Syncthing != nil {
+		in, out := &in.Syncthing, &out.Syncthing
+		*out = new(ReplicationDestinationSyncthingSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.External != nil {
 		in, out := &in.External, &out.External
 		*out = new(ReplicationDestinationExternalSpec)
@@ -506,6 +612,13 @@ func (in *ReplicationDestinationStatus) DeepCopyInto(out *ReplicationDestination
 		*out = new(v1.TypedLocalObjectReference)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RetainedImages != nil {
+		in, out := &in.RetainedImages, &out.RetainedImages
+		*out = make([]RetainedImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.LatestMoverStatus != nil {
 		in, out := &in.LatestMoverStatus, &out.LatestMoverStatus
 		*out = new(MoverStatus)
@@ -521,6 +634,11 @@ func (in *ReplicationDestinationStatus) DeepCopyInto(out *ReplicationDestination
 		*out = new(ReplicationDestinationRsyncTLSStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Syncthing != nil {
+		in, out := &in.Syncthing, &out.Syncthing
+		*out = new(ReplicationDestinationSyncthingStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.External != nil {
 		in, out := &in.External, &out.External
 		*out = make(map[string]string, len(*in))
@@ -547,6 +665,95 @@ func (in *ReplicationDestinationStatus) DeepCopy() *ReplicationDestinationStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationDestinationSyncthingSpec) DeepCopyInto(out *ReplicationDestinationSyncthingSpec) {
+	*out = *in
+	in.ReplicationDestinationVolumeOptions.DeepCopyInto(&out.ReplicationDestinationVolumeOptions)
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]SyncthingPeer, len(*in))
+		copy(*out, *in)
+	}
+	in.SyncthingConnectivityOptions.DeepCopyInto(&out.SyncthingConnectivityOptions)
+	if in.ServiceType != nil {
+		in, out := &in.ServiceType, &out.ServiceType
+		*out = new(v1.ServiceType)
+		**out = **in
+	}
+	if in.ConfigCapacity != nil {
+		in, out := &in.ConfigCapacity, &out.ConfigCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ConfigStorageClassName != nil {
+		in, out := &in.ConfigStorageClassName, &out.ConfigStorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ConfigAccessModes != nil {
+		in, out := &in.ConfigAccessModes, &out.ConfigAccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.MoverSecurityContext != nil {
+		in, out := &in.MoverSecurityContext, &out.MoverSecurityContext
+		*out = new(v1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MoverServiceAccount != nil {
+		in, out := &in.MoverServiceAccount, &out.MoverServiceAccount
+		*out = new(string)
+		**out = **in
+	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationSyncthingSpec.
+func (in *ReplicationDestinationSyncthingSpec) DeepCopy() *ReplicationDestinationSyncthingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationDestinationSyncthingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationDestinationSyncthingStatus) DeepCopyInto(out *ReplicationDestinationSyncthingStatus) {
+	*out = *in
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]SyncthingPeerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Folder != nil {
+		in, out := &in.Folder, &out.Folder
+		*out = new(SyncthingFolderStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APICertificateExpiry != nil {
+		in, out := &in.APICertificateExpiry, &out.APICertificateExpiry
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncedSequence != nil {
+		in, out := &in.LastSyncedSequence, &out.LastSyncedSequence
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationSyncthingStatus.
+func (in *ReplicationDestinationSyncthingStatus) DeepCopy() *ReplicationDestinationSyncthingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationDestinationSyncthingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicationDestinationTriggerSpec) DeepCopyInto(out *ReplicationDestinationTriggerSpec) {
 	*out = *in
@@ -595,6 +802,11 @@ func (in *ReplicationDestinationVolumeOptions) DeepCopyInto(out *ReplicationDest
 		*out = new(string)
 		**out = **in
 	}
+	if in.Retain != nil {
+		in, out := &in.Retain, &out.Retain
+		*out = new(SnapshotRetainPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationVolumeOptions.
@@ -722,6 +934,7 @@ func (in *ReplicationSourceRcloneSpec) DeepCopyInto(out *ReplicationSourceRclone
 		*out = new(string)
 		**out = **in
 	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceRcloneSpec.
@@ -789,6 +1002,7 @@ func (in *ReplicationSourceResticSpec) DeepCopyInto(out *ReplicationSourceRestic
 		*out = new(string)
 		**out = **in
 	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceResticSpec.
@@ -854,11 +1068,13 @@ func (in *ReplicationSourceRsyncSpec) DeepCopyInto(out *ReplicationSourceRsyncSp
 		*out = new(string)
 		**out = **in
 	}
+	in.RsyncTransferOptions.DeepCopyInto(&out.RsyncTransferOptions)
 	if in.MoverServiceAccount != nil {
 		in, out := &in.MoverServiceAccount, &out.MoverServiceAccount
 		*out = new(string)
 		**out = **in
 	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceRsyncSpec.
@@ -889,6 +1105,11 @@ func (in *ReplicationSourceRsyncStatus) DeepCopyInto(out *ReplicationSourceRsync
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(RsyncVerificationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceRsyncStatus.
@@ -901,6 +1122,64 @@ func (in *ReplicationSourceRsyncStatus) DeepCopy() *ReplicationSourceRsyncStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationSourceRsyncTLSDestinationSpec) DeepCopyInto(out *ReplicationSourceRsyncTLSDestinationSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeySecret != nil {
+		in, out := &in.KeySecret, &out.KeySecret
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceRsyncTLSDestinationSpec.
+func (in *ReplicationSourceRsyncTLSDestinationSpec) DeepCopy() *ReplicationSourceRsyncTLSDestinationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationSourceRsyncTLSDestinationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationSourceRsyncTLSDestinationStatus) DeepCopyInto(out *ReplicationSourceRsyncTLSDestinationStatus) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LatestMoverStatus != nil {
+		in, out := &in.LatestMoverStatus, &out.LatestMoverStatus
+		*out = new(MoverStatus)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceRsyncTLSDestinationStatus.
+func (in *ReplicationSourceRsyncTLSDestinationStatus) DeepCopy() *ReplicationSourceRsyncTLSDestinationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationSourceRsyncTLSDestinationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicationSourceRsyncTLSSpec) DeepCopyInto(out *ReplicationSourceRsyncTLSSpec) {
 	*out = *in
@@ -920,6 +1199,30 @@ func (in *ReplicationSourceRsyncTLSSpec) DeepCopyInto(out *ReplicationSourceRsyn
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ServiceType != nil {
+		in, out := &in.ServiceType, &out.ServiceType
+		*out = new(v1.ServiceType)
+		**out = **in
+	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = new(map[string]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make(map[string]string, len(*in))
+			for key, val := range *in {
+				(*out)[key] = val
+			}
+		}
+	}
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]ReplicationSourceRsyncTLSDestinationSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.RsyncTransferOptions.DeepCopyInto(&out.RsyncTransferOptions)
 	if in.MoverSecurityContext != nil {
 		in, out := &in.MoverSecurityContext, &out.MoverSecurityContext
 		*out = new(v1.PodSecurityContext)
@@ -930,6 +1233,7 @@ func (in *ReplicationSourceRsyncTLSSpec) DeepCopyInto(out *ReplicationSourceRsyn
 		*out = new(string)
 		**out = **in
 	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceRsyncTLSSpec.
@@ -950,6 +1254,33 @@ func (in *ReplicationSourceRsyncTLSStatus) DeepCopyInto(out *ReplicationSourceRs
 		*out = new(string)
 		**out = **in
 	}
+	if in.Address != nil {
+		in, out := &in.Address, &out.Address
+		*out = new(string)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]ReplicationSourceRsyncTLSDestinationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(RsyncVerificationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(BlockTransferProgress)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceRsyncTLSStatus.
@@ -962,9 +1293,54 @@ func (in *ReplicationSourceRsyncTLSStatus) DeepCopy() *ReplicationSourceRsyncTLS
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationSourceSnapshotSpec) DeepCopyInto(out *ReplicationSourceSnapshotSpec) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceSnapshotSpec.
+func (in *ReplicationSourceSnapshotSpec) DeepCopy() *ReplicationSourceSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationSourceSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationSourceSnapshotStatus) DeepCopyInto(out *ReplicationSourceSnapshotStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceSnapshotStatus.
+func (in *ReplicationSourceSnapshotStatus) DeepCopy() *ReplicationSourceSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationSourceSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicationSourceSpec) DeepCopyInto(out *ReplicationSourceSpec) {
 	*out = *in
+	if in.SourceSnapshot != nil {
+		in, out := &in.SourceSnapshot, &out.SourceSnapshot
+		*out = new(ReplicationSourceSnapshotSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Trigger != nil {
 		in, out := &in.Trigger, &out.Trigger
 		*out = new(ReplicationSourceTriggerSpec)
@@ -1037,6 +1413,11 @@ func (in *ReplicationSourceStatus) DeepCopyInto(out *ReplicationSourceStatus) {
 		*out = new(MoverStatus)
 		**out = **in
 	}
+	if in.LastSourceSnapshot != nil {
+		in, out := &in.LastSourceSnapshot, &out.LastSourceSnapshot
+		*out = new(ReplicationSourceSnapshotStatus)
+		**out = **in
+	}
 	if in.Rsync != nil {
 		in, out := &in.Rsync, &out.Rsync
 		*out = new(ReplicationSourceRsyncStatus)
@@ -1091,6 +1472,16 @@ func (in *ReplicationSourceSyncthingSpec) DeepCopyInto(out *ReplicationSourceSyn
 		*out = make([]SyncthingPeer, len(*in))
 		copy(*out, *in)
 	}
+	if in.PeerSelector != nil {
+		in, out := &in.PeerSelector, &out.PeerSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PeerNamespaceSelector != nil {
+		in, out := &in.PeerNamespaceSelector, &out.PeerNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ServiceType != nil {
 		in, out := &in.ServiceType, &out.ServiceType
 		*out = new(v1.ServiceType)
@@ -1111,6 +1502,22 @@ func (in *ReplicationSourceSyncthingSpec) DeepCopyInto(out *ReplicationSourceSyn
 		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
 		copy(*out, *in)
 	}
+	if in.FolderType != nil {
+		in, out := &in.FolderType, &out.FolderType
+		*out = new(SyncthingFolderType)
+		**out = **in
+	}
+	if in.Versioning != nil {
+		in, out := &in.Versioning, &out.Versioning
+		*out = new(SyncthingVersioningSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IgnorePatterns != nil {
+		in, out := &in.IgnorePatterns, &out.IgnorePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.SyncthingConnectivityOptions.DeepCopyInto(&out.SyncthingConnectivityOptions)
 	if in.MoverSecurityContext != nil {
 		in, out := &in.MoverSecurityContext, &out.MoverSecurityContext
 		*out = new(v1.PodSecurityContext)
@@ -1121,6 +1528,7 @@ func (in *ReplicationSourceSyncthingSpec) DeepCopyInto(out *ReplicationSourceSyn
 		*out = new(string)
 		**out = **in
 	}
+	in.MoverPodOptions.DeepCopyInto(&out.MoverPodOptions)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceSyncthingSpec.
@@ -1139,7 +1547,18 @@ func (in *ReplicationSourceSyncthingStatus) DeepCopyInto(out *ReplicationSourceS
 	if in.Peers != nil {
 		in, out := &in.Peers, &out.Peers
 		*out = make([]SyncthingPeerStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Folder != nil {
+		in, out := &in.Folder, &out.Folder
+		*out = new(SyncthingFolderStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APICertificateExpiry != nil {
+		in, out := &in.APICertificateExpiry, &out.APICertificateExpiry
+		*out = (*in).DeepCopy()
 	}
 }
 
@@ -1258,6 +1677,257 @@ func (in *ResticRetainPolicy) DeepCopy() *ResticRetainPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetainedImage) DeepCopyInto(out *RetainedImage) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	in.CreationTime.DeepCopyInto(&out.CreationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetainedImage.
+func (in *RetainedImage) DeepCopy() *RetainedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(RetainedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncTransferOptions) DeepCopyInto(out *RsyncTransferOptions) {
+	*out = *in
+	if in.BandwidthLimit != nil {
+		in, out := &in.BandwidthLimit, &out.BandwidthLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CompressionLevel != nil {
+		in, out := &in.CompressionLevel, &out.CompressionLevel
+		*out = new(int32)
+		**out = **in
+	}
+	if in.WholeFile != nil {
+		in, out := &in.WholeFile, &out.WholeFile
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Partial != nil {
+		in, out := &in.Partial, &out.Partial
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncTransferOptions.
+func (in *RsyncTransferOptions) DeepCopy() *RsyncTransferOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncTransferOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncVerificationStatus) DeepCopyInto(out *RsyncVerificationStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.InSync != nil {
+		in, out := &in.InSync, &out.InSync
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DifferingFiles != nil {
+		in, out := &in.DifferingFiles, &out.DifferingFiles
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DifferingBytes != nil {
+		in, out := &in.DifferingBytes, &out.DifferingBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ExtraFiles != nil {
+		in, out := &in.ExtraFiles, &out.ExtraFiles
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncVerificationStatus.
+func (in *RsyncVerificationStatus) DeepCopy() *RsyncVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRetainPolicy) DeepCopyInto(out *SnapshotRetainPolicy) {
+	*out = *in
+	if in.Last != nil {
+		in, out := &in.Last, &out.Last
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Hourly != nil {
+		in, out := &in.Hourly, &out.Hourly
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Daily != nil {
+		in, out := &in.Daily, &out.Daily
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Weekly != nil {
+		in, out := &in.Weekly, &out.Weekly
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Monthly != nil {
+		in, out := &in.Monthly, &out.Monthly
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRetainPolicy.
+func (in *SnapshotRetainPolicy) DeepCopy() *SnapshotRetainPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRetainPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncthingBandwidthSpec) DeepCopyInto(out *SyncthingBandwidthSpec) {
+	*out = *in
+	if in.SendLimit != nil {
+		in, out := &in.SendLimit, &out.SendLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReceiveLimit != nil {
+		in, out := &in.ReceiveLimit, &out.ReceiveLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncthingBandwidthSpec.
+func (in *SyncthingBandwidthSpec) DeepCopy() *SyncthingBandwidthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncthingBandwidthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncthingConnectivityOptions) DeepCopyInto(out *SyncthingConnectivityOptions) {
+	*out = *in
+	if in.GlobalDiscovery != nil {
+		in, out := &in.GlobalDiscovery, &out.GlobalDiscovery
+		*out = new(SyncthingGlobalDiscoverySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Relays != nil {
+		in, out := &in.Relays, &out.Relays
+		*out = new(SyncthingRelaysSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(SyncthingBandwidthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncthingConnectivityOptions.
+func (in *SyncthingConnectivityOptions) DeepCopy() *SyncthingConnectivityOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncthingConnectivityOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncthingFileError) DeepCopyInto(out *SyncthingFileError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncthingFileError.
+func (in *SyncthingFileError) DeepCopy() *SyncthingFileError {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncthingFileError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncthingFolderStatus) DeepCopyInto(out *SyncthingFolderStatus) {
+	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]SyncthingFileError, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncthingFolderStatus.
+func (in *SyncthingFolderStatus) DeepCopy() *SyncthingFolderStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncthingFolderStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncthingGlobalDiscoverySpec) DeepCopyInto(out *SyncthingGlobalDiscoverySpec) {
+	*out = *in
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncthingGlobalDiscoverySpec.
+func (in *SyncthingGlobalDiscoverySpec) DeepCopy() *SyncthingGlobalDiscoverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncthingGlobalDiscoverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncthingPeer) DeepCopyInto(out *SyncthingPeer) {
 	*out = *in
@@ -1276,6 +1946,16 @@ func (in *SyncthingPeer) DeepCopy() *SyncthingPeer {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncthingPeerStatus) DeepCopyInto(out *SyncthingPeerStatus) {
 	*out = *in
+	if in.Completion != nil {
+		in, out := &in.Completion, &out.Completion
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OutOfSyncBytes != nil {
+		in, out := &in.OutOfSyncBytes, &out.OutOfSyncBytes
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncthingPeerStatus.
@@ -1287,3 +1967,48 @@ func (in *SyncthingPeerStatus) DeepCopy() *SyncthingPeerStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncthingRelaysSpec) DeepCopyInto(out *SyncthingRelaysSpec) {
+	*out = *in
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncthingRelaysSpec.
+func (in *SyncthingRelaysSpec) DeepCopy() *SyncthingRelaysSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncthingRelaysSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncthingVersioningSpec) DeepCopyInto(out *SyncthingVersioningSpec) {
+	*out = *in
+	if in.Keep != nil {
+		in, out := &in.Keep, &out.Keep
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RetentionDays != nil {
+		in, out := &in.RetentionDays, &out.RetentionDays
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncthingVersioningSpec.
+func (in *SyncthingVersioningSpec) DeepCopy() *SyncthingVersioningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncthingVersioningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+// reconcileDeletionPolicy adds the finalizer that applies the deletion policy
+// to obj if the policy retains anything, and removes it otherwise. Once obj is
+// being deleted, the policy is applied and the finalizer removed. In this case
+// true is returned and obj must not be reconciled any further. Besides the data
+// volume of obj, the policy retains the snapshot named latestImage and, unless
+// it is RetainLatest, the snapshots of previous synchronizations named in
+// previousImages.
+func reconcileDeletionPolicy(ctx context.Context, c client.Client, logger logr.Logger, obj client.Object,
+	policy volsyncv1alpha1.DeletionPolicyType, latestImage string, previousImages []string) (bool, error) {
+	retain := policy == volsyncv1alpha1.DeletionPolicyRetain || policy == volsyncv1alpha1.DeletionPolicyRetainLatest
+
+	if obj.GetDeletionTimestamp().IsZero() {
+		updated := false
+		if retain {
+			updated = ctrlutil.AddFinalizer(obj, utils.DeletionPolicyFinalizer)
+		} else {
+			updated = ctrlutil.RemoveFinalizer(obj, utils.DeletionPolicyFinalizer)
+		}
+		if updated {
+			return false, c.Update(ctx, obj)
+		}
+		return false, nil
+	}
+
+	if !ctrlutil.ContainsFinalizer(obj, utils.DeletionPolicyFinalizer) {
+		return false, nil
+	}
+	// The policy may have been changed to Delete after the deletion started
+	if retain {
+		retainSnapshots := []string{}
+		if latestImage != "" {
+			retainSnapshots = append(retainSnapshots, latestImage)
+		}
+		if policy == volsyncv1alpha1.DeletionPolicyRetain {
+			retainSnapshots = append(retainSnapshots, previousImages...)
+		}
+		logger.Info("applying deletion policy", "policy", policy)
+		if err := utils.RelinquishRetainedObjects(ctx, c, logger, obj, retainSnapshots); err != nil {
+			return true, err
+		}
+	}
+	ctrlutil.RemoveFinalizer(obj, utils.DeletionPolicyFinalizer)
+	return true, c.Update(ctx, obj)
+}
+
+// latestImageName returns the name of the latest image of a
+// ReplicationDestination if it is a snapshot, or "" otherwise
+func latestImageName(rd *volsyncv1alpha1.ReplicationDestination) string {
+	if rd.Status == nil || rd.Status.LatestImage == nil || rd.Status.LatestImage.Kind != "VolumeSnapshot" {
+		return ""
+	}
+	return rd.Status.LatestImage.Name
+}
+
+// previousImageNames returns the names of the images of a
+// ReplicationDestination that are retained besides the latest image
+func previousImageNames(rd *volsyncv1alpha1.ReplicationDestination) []string {
+	if rd.Status == nil {
+		return nil
+	}
+	names := []string{}
+	for _, image := range rd.Status.RetainedImages {
+		if rd.Status.LatestImage == nil || image.Image.Name != rd.Status.LatestImage.Name {
+			names = append(names, image.Image.Name)
+		}
+	}
+	return names
+}
@@ -22,6 +22,8 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
 )
 
 const (
@@ -33,6 +35,9 @@ type volsyncMetrics struct {
 	MissedIntervals prometheus.Counter
 	OutOfSync       prometheus.Gauge
 	SyncDurations   prometheus.Observer
+	// labels of the metrics above, for the metrics that are only set by
+	// some movers
+	labels prometheus.Labels
 }
 
 var (
@@ -69,6 +74,38 @@ var (
 		},
 		metricLabels,
 	)
+	syncthingFolderCompletion = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "syncthing_folder_completion_percent",
+			Namespace: metricsNamespace,
+			Help:      "The percentage of the Syncthing folder that is in sync locally",
+		},
+		metricLabels,
+	)
+	syncthingOutOfSyncBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "syncthing_out_of_sync_bytes",
+			Namespace: metricsNamespace,
+			Help:      "The amount of data that still needs to be received for the Syncthing folder to be in sync",
+		},
+		metricLabels,
+	)
+	syncthingFolderErrors = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "syncthing_folder_errors",
+			Namespace: metricsNamespace,
+			Help:      "The number of files of the Syncthing folder that could not be synced",
+		},
+		metricLabels,
+	)
+	syncthingFolderConflicts = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "syncthing_folder_conflicts",
+			Namespace: metricsNamespace,
+			Help:      "The number of conflict copies in the Syncthing folder",
+		},
+		metricLabels,
+	)
 )
 
 func newVolSyncMetrics(labels prometheus.Labels) volsyncMetrics {
@@ -76,10 +113,28 @@ func newVolSyncMetrics(labels prometheus.Labels) volsyncMetrics {
 		MissedIntervals: missedIntervals.With(labels),
 		OutOfSync:       outOfSync.With(labels),
 		SyncDurations:   syncDurations.With(labels),
+		labels:          labels,
+	}
+}
+
+// SetSyncthingFolder updates the Syncthing folder metrics from the folder
+// status, or removes them if there is no status
+func (m volsyncMetrics) SetSyncthingFolder(status *volsyncv1alpha1.SyncthingFolderStatus) {
+	if status == nil {
+		syncthingFolderCompletion.Delete(m.labels)
+		syncthingOutOfSyncBytes.Delete(m.labels)
+		syncthingFolderErrors.Delete(m.labels)
+		syncthingFolderConflicts.Delete(m.labels)
+		return
 	}
+	syncthingFolderCompletion.With(m.labels).Set(float64(status.Completion))
+	syncthingOutOfSyncBytes.With(m.labels).Set(float64(status.OutOfSyncBytes))
+	syncthingFolderErrors.With(m.labels).Set(float64(status.ErrorCount))
+	syncthingFolderConflicts.With(m.labels).Set(float64(status.ConflictCount))
 }
 
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(missedIntervals, outOfSync, syncDurations)
+	metrics.Registry.MustRegister(missedIntervals, outOfSync, syncDurations,
+		syncthingFolderCompletion, syncthingOutOfSyncBytes, syncthingFolderErrors, syncthingFolderConflicts)
 }
@@ -103,3 +103,53 @@ func GetSourceMoverFromCatalog(client client.Client, logger logr.Logger,
 	}
 	return dataMover, nil
 }
+
+// PopulatorOptions selects the backup that should be restored into a volume by
+// the volume populator.
+type PopulatorOptions struct {
+	// SnapshotID is the mover-specific identifier of the backup to restore.
+	SnapshotID *string
+	// RestoreAsOf selects the most recent backup taken at or before this
+	// (RFC-3339) time.
+	RestoreAsOf *string
+}
+
+// PopulatorBuilder is implemented by Builders whose movers are able to restore
+// a selected backup directly into a volume on behalf of the volume populator.
+type PopulatorBuilder interface {
+	// FromPopulator attempts to construct a Mover that restores the backup
+	// selected by options from the repository configured in the provided
+	// ReplicationDestination into the PVC named pvcName. The temporary objects
+	// created by the Mover are owned by owner. If the RD does not reference
+	// the Builder's mover type, this function should return (nil, nil).
+	FromPopulator(client client.Client, logger logr.Logger,
+		eventRecorder events.EventRecorder,
+		destination *volsyncv1alpha1.ReplicationDestination, owner client.Object,
+		pvcName string, options PopulatorOptions, privileged bool) (Mover, error)
+}
+
+func GetPopulatorMoverFromCatalog(client client.Client, logger logr.Logger,
+	eventRecorder events.EventRecorder,
+	destination *volsyncv1alpha1.ReplicationDestination, owner client.Object,
+	pvcName string, options PopulatorOptions, privileged bool) (Mover, error) {
+	var dataMover Mover
+	for _, builder := range Catalog {
+		popBuilder, ok := builder.(PopulatorBuilder)
+		if !ok {
+			continue
+		}
+		candidate, err := popBuilder.FromPopulator(client, logger, eventRecorder, destination, owner,
+			pvcName, options, privileged)
+		if err == nil && candidate != nil {
+			if dataMover != nil {
+				// Found 2 movers claiming this CR...
+				return nil, ErrMultipleMoversFound
+			}
+			dataMover = candidate
+		}
+	}
+	if dataMover == nil { // No mover matched
+		return nil, ErrNoMoverFound
+	}
+	return dataMover, nil
+}
@@ -40,6 +40,16 @@ type Mover interface {
 	Cleanup(ctx context.Context) (Result, error)
 }
 
+// Verifier is an optional interface that can be implemented by data movers
+// that are able to check whether the destination matches the source without
+// transferring any data.
+type Verifier interface {
+	// VerifyRequested returns true if a verification has been requested but
+	// has not yet been performed. While true, Synchronize() runs the
+	// verification instead of a synchronization.
+	VerifyRequested() bool
+}
+
 // Result indicates the outcome of a synchronization attempt
 type Result struct {
 	// Completed is set to true if the synchronization has completed. RetryAfter
@@ -104,6 +104,7 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(source),
 		volumehandler.FromSource(&source.Spec.Rclone.ReplicationSourceVolumeOptions),
+		volumehandler.SourceSnapshot(source.Spec.SourceSnapshot),
 	)
 	if err != nil {
 		return nil, err
@@ -132,6 +133,7 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		privileged:           privileged,
 		moverSecurityContext: source.Spec.Rclone.MoverSecurityContext,
 		latestMoverStatus:    source.Status.LatestMoverStatus,
+		podOptions:           source.Spec.Rclone.MoverPodOptions,
 	}, nil
 }
 
@@ -180,5 +182,6 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 		privileged:           privileged,
 		moverSecurityContext: destination.Spec.Rclone.MoverSecurityContext,
 		latestMoverStatus:    destination.Status.LatestMoverStatus,
+		podOptions:           destination.Spec.Rclone.MoverPodOptions,
 	}, nil
 }
@@ -65,6 +65,7 @@ type Mover struct {
 	privileged           bool // true if the mover should have elevated privileges
 	moverSecurityContext *corev1.PodSecurityContext
 	latestMoverStatus    *volsyncv1alpha1.MoverStatus
+	podOptions           volsyncv1alpha1.MoverPodOptions
 }
 
 var _ mover.Mover = &Mover{}
@@ -159,6 +160,10 @@ func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
 }
 
 func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	dataName := "volsync-" + m.owner.GetName() + "-src"
+	if m.vh.UsesSourceSnapshot() {
+		return m.vh.EnsurePVCFromSourceSnapshot(ctx, m.logger, dataName, true, true)
+	}
 	srcPVC := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      *m.mainPVCName,
@@ -169,7 +174,6 @@ func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeCl
 		m.logger.Error(err, "unable to get source PVC", "PVC", client.ObjectKeyFromObject(srcPVC))
 		return nil, err
 	}
-	dataName := "volsync-" + m.owner.GetName() + "-src"
 	return m.vh.EnsurePVCFromSrc(ctx, m.logger, srcPVC, dataName, true)
 }
 
@@ -180,7 +184,7 @@ func (m *Mover) ensureDestinationPVC(ctx context.Context) (*corev1.PersistentVol
 		return m.vh.UseProvidedPVC(ctx, dataPVCName)
 	}
 	// Need to allocate the incoming data volume
-	return m.vh.EnsureNewPVC(ctx, m.logger, dataPVCName)
+	return m.vh.EnsureNewDataPVC(ctx, m.logger, dataPVCName)
 }
 
 func (m *Mover) getDestinationPVCName() (bool, string) {
@@ -293,6 +297,7 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 			}
 			job.Spec.Template.Spec.NodeSelector = affinity.NodeSelector
 			job.Spec.Template.Spec.Tolerations = affinity.Tolerations
+			job.Spec.Template.Spec.Affinity = affinity.Affinity
 		}
 		logger.V(1).Info("Job has PVC", "PVC", dataPVC, "DS", dataPVC.Spec.DataSource)
 
@@ -336,6 +341,7 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 			})
 		}
 
+		utils.ApplyMoverPodOptions(&job.Spec.Template, &m.podOptions)
 		return nil
 	})
 	// If Job had failed, delete it so it can be recreated
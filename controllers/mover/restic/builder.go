@@ -48,6 +48,7 @@ type Builder struct {
 }
 
 var _ mover.Builder = &Builder{}
+var _ mover.PopulatorBuilder = &Builder{}
 
 func Register() error {
 	// Use global viper & command line flags
@@ -109,6 +110,7 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(source),
 		volumehandler.FromSource(&source.Spec.Restic.ReplicationSourceVolumeOptions),
+		volumehandler.SourceSnapshot(source.Spec.SourceSnapshot),
 	)
 	if err != nil {
 		return nil, err
@@ -142,6 +144,7 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		unlock:                source.Spec.Restic.Unlock,
 		sourceStatus:          source.Status.Restic,
 		latestMoverStatus:     source.Status.LatestMoverStatus,
+		podOptions:            source.Spec.Restic.MoverPodOptions,
 	}, nil
 }
 
@@ -193,5 +196,59 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 		restoreAsOf:           destination.Spec.Restic.RestoreAsOf,
 		previous:              destination.Spec.Restic.Previous,
 		latestMoverStatus:     destination.Status.LatestMoverStatus,
+		podOptions:            destination.Spec.Restic.MoverPodOptions,
+	}, nil
+}
+
+func (rb *Builder) FromPopulator(client client.Client, logger logr.Logger,
+	eventRecorder events.EventRecorder,
+	destination *volsyncv1alpha1.ReplicationDestination, owner client.Object,
+	pvcName string, options mover.PopulatorOptions, privileged bool) (mover.Mover, error) {
+	// Only build if the CR belongs to us
+	if destination.Spec.Restic == nil {
+		return nil, nil
+	}
+
+	// The data is restored directly into the provided PVC, there is no image
+	// to preserve afterward
+	volumeOptions := destination.Spec.Restic.ReplicationDestinationVolumeOptions
+	volumeOptions.CopyMethod = volsyncv1alpha1.CopyMethodDirect
+
+	vh, err := volumehandler.NewVolumeHandler(
+		volumehandler.WithClient(client),
+		volumehandler.WithRecorder(eventRecorder),
+		volumehandler.WithOwner(owner),
+		volumehandler.FromDestination(&volumeOptions),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	isSource := false
+
+	saHandler := utils.NewSAHandler(client, owner, isSource, privileged,
+		destination.Spec.Restic.MoverServiceAccount)
+
+	return &Mover{
+		client:                client,
+		logger:                logger.WithValues("method", "Restic"),
+		eventRecorder:         eventRecorder,
+		owner:                 owner,
+		vh:                    vh,
+		saHandler:             saHandler,
+		containerImage:        rb.getResticContainerImage(),
+		cacheAccessModes:      destination.Spec.Restic.CacheAccessModes,
+		cacheCapacity:         destination.Spec.Restic.CacheCapacity,
+		cacheStorageClassName: destination.Spec.Restic.CacheStorageClassName,
+		repositoryName:        destination.Spec.Restic.Repository,
+		isSource:              isSource,
+		mainPVCName:           &pvcName,
+		customCASpec:          volsyncv1alpha1.CustomCASpec(destination.Spec.Restic.CustomCA),
+		privileged:            privileged,
+		moverSecurityContext:  destination.Spec.Restic.MoverSecurityContext,
+		restoreAsOf:           options.RestoreAsOf,
+		snapshotID:            options.SnapshotID,
+		latestMoverStatus:     &volsyncv1alpha1.MoverStatus{},
+		podOptions:            destination.Spec.Restic.MoverPodOptions,
 	}, nil
 }
@@ -74,6 +74,7 @@ type Mover struct {
 	privileged            bool
 	moverSecurityContext  *corev1.PodSecurityContext
 	latestMoverStatus     *volsyncv1alpha1.MoverStatus
+	podOptions            volsyncv1alpha1.MoverPodOptions
 	// Source-only fields
 	pruneInterval *int32
 	unlock        string
@@ -82,6 +83,7 @@ type Mover struct {
 	// Destination-only fields
 	previous    *int32
 	restoreAsOf *string
+	snapshotID  *string
 }
 
 var _ mover.Mover = &Mover{}
@@ -215,6 +217,10 @@ func (m *Mover) ensureCache(ctx context.Context,
 }
 
 func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	dataName := "volsync-" + m.owner.GetName() + "-src"
+	if m.vh.UsesSourceSnapshot() {
+		return m.vh.EnsurePVCFromSourceSnapshot(ctx, m.logger, dataName, true, true)
+	}
 	srcPVC := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      *m.mainPVCName,
@@ -224,7 +230,6 @@ func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeCl
 	if err := m.client.Get(ctx, client.ObjectKeyFromObject(srcPVC), srcPVC); err != nil {
 		return nil, err
 	}
-	dataName := "volsync-" + m.owner.GetName() + "-src"
 	return m.vh.EnsurePVCFromSrc(ctx, m.logger, srcPVC, dataName, true)
 }
 
@@ -234,7 +239,7 @@ func (m *Mover) ensureDestinationPVC(ctx context.Context) (*corev1.PersistentVol
 		return m.vh.UseProvidedPVC(ctx, dataPVCName)
 	}
 	// Need to allocate the incoming data volume
-	return m.vh.EnsureNewPVC(ctx, m.logger, dataPVCName)
+	return m.vh.EnsureNewDataPVC(ctx, m.logger, dataPVCName)
 }
 
 func (m *Mover) getDestinationPVCName() (bool, string) {
@@ -438,6 +443,14 @@ func (m *Mover) ensureJob(ctx context.Context, cachePVC *corev1.PersistentVolume
 			}
 			podSpec.NodeSelector = affinity.NodeSelector
 			podSpec.Tolerations = affinity.Tolerations
+			podSpec.Affinity = affinity.Affinity
+		}
+		if m.snapshotID != nil {
+			// Restore this specific restic snapshot instead of selecting one
+			podSpec.Containers[0].Env = append(podSpec.Containers[0].Env, corev1.EnvVar{
+				Name:  "RESTORE_SNAPSHOT_ID",
+				Value: *m.snapshotID,
+			})
 		}
 		if customCAObj != nil {
 			// Tell mover where to find the cert
@@ -504,6 +517,7 @@ func (m *Mover) ensureJob(ctx context.Context, cachePVC *corev1.PersistentVolume
 				Value: "0",
 			})
 		}
+		utils.ApplyMoverPodOptions(&job.Spec.Template, &m.podOptions)
 		return nil
 	})
 	// If Job had failed, delete it so it can be recreated
@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/pflag"
@@ -645,6 +646,40 @@ var _ = Describe("Restic as a source", func() {
 					Expect(dataPVC.Labels).To(HaveKey("volsync.backube/cleanup"))
 				})
 			})
+			When("a sourceSnapshot is set", func() {
+				BeforeEach(func() {
+					rs.Spec.Restic.CopyMethod = volsyncv1alpha1.CopyMethodDirect
+					rs.Spec.SourceSnapshot = &volsyncv1alpha1.ReplicationSourceSnapshotSpec{
+						Name: ptr.To("existing-snap"),
+					}
+					snap := &snapv1.VolumeSnapshot{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "existing-snap",
+							Namespace: ns.Name,
+						},
+						Spec: snapv1.VolumeSnapshotSpec{
+							Source: snapv1.VolumeSnapshotSource{
+								PersistentVolumeClaimName: &sPVC.Name,
+							},
+						},
+					}
+					Expect(k8sClient.Create(ctx, snap)).To(Succeed())
+					snap.Status = &snapv1.VolumeSnapshotStatus{
+						CreationTime: &metav1.Time{Time: time.Now()},
+						ReadyToUse:   ptr.To(true),
+					}
+					Expect(k8sClient.Status().Update(ctx, snap)).To(Succeed())
+				})
+				It("the data PVC is restored from the snapshot", func() {
+					dataPVC, err := mover.ensureSourcePVC(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(dataPVC.Name).NotTo(Equal(sPVC.Name))
+					Expect(dataPVC.Spec.DataSource).NotTo(BeNil())
+					Expect(dataPVC.Spec.DataSource.Name).To(Equal("existing-snap"))
+					// It will be cleaned up at the end of the transfer
+					Expect(dataPVC.Labels).To(HaveKey("volsync.backube/cleanup"))
+				})
+			})
 		})
 
 		Context("mover Job is handled properly", func() {
@@ -803,6 +838,56 @@ var _ = Describe("Restic as a source", func() {
 					})
 				})
 
+				When("Mover pod options are provided", func() {
+					BeforeEach(func() {
+						rs.Spec.Restic.MoverPodOptions = volsyncv1alpha1.MoverPodOptions{
+							MoverResources: &corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("64Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("1Gi"),
+								},
+							},
+							MoverNodeSelector: map[string]string{"node-type": "storage"},
+							MoverTolerations: []corev1.Toleration{{
+								Key:      "dedicated",
+								Operator: corev1.TolerationOpEqual,
+								Value:    "storage",
+								Effect:   corev1.TaintEffectNoSchedule,
+							}},
+							MoverPriorityClassName: ptr.To("backup"),
+							MoverPodLabels:         map[string]string{"team": "storage"},
+							MoverPodAnnotations:    map[string]string{"example.com/owner": "storage"},
+						}
+					})
+					It("Should apply them to the mover Job", func() {
+						j, e := mover.ensureJob(ctx, cache, sPVC, sa, repo, nil)
+						Expect(e).NotTo(HaveOccurred())
+						Expect(j).To(BeNil()) // hasn't completed
+						nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+						job = &batchv1.Job{}
+						Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+
+						template := job.Spec.Template
+						Expect(template.Spec.Containers[0].Resources).To(Equal(*rs.Spec.Restic.MoverResources))
+						Expect(template.Spec.NodeSelector).To(HaveKeyWithValue("node-type", "storage"))
+						Expect(template.Spec.Tolerations).To(Equal(rs.Spec.Restic.MoverTolerations))
+						Expect(template.Spec.PriorityClassName).To(Equal("backup"))
+						Expect(template.Labels).To(HaveKeyWithValue("team", "storage"))
+						Expect(template.Annotations).To(HaveKeyWithValue("example.com/owner", "storage"))
+
+						// Reconciling again leaves the Job alone
+						_, e = mover.ensureJob(ctx, cache, sPVC, sa, repo, nil)
+						Expect(e).NotTo(HaveOccurred())
+						updatedJob := &batchv1.Job{}
+						Expect(k8sClient.Get(ctx, nsn, updatedJob)).To(Succeed())
+						Expect(updatedJob.GetUID()).To(Equal(job.GetUID()))
+						Expect(updatedJob.Spec.Template.Spec.Tolerations).To(HaveLen(1))
+					})
+				})
+
 				When("The NS allows privileged movers", func() { // Already the case in this block
 					It("Should start a privileged mover", func() {
 						j, e := mover.ensureJob(ctx, cache, sPVC, sa, repo, nil)
@@ -78,6 +78,12 @@ func newBuilder(viper *viper.Viper, flags *flag.FlagSet) (*Builder, error) {
 	return b, err
 }
 
+// ContainerImage returns the configured container image of the rsync data
+// mover (using the global viper & command line flags)
+func ContainerImage() string {
+	return viper.GetString(rsyncContainerImageFlag)
+}
+
 func (rb *Builder) VersionInfo() string {
 	return fmt.Sprintf("Rsync container: %s", rb.getRsyncContainerImage())
 }
@@ -109,6 +115,7 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(source),
 		volumehandler.FromSource(&source.Spec.Rsync.ReplicationSourceVolumeOptions),
+		volumehandler.SourceSnapshot(source.Spec.SourceSnapshot),
 	)
 	if err != nil {
 		return nil, err
@@ -132,11 +139,14 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		serviceAnnotations: nil,
 		address:            source.Spec.Rsync.Address,
 		port:               source.Spec.Rsync.Port,
+		transferOptions:    source.Spec.Rsync.RsyncTransferOptions,
+		verify:             source.Spec.Rsync.Verify,
 		isSource:           isSource,
 		paused:             source.Spec.Paused,
 		mainPVCName:        &source.Spec.SourcePVC,
 		sourceStatus:       source.Status.Rsync,
 		latestMoverStatus:  source.Status.LatestMoverStatus,
+		podOptions:         source.Spec.Rsync.MoverPodOptions,
 	}, nil
 }
 
@@ -198,5 +208,6 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 		mainPVCName:        destination.Spec.Rsync.DestinationPVC,
 		destStatus:         destination.Status.Rsync,
 		latestMoverStatus:  destination.Status.LatestMoverStatus,
+		podOptions:         destination.Spec.Rsync.MoverPodOptions,
 	}, nil
 }
@@ -60,15 +60,19 @@ type Mover struct {
 	serviceAnnotations map[string]string
 	address            *string
 	port               *int32
+	transferOptions    volsyncv1alpha1.RsyncTransferOptions
+	verify             string
 	isSource           bool
 	paused             bool
 	mainPVCName        *string
 	sourceStatus       *volsyncv1alpha1.ReplicationSourceRsyncStatus
 	destStatus         *volsyncv1alpha1.ReplicationDestinationRsyncStatus
 	latestMoverStatus  *volsyncv1alpha1.MoverStatus
+	podOptions         volsyncv1alpha1.MoverPodOptions
 }
 
 var _ mover.Mover = &Mover{}
+var _ mover.Verifier = &Mover{}
 
 // All object types that are temporary/per-iteration should be listed here. The
 // individual objects to be cleaned up must also be marked.
@@ -94,6 +98,12 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 		return mover.InProgress(), err
 	}
 
+	if m.VerifyRequested() && utils.PvcIsBlockMode(dataPVC) {
+		m.recordVerification(utils.NewRsyncVerificationStatusNotPerformed(m.verify,
+			"verification is not supported for block volumes"))
+		return mover.Complete(), nil
+	}
+
 	// Ensure service (if required) and publish the address in the status
 	cont, err := m.ensureServiceAndPublishAddress(ctx)
 	if !cont || err != nil {
@@ -127,10 +137,25 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 		return mover.CompleteWithImage(image), nil
 	}
 
+	if m.VerifyRequested() {
+		m.recordVerification(utils.NewRsyncVerificationStatus(m.verify, m.latestMoverStatus.Logs))
+	}
+
 	// On the source, just signal completion
 	return mover.Complete(), nil
 }
 
+// VerifyRequested returns true if a verification of the destination has been
+// requested via the source's verify trigger and has not been performed yet
+func (m *Mover) VerifyRequested() bool {
+	return m.isSource && utils.RsyncVerifyRequested(m.verify, m.sourceStatus.Verification)
+}
+
+func (m *Mover) recordVerification(verification *volsyncv1alpha1.RsyncVerificationStatus) {
+	m.sourceStatus.Verification = verification
+	utils.PublishRsyncVerificationEvent(m.eventRecorder, m.owner, verification)
+}
+
 func (m *Mover) ensureServiceAndPublishAddress(ctx context.Context) (bool, error) {
 	if m.address != nil {
 		// Connection will be outbound. Don't need a Service
@@ -297,6 +322,10 @@ func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
 }
 
 func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	dataName := "volsync-" + m.owner.GetName() + "-" + m.direction()
+	if m.vh.UsesSourceSnapshot() {
+		return m.vh.EnsurePVCFromSourceSnapshot(ctx, m.logger, dataName, true, true)
+	}
 	srcPVC := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      *m.mainPVCName,
@@ -307,7 +336,6 @@ func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeCl
 		m.logger.Error(err, "unable to get source PVC", "PVC", client.ObjectKeyFromObject(srcPVC))
 		return nil, err
 	}
-	dataName := "volsync-" + m.owner.GetName() + "-" + m.direction()
 	return m.vh.EnsurePVCFromSrc(ctx, m.logger, srcPVC, dataName, true)
 }
 
@@ -317,7 +345,7 @@ func (m *Mover) ensureDestinationPVC(ctx context.Context) (*corev1.PersistentVol
 		return m.vh.UseProvidedPVC(ctx, dataPVCName)
 	}
 	// Need to allocate the incoming data volume
-	return m.vh.EnsureNewPVC(ctx, m.logger, dataPVCName)
+	return m.vh.EnsureNewDataPVC(ctx, m.logger, dataPVCName)
 }
 
 func (m *Mover) getDestinationPVCName() (bool, string) {
@@ -374,6 +402,12 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 				}
 			}
 
+			containerEnv = append(containerEnv, utils.RsyncTransferOptionsEnvVars(m.transferOptions)...)
+			if m.VerifyRequested() {
+				// Compare with the destination instead of transferring data
+				containerEnv = append(containerEnv, corev1.EnvVar{Name: "VERIFY_ONLY", Value: "true"})
+			}
+
 			// Set container cmd for the replicationSource job
 			containerCmd = []string{"/bin/bash", "-c", "/mover-rsync/source.sh"}
 
@@ -451,10 +485,19 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 			}
 			job.Spec.Template.Spec.NodeSelector = affinity.NodeSelector
 			job.Spec.Template.Spec.Tolerations = affinity.Tolerations
+			job.Spec.Template.Spec.Affinity = affinity.Affinity
 		}
 		logger.V(1).Info("Job has PVC", "PVC", dataPVC, "DS", dataPVC.Spec.DataSource)
+		utils.ApplyMoverPodOptions(&job.Spec.Template, &m.podOptions)
 		return nil
 	})
+	// A full destination is expanded before the transfer is retried
+	if err == nil && !m.isSource && job.Status.Failed > 0 {
+		full, err := m.expandIfFull(ctx, logger, job, dataPVC)
+		if full || err != nil {
+			return nil, err
+		}
+	}
 	// If Job had failed, delete it so it can be recreated
 	if job.Status.Failed >= *job.Spec.BackoffLimit {
 		// Update status with mover logs from failed job
@@ -491,9 +534,32 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 	logger.Info("job completed")
 
 	// update status with mover logs from successful job
+	logLineFilter := LogLineFilterSuccess
+	if m.VerifyRequested() {
+		logLineFilter = utils.LogLineFilterRsyncVerify
+	}
 	utils.UpdateMoverStatusForSuccessfulJob(ctx, m.logger, m.latestMoverStatus, job.GetName(), job.GetNamespace(),
-		LogLineFilterSuccess)
+		logLineFilter)
 
 	// We only continue reconciling if the rsync job has completed
 	return job, nil
 }
+
+// Checks whether the destination mover failed because its volume is full. If
+// so, the volume is expanded and the Job is deleted so that the transfer is
+// retried with the larger volume.
+func (m *Mover) expandIfFull(ctx context.Context, logger logr.Logger, job *batchv1.Job,
+	dataPVC *corev1.PersistentVolumeClaim) (bool, error) {
+	full, required, err := utils.GetNoSpaceReportForJob(ctx, logger, job.GetName(), job.GetNamespace())
+	if !full || err != nil {
+		return false, err
+	}
+	utils.UpdateMoverStatusForFailedJob(ctx, m.logger, m.latestMoverStatus, job.GetName(), job.GetNamespace(),
+		utils.AllLines)
+	if err := m.vh.ExpandFullPVC(ctx, logger, dataPVC, required); err != nil {
+		return true, err
+	}
+
+	logger.Info("deleting job -- destination volume is full")
+	return true, m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+}
@@ -1003,6 +1003,72 @@ var _ = Describe("Rsync as a source", func() {
 				})
 			})
 
+			When("a verification is requested", func() {
+				BeforeEach(func() {
+					address := "testserver.mydomain"
+					rs.Spec.Rsync.Address = &address
+					rs.Spec.Rsync.Verify = "v1"
+				})
+				It("should run the mover in verify mode", func() {
+					Expect(mover.VerifyRequested()).To(BeTrue())
+					j, e := mover.ensureJob(ctx, sPVC, sa, sshKeysSecret.GetName()) // Using sPVC as dataPVC (i.e. direct)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					validateEnvVar(job.Spec.Template.Spec.Containers[0].Env, "VERIFY_ONLY", "true")
+				})
+				When("the verification has already been performed", func() {
+					JustBeforeEach(func() {
+						mover.sourceStatus.Verification = &volsyncv1alpha1.RsyncVerificationStatus{Tag: "v1"}
+					})
+					It("should run the mover normally", func() {
+						Expect(mover.VerifyRequested()).To(BeFalse())
+						j, e := mover.ensureJob(ctx, sPVC, sa, sshKeysSecret.GetName()) // Using sPVC as dataPVC (i.e. direct)
+						Expect(e).NotTo(HaveOccurred())
+						Expect(j).To(BeNil()) // hasn't completed
+						nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+						job = &batchv1.Job{}
+						Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+						for _, envVar := range job.Spec.Template.Spec.Containers[0].Env {
+							Expect(envVar.Name).NotTo(Equal("VERIFY_ONLY"))
+						}
+					})
+				})
+			})
+
+			When("transfer options are specified in rsync spec", func() {
+				BeforeEach(func() {
+					address := "testserver.mydomain"
+					rs.Spec.Rsync.Address = &address
+
+					bwLimit := resource.MustParse("1Mi")
+					rs.Spec.Rsync.BandwidthLimit = &bwLimit
+					rs.Spec.Rsync.CompressionLevel = ptr.To(int32(3))
+					rs.Spec.Rsync.WholeFile = ptr.To(true)
+					rs.Spec.Rsync.Checksum = ptr.To(true)
+					rs.Spec.Rsync.Partial = ptr.To(false)
+				})
+				It("should pass the transfer options to the mover in env vars", func() {
+					j, e := mover.ensureJob(ctx, sPVC, sa, sshKeysSecret.GetName()) // Using sPVC as dataPVC (i.e. direct)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+
+					// Validate job env vars
+					env := job.Spec.Template.Spec.Containers[0].Env
+					Expect(len(env)).To(Equal(6))
+					validateEnvVar(env, "BANDWIDTH_LIMIT", "1024")
+					validateEnvVar(env, "COMPRESSION_LEVEL", "3")
+					validateEnvVar(env, "WHOLE_FILE", "true")
+					validateEnvVar(env, "CHECKSUM", "true")
+					validateEnvVar(env, "PARTIAL", "false")
+				})
+			})
+
 			When("Doing a sync when the job already exists", func() {
 				JustBeforeEach(func() {
 					mover.containerImage = "my-rsync-mover-image"
@@ -109,6 +109,7 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(source),
 		volumehandler.FromSource(&source.Spec.RsyncTLS.ReplicationSourceVolumeOptions),
+		volumehandler.SourceSnapshot(source.Spec.SourceSnapshot),
 	)
 	if err != nil {
 		return nil, err
@@ -119,6 +120,14 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 	saHandler := utils.NewSAHandler(client, source, isSource, privileged,
 		source.Spec.RsyncTLS.MoverServiceAccount)
 
+	var svcAnnotations map[string]string
+	if source.Spec.RsyncTLS.ServiceAnnotations != nil {
+		// If nil we will assume VolSync can set defaults
+		// if not nil, we will assume we will use the users settings (and empty map will mean
+		// we do not set any annotations at all on the service)
+		svcAnnotations = *source.Spec.RsyncTLS.ServiceAnnotations
+	}
+
 	return &Mover{
 		client:               client,
 		logger:               logger.WithValues("method", "RsyncTLS"),
@@ -128,10 +137,13 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		saHandler:            saHandler,
 		containerImage:       rb.getRsyncTLSContainerImage(),
 		key:                  source.Spec.RsyncTLS.KeySecret,
-		serviceType:          nil,
-		serviceAnnotations:   nil,
+		serviceType:          source.Spec.RsyncTLS.ServiceType,
+		serviceAnnotations:   svcAnnotations,
 		address:              source.Spec.RsyncTLS.Address,
 		port:                 source.Spec.RsyncTLS.Port,
+		destinations:         source.Spec.RsyncTLS.Destinations,
+		transferOptions:      source.Spec.RsyncTLS.RsyncTransferOptions,
+		verify:               source.Spec.RsyncTLS.Verify,
 		isSource:             isSource,
 		paused:               source.Spec.Paused,
 		mainPVCName:          &source.Spec.SourcePVC,
@@ -139,6 +151,7 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		moverSecurityContext: source.Spec.RsyncTLS.MoverSecurityContext,
 		sourceStatus:         source.Status.RsyncTLS,
 		latestMoverStatus:    source.Status.LatestMoverStatus,
+		podOptions:           source.Spec.RsyncTLS.MoverPodOptions,
 	}, nil
 }
 
@@ -193,14 +206,17 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 		key:                  destination.Spec.RsyncTLS.KeySecret,
 		serviceType:          destination.Spec.RsyncTLS.ServiceType,
 		serviceAnnotations:   svcAnnotations,
-		address:              nil,
-		port:                 nil,
+		address:              destination.Spec.RsyncTLS.Address,
+		port:                 destination.Spec.RsyncTLS.Port,
+		transferOptions:      destination.Spec.RsyncTLS.RsyncTransferOptions,
 		isSource:             isSource,
 		paused:               destination.Spec.Paused,
 		mainPVCName:          destination.Spec.RsyncTLS.DestinationPVC,
+		storageClassName:     destination.Spec.RsyncTLS.StorageClassName,
 		privileged:           privileged,
 		moverSecurityContext: destination.Spec.RsyncTLS.MoverSecurityContext,
 		destStatus:           destination.Status.RsyncTLS,
 		latestMoverStatus:    destination.Status.LatestMoverStatus,
+		podOptions:           destination.Spec.RsyncTLS.MoverPodOptions,
 	}, nil
 }
@@ -19,6 +19,8 @@ package rsynctls
 
 import (
 	"regexp"
+
+	"github.com/backube/volsync/controllers/utils"
 )
 
 var rsyncTLSRegex = regexp.MustCompile(
@@ -34,13 +36,18 @@ var rsyncTLSRegexFailures = regexp.MustCompile(
 
 // Filter rsync log lines for a successful move job
 func LogLineFilterSuccess(line string) *string {
-	if rsyncTLSRegex.MatchString(line) {
+	if rsyncTLSRegex.MatchString(line) || utils.LogLineFilterBlockVerify(line) != nil {
 		return &line
 	}
 	return nil
 }
 
 func LogLineFilterFailure(line string) *string {
+	// Progress reports would crowd out the lines explaining the failure
+	if utils.LogLineFilterBlockProgress(line) != nil {
+		return nil
+	}
+
 	// Match first against the same stuff we do for success
 	if rsyncTLSRegex.MatchString(line) {
 		return &line
@@ -760,4 +760,37 @@ Shutting down...
 			Expect(filteredLines).To(Equal(expectedFilteredLog))
 		})
 	})
+
+	Context("Block volume failure logs filtering test", func() {
+		blockLog := `2023-10-01T10:00:00.000Z	INFO	diskrsync-tls (for VolSync) Version: 0.8.0
+diskrsync-progress: {"time":"2023-10-01T10:00:10Z","totalBytes":1000,"percent":0}
+diskrsync-progress: {"time":"2023-10-01T10:00:20Z","totalBytes":1000,"percent":40}
+2023-10-01T10:00:25.000Z	ERROR	Unable to connect to target	{"error": "connection refused"}`
+
+		It("Should drop the progress reports", func() {
+			reader := strings.NewReader(blockLog)
+			filteredLines, err := utils.FilterLogs(reader, rsynctls.LogLineFilterFailure)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filteredLines).To(Equal(
+				"2023-10-01T10:00:25.000Z\tERROR\tUnable to connect to target\t{\"error\": \"connection refused\"}"))
+		})
+	})
+
+	Context("Block volume verification logs filtering test", func() {
+		blockLog := `2023-10-01T10:00:00.000Z	INFO	diskrsync-tls (for VolSync) Version: 0.8.0
+diskrsync-progress: {"time":"2023-10-01T10:00:10Z","totalBytes":1000,"percent":0}
+2023-10-01T10:00:12.000Z	INFO	Verification complete, the target matches the source
+diskrsync-verify: {"match":true,"totalBytes":1000,"rangeSize":67108864,"mismatchedBytes":0}
+2023-10-01T10:00:12.000Z	INFO	Successfully completed sync
+diskrsync completed in 12s`
+
+		It("Should keep the verification result", func() {
+			reader := strings.NewReader(blockLog)
+			filteredLines, err := utils.FilterLogs(reader, rsynctls.LogLineFilterSuccess)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filteredLines).To(Equal(
+				`diskrsync-verify: {"match":true,"totalBytes":1000,"rangeSize":67108864,"mismatchedBytes":0}
+diskrsync completed in 12s`))
+		})
+	})
 })
@@ -21,6 +21,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -29,7 +30,9 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/events"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -47,6 +50,11 @@ const (
 	devicePath       = "/dev/block"
 	dataVolumeName   = "data"
 	tlsContainerPort = 8000
+	// The destination of a block transfer records its progress on a volume of
+	// its own, so the transfer can be resumed by a new mover Pod
+	checkpointVolumeName = "checkpoint"
+	checkpointMountPath  = "/checkpoint"
+	checkpointCapacity   = "64Mi"
 )
 
 // Mover is the reconciliation logic for the Rsync-based data mover.
@@ -63,17 +71,34 @@ type Mover struct {
 	serviceAnnotations   map[string]string
 	address              *string
 	port                 *int32
+	destinations         []volsyncv1alpha1.ReplicationSourceRsyncTLSDestinationSpec
+	transferOptions      volsyncv1alpha1.RsyncTransferOptions
+	verify               string
 	isSource             bool
 	paused               bool
 	mainPVCName          *string
+	storageClassName     *string
 	privileged           bool
 	moverSecurityContext *corev1.PodSecurityContext
 	sourceStatus         *volsyncv1alpha1.ReplicationSourceRsyncTLSStatus
 	destStatus           *volsyncv1alpha1.ReplicationDestinationRsyncTLSStatus
 	latestMoverStatus    *volsyncv1alpha1.MoverStatus
+	podOptions           volsyncv1alpha1.MoverPodOptions
+	// The result of the verification of a block volume, as published by the
+	// mover
+	blockVerification string
 }
 
 var _ mover.Mover = &Mover{}
+var _ mover.Verifier = &Mover{}
+
+// remote describes the other side of the TLS connection for a mover Job
+type remote struct {
+	jobName     string
+	address     *string
+	port        *int32
+	moverStatus *volsyncv1alpha1.MoverStatus
+}
 
 // All object types that are temporary/per-iteration should be listed here. The
 // individual objects to be cleaned up must also be marked.
@@ -99,6 +124,13 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 		return mover.InProgress(), err
 	}
 
+	if m.VerifyRequested() {
+		if msg := m.verifyNotSupportedReason(dataPVC); msg != "" {
+			m.recordVerification(utils.NewRsyncVerificationStatusNotPerformed(m.verify, msg))
+			return mover.Complete(), nil
+		}
+	}
+
 	// Ensure service (if required) and publish the address in the status
 	cont, err := m.ensureServiceAndPublishAddress(ctx)
 	if !cont || err != nil {
@@ -117,6 +149,21 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 		return mover.InProgress(), err
 	}
 
+	// Send the data to each destination in turn, if there are several
+	if len(m.destinations) > 0 {
+		done, err := m.ensureDestinationJobs(ctx, dataPVC, sa, *rsyncPSKSecretName)
+		if !done || err != nil {
+			return mover.InProgress(), err
+		}
+		return mover.Complete(), nil
+	}
+
+	if !m.isSource && utils.PvcIsBlockMode(dataPVC) {
+		if err := m.ensureCheckpointPVC(ctx); err != nil {
+			return mover.InProgress(), err
+		}
+	}
+
 	// Ensure mover Job
 	job, err := m.ensureJob(ctx, dataPVC, sa, *rsyncPSKSecretName)
 	if job == nil || err != nil {
@@ -132,12 +179,44 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 		return mover.CompleteWithImage(image), nil
 	}
 
+	if m.VerifyRequested() {
+		if utils.PvcIsBlockMode(dataPVC) {
+			m.recordVerification(utils.NewBlockVerificationStatus(m.verify, m.blockVerification))
+		} else {
+			m.recordVerification(utils.NewRsyncVerificationStatus(m.verify, m.latestMoverStatus.Logs))
+		}
+	}
+
 	// On the source, just signal completion
 	return mover.Complete(), nil
 }
 
+// VerifyRequested returns true if a verification of the destination has been
+// requested via the source's verify trigger and has not been performed yet
+func (m *Mover) VerifyRequested() bool {
+	return m.isSource && utils.RsyncVerifyRequested(m.verify, m.sourceStatus.Verification)
+}
+
+// The source can only verify a single destination. For filesystem volumes, it
+// must also be the one running rsync. Block volumes are verified by
+// diskrsync-tcp whichever side connects.
+func (m *Mover) verifyNotSupportedReason(dataPVC *corev1.PersistentVolumeClaim) string {
+	switch {
+	case len(m.destinations) > 0:
+		return "verification is not supported with multiple destinations"
+	case m.address == nil && !utils.PvcIsBlockMode(dataPVC):
+		return "verification is not supported when the destination connects to the source"
+	}
+	return ""
+}
+
+func (m *Mover) recordVerification(verification *volsyncv1alpha1.RsyncVerificationStatus) {
+	m.sourceStatus.Verification = verification
+	utils.PublishRsyncVerificationEvent(m.eventRecorder, m.owner, verification)
+}
+
 func (m *Mover) ensureServiceAndPublishAddress(ctx context.Context) (bool, error) {
-	if m.address != nil || m.isSource {
+	if m.address != nil || len(m.destinations) > 0 {
 		// Connection will be outbound. Don't need a Service
 		return true, nil
 	}
@@ -170,7 +249,7 @@ func (m *Mover) publishSvcAddress(service *corev1.Service) (bool, error) {
 	address := utils.GetServiceAddress(service)
 	if address == "" {
 		// We don't have an address yet, try again later
-		m.updateStatusAddress(nil)
+		m.updateStatusAddress(nil, nil)
 		if service.CreationTimestamp.Add(mover.ServiceAddressTimeout).Before(time.Now()) {
 			m.eventRecorder.Eventf(m.owner, service, corev1.EventTypeWarning,
 				volsyncv1alpha1.EvRSvcNoAddress, volsyncv1alpha1.EvANone,
@@ -179,20 +258,29 @@ func (m *Mover) publishSvcAddress(service *corev1.Service) (bool, error) {
 		}
 		return false, nil
 	}
-	m.updateStatusAddress(&address)
+	port := service.Spec.Ports[0].Port
+	m.updateStatusAddress(&address, &port)
 
-	m.logger.V(1).Info("Service addr published", "address", address)
+	m.logger.V(1).Info("Service addr published", "address", address, "port", port)
 	return true, nil
 }
 
-func (m *Mover) updateStatusAddress(address *string) {
+func (m *Mover) updateStatusAddress(address *string, port *int32) {
 	publishEvent := false
-	if !m.isSource {
+	if m.isSource {
+		if m.sourceStatus.Address == nil ||
+			address != nil && *m.sourceStatus.Address != *address {
+			publishEvent = true
+		}
+		m.sourceStatus.Address = address
+		m.sourceStatus.Port = port
+	} else {
 		if m.destStatus.Address == nil ||
 			address != nil && *m.destStatus.Address != *address {
 			publishEvent = true
 		}
 		m.destStatus.Address = address
+		m.destStatus.Port = port
 	}
 	if publishEvent && address != nil {
 		m.eventRecorder.Eventf(m.owner, nil, corev1.EventTypeNormal,
@@ -214,23 +302,35 @@ func (m *Mover) updateStatusPSK(pskSecretName *string) {
 func (m *Mover) ensureSecrets(ctx context.Context) (*string, error) {
 	// If user provided key, use that
 	if m.key != nil {
-		keySecret := &corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      *m.key,
-				Namespace: m.owner.GetNamespace(),
-			},
-		}
-		fields := []string{"psk.txt"}
-		if err := utils.GetAndValidateSecret(ctx, m.client, m.logger, keySecret, fields...); err != nil {
-			m.logger.Error(err, "Key Secret does not contain the proper fields")
+		if err := m.validateKeySecret(ctx, *m.key); err != nil {
 			return nil, err
 		}
 		return m.key, nil
 	}
 
+	// Destinations used to generate a key named without the direction. Keep
+	// using it so the sources it has been copied to continue to work.
+	if !m.isSource {
+		legacySecret := &corev1.Secret{}
+		err := m.client.Get(ctx, types.NamespacedName{
+			Name:      "volsync-rsync-tls-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		}, legacySecret)
+		if client.IgnoreNotFound(err) != nil {
+			m.logger.Error(err, "error retreiving key")
+			return nil, err
+		}
+		if err == nil && metav1.IsControlledBy(legacySecret, m.owner) {
+			m.updateStatusPSK(&legacySecret.Name)
+			return &legacySecret.Name, nil
+		}
+	}
+
+	// The name includes the direction so that a source and a destination
+	// with the same name don't share their key
 	keySecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "volsync-rsync-tls-" + m.owner.GetName(),
+			Name:      "volsync-rsync-tls-" + m.direction() + "-" + m.owner.GetName(),
 			Namespace: m.owner.GetNamespace(),
 		},
 	}
@@ -266,6 +366,22 @@ func (m *Mover) ensureSecrets(ctx context.Context) (*string, error) {
 	return &keySecret.Name, nil
 }
 
+// Ensures that a user-provided key Secret exists and contains the key
+func (m *Mover) validateKeySecret(ctx context.Context, name string) error {
+	keySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	fields := []string{"psk.txt"}
+	if err := utils.GetAndValidateSecret(ctx, m.client, m.logger, keySecret, fields...); err != nil {
+		m.logger.Error(err, "Key Secret does not contain the proper fields")
+		return err
+	}
+	return nil
+}
+
 func (m *Mover) direction() string {
 	dir := "src"
 	if !m.isSource {
@@ -274,6 +390,14 @@ func (m *Mover) direction() string {
 	return dir
 }
 
+func (m *Mover) role() string {
+	role := "source"
+	if !m.isSource {
+		role = "destination"
+	}
+	return role
+}
+
 func (m *Mover) serviceSelector() map[string]string {
 	return map[string]string{
 		"app.kubernetes.io/name":      m.direction() + "-" + m.owner.GetName(),
@@ -304,6 +428,11 @@ func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
 }
 
 func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	dataName := "volsync-" + m.owner.GetName() + "-" + m.direction()
+	if m.vh.UsesSourceSnapshot() {
+		// A verification compares the destination with the snapshot replicated last
+		return m.vh.EnsurePVCFromSourceSnapshot(ctx, m.logger, dataName, true, !m.VerifyRequested())
+	}
 	srcPVC := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      *m.mainPVCName,
@@ -314,7 +443,6 @@ func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeCl
 		m.logger.Error(err, "unable to get source PVC", "PVC", client.ObjectKeyFromObject(srcPVC))
 		return nil, err
 	}
-	dataName := "volsync-" + m.owner.GetName() + "-" + m.direction()
 	return m.vh.EnsurePVCFromSrc(ctx, m.logger, srcPVC, dataName, true)
 }
 
@@ -324,7 +452,7 @@ func (m *Mover) ensureDestinationPVC(ctx context.Context) (*corev1.PersistentVol
 		return m.vh.UseProvidedPVC(ctx, dataPVCName)
 	}
 	// Need to allocate the incoming data volume
-	return m.vh.EnsureNewPVC(ctx, m.logger, dataPVCName)
+	return m.vh.EnsureNewDataPVC(ctx, m.logger, dataPVCName)
 }
 
 func (m *Mover) getDestinationPVCName() (bool, string) {
@@ -335,12 +463,168 @@ func (m *Mover) getDestinationPVCName() (bool, string) {
 	return true, *m.mainPVCName
 }
 
-//nolint:funlen
+func (m *Mover) jobName() string {
+	return "volsync-rsync-tls-" + m.direction() + "-" + m.owner.GetName()
+}
+
+func (m *Mover) checkpointPVCName() string {
+	return "volsync-rsync-tls-checkpoint-" + m.owner.GetName()
+}
+
+// Ensures the PVC that keeps the progress of block transfers on the
+// destination. It is kept between synchronizations.
+func (m *Mover) ensureCheckpointPVC(ctx context.Context) error {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.checkpointPVCName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	_, err := ctrlutil.CreateOrUpdate(ctx, m.client, pvc, func() error {
+		if err := ctrl.SetControllerReference(m.owner, pvc, m.client.Scheme()); err != nil {
+			m.logger.Error(err, utils.ErrUnableToSetControllerRef)
+			return err
+		}
+		utils.SetOwnedByVolSync(pvc)
+		if pvc.CreationTimestamp.IsZero() {
+			pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+			pvc.Spec.StorageClassName = m.storageClassName
+			pvc.Spec.Resources.Requests = corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse(checkpointCapacity),
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		m.logger.Error(err, "unable to reconcile the checkpoint PVC")
+	}
+	return err
+}
+
+// The transfer ID lets the destination of a block transfer resume it. It is the
+// same for every mover Pod of a synchronization, but changes with each
+// synchronization, even if the source volume is the same.
+func (m *Mover) transferID(dataPVC *corev1.PersistentVolumeClaim) string {
+	id := string(dataPVC.GetUID())
+	if rs, ok := m.owner.(*volsyncv1alpha1.ReplicationSource); ok && rs.Status != nil &&
+		rs.Status.LastSyncStartTime != nil {
+		id += "-" + strconv.FormatInt(rs.Status.LastSyncStartTime.Unix(), 10)
+	}
+	return id
+}
+
+// Sends the data to each of the listed destinations. This is done one
+// destination at a time so that the PiT image is only ever used by a single
+// mover Pod. A destination whose mover Job reaches its backoff limit is marked
+// as failed and skipped until the next synchronization. Returns true once all
+// destinations have been synchronized or have failed.
+func (m *Mover) ensureDestinationJobs(ctx context.Context, dataPVC *corev1.PersistentVolumeClaim,
+	sa *corev1.ServiceAccount, rsyncSecretName string) (bool, error) {
+	m.updateStatusDestinations()
+
+	for i := range m.destinations {
+		dest := m.destinations[i]
+		destStatus := &m.sourceStatus.Destinations[i]
+		if destStatus.LatestMoverStatus == nil {
+			destStatus.LatestMoverStatus = &volsyncv1alpha1.MoverStatus{}
+		}
+
+		keySecretName := rsyncSecretName
+		if dest.KeySecret != nil {
+			if err := m.validateKeySecret(ctx, *dest.KeySecret); err != nil {
+				return false, err
+			}
+			keySecretName = *dest.KeySecret
+		}
+
+		r := remote{
+			jobName:     fmt.Sprintf("%s-%d", m.jobName(), i),
+			address:     &dest.Address,
+			port:        dest.Port,
+			moverStatus: destStatus.LatestMoverStatus,
+		}
+
+		// Jobs for destinations that have already been synchronized are left
+		// in place until cleanup, don't process them again once their status
+		// has been recorded
+		job := &batchv1.Job{}
+		err := m.client.Get(ctx, types.NamespacedName{Name: r.jobName, Namespace: m.owner.GetNamespace()}, job)
+		if err == nil && job.Status.Succeeded > 0 && job.Status.CompletionTime != nil &&
+			destStatus.LastSyncTime != nil && destStatus.LastSyncTime.Equal(job.Status.CompletionTime) {
+			continue
+		}
+		if err == nil && job.Spec.BackoffLimit != nil && job.Status.Failed >= *job.Spec.BackoffLimit {
+			m.markDestinationFailed(ctx, job, destStatus)
+			continue
+		}
+
+		job, err = m.ensureJobForRemote(ctx, dataPVC, sa, keySecretName, r)
+		if job == nil || err != nil {
+			return false, err
+		}
+		destStatus.LastSyncTime = job.Status.CompletionTime
+	}
+
+	return true, nil
+}
+
+// Records that the synchronization to a destination failed. The failed Job is
+// left in place until cleanup so that the destination isn't retried before the
+// next synchronization.
+func (m *Mover) markDestinationFailed(ctx context.Context, job *batchv1.Job,
+	destStatus *volsyncv1alpha1.ReplicationSourceRsyncTLSDestinationStatus) {
+	// Only record the failure of each Job once
+	if destStatus.LastFailureTime != nil && !destStatus.LastFailureTime.Before(&job.CreationTimestamp) {
+		return
+	}
+	utils.UpdateMoverStatusForFailedJob(ctx, m.logger, destStatus.LatestMoverStatus, job.GetName(),
+		job.GetNamespace(), LogLineFilterFailure)
+	m.updateLatestMoverStatus(destStatus.LatestMoverStatus)
+	now := metav1.Now()
+	destStatus.LastFailureTime = &now
+
+	m.logger.Info("giving up on destination -- backoff limit reached", "job", client.ObjectKeyFromObject(job),
+		"address", destStatus.Address)
+	m.eventRecorder.Eventf(m.owner, job, corev1.EventTypeWarning,
+		volsyncv1alpha1.EvRTransferFailed, volsyncv1alpha1.EvANone,
+		"mover Job backoff limit reached, skipping destination %s until the next synchronization",
+		destStatus.Address)
+}
+
+// Ensures there is a status entry for each destination in the spec, keeping
+// the previous status of destinations that are still listed. Destinations are
+// identified by their address and port.
+func (m *Mover) updateStatusDestinations() {
+	statuses := make([]volsyncv1alpha1.ReplicationSourceRsyncTLSDestinationStatus, len(m.destinations))
+	for i, dest := range m.destinations {
+		statuses[i].Address = dest.Address
+		statuses[i].Port = dest.Port
+		for _, existing := range m.sourceStatus.Destinations {
+			if existing.Address == dest.Address && ptr.Equal(existing.Port, dest.Port) {
+				statuses[i] = existing
+				break
+			}
+		}
+	}
+	m.sourceStatus.Destinations = statuses
+}
+
 func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeClaim,
 	sa *corev1.ServiceAccount, rsyncSecretName string) (*batchv1.Job, error) {
+	return m.ensureJobForRemote(ctx, dataPVC, sa, rsyncSecretName, remote{
+		jobName:     m.jobName(),
+		address:     m.address,
+		port:        m.port,
+		moverStatus: m.latestMoverStatus,
+	})
+}
+
+//nolint:funlen
+func (m *Mover) ensureJobForRemote(ctx context.Context, dataPVC *corev1.PersistentVolumeClaim,
+	sa *corev1.ServiceAccount, rsyncSecretName string, r remote) (*batchv1.Job, error) {
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "volsync-rsync-tls-" + m.direction() + "-" + m.owner.GetName(),
+			Name:      r.jobName,
 			Namespace: m.owner.GetNamespace(),
 		},
 	}
@@ -369,20 +653,31 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 		readOnlyVolume := false
 		blockVolume := utils.PvcIsBlockMode(dataPVC)
 
-		containerEnv := []corev1.EnvVar{}
-		containerCmd := []string{"/bin/bash", "-c", "/mover-rsync-tls/server.sh"} // cmd for replicationDestination job
-		if m.isSource {
-			// Set dest address/port if necessary
-			if m.address != nil {
-				containerEnv = append(containerEnv, corev1.EnvVar{Name: "DESTINATION_ADDRESS", Value: *m.address})
+		containerEnv := []corev1.EnvVar{{Name: "MOVER_ROLE", Value: m.role()}}
+		// Whichever side has been given an address makes the outbound connection
+		// (client), the other side waits for the incoming connection (server)
+		containerCmd := []string{"/bin/bash", "-c", "/mover-rsync-tls/server.sh"}
+		if r.address != nil {
+			remoteSide := "DESTINATION"
+			if !m.isSource {
+				remoteSide = "SOURCE"
 			}
-			if m.port != nil {
-				connectPort := strconv.Itoa(int(*m.port))
-				containerEnv = append(containerEnv, corev1.EnvVar{Name: "DESTINATION_PORT", Value: connectPort})
+			containerEnv = append(containerEnv, corev1.EnvVar{Name: remoteSide + "_ADDRESS", Value: *r.address})
+			if r.port != nil {
+				connectPort := strconv.Itoa(int(*r.port))
+				containerEnv = append(containerEnv, corev1.EnvVar{Name: remoteSide + "_PORT", Value: connectPort})
 			}
-			// Set container cmd for the replicationSource job
 			containerCmd = []string{"/bin/bash", "-c", "/mover-rsync-tls/client.sh"}
-
+		}
+		containerEnv = append(containerEnv, utils.RsyncTransferOptionsEnvVars(m.transferOptions)...)
+		if m.VerifyRequested() {
+			// Compare with the destination instead of transferring data
+			containerEnv = append(containerEnv, corev1.EnvVar{Name: "VERIFY_ONLY", Value: "true"})
+		}
+		if m.isSource && blockVolume {
+			containerEnv = append(containerEnv, corev1.EnvVar{Name: "TRANSFER_ID", Value: m.transferID(dataPVC)})
+		}
+		if m.isSource {
 			// Set read-only for volume in repl source job spec if the PVC only supports read-only
 			readOnlyVolume = utils.PvcIsReadOnly(dataPVC)
 		}
@@ -407,6 +702,10 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 		}
 		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "keys", MountPath: "/keys"},
 			corev1.VolumeMount{Name: "tempdir", MountPath: "/tmp"})
+		if !m.isSource && blockVolume {
+			volumeMounts = append(volumeMounts,
+				corev1.VolumeMount{Name: checkpointVolumeName, MountPath: checkpointMountPath})
+		}
 		job.Spec.Template.Spec.Containers[0].VolumeMounts = volumeMounts
 		if blockVolume {
 			job.Spec.Template.Spec.Containers[0].VolumeDevices = []corev1.VolumeDevice{
@@ -435,6 +734,14 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 				}},
 			},
 		}
+		if !m.isSource && blockVolume {
+			podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+				Name: checkpointVolumeName, VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: m.checkpointPVCName(),
+					}},
+			})
+		}
 		if m.vh.IsCopyMethodDirect() {
 			affinity, err := utils.AffinityFromVolume(ctx, m.client, logger, dataPVC)
 			if err != nil {
@@ -443,6 +750,7 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 			}
 			podSpec.NodeSelector = affinity.NodeSelector
 			podSpec.Tolerations = affinity.Tolerations
+			podSpec.Affinity = affinity.Affinity
 		}
 		if m.privileged {
 			podSpec.Containers[0].Env = append(podSpec.Containers[0].Env, corev1.EnvVar{
@@ -463,13 +771,23 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 			})
 		}
 		logger.V(1).Info("Job has PVC", "PVC", dataPVC, "DS", dataPVC.Spec.DataSource)
+		utils.ApplyMoverPodOptions(&job.Spec.Template, &m.podOptions)
 		return nil
 	})
+	// A full destination is expanded before the transfer is retried
+	if err == nil && !m.isSource && job.Status.Failed > 0 {
+		full, err := m.expandIfFull(ctx, logger, job, dataPVC)
+		if full || err != nil {
+			return nil, err
+		}
+	}
 	// If Job had failed, delete it so it can be recreated
 	if job.Status.Failed >= *job.Spec.BackoffLimit {
 		// Update status with mover logs from failed job
-		utils.UpdateMoverStatusForFailedJob(ctx, m.logger, m.latestMoverStatus, job.GetName(), job.GetNamespace(),
+		utils.UpdateMoverStatusForFailedJob(ctx, m.logger, r.moverStatus, job.GetName(), job.GetNamespace(),
 			LogLineFilterFailure)
+		m.updateLatestMoverStatus(r.moverStatus)
+		m.setProgress(nil)
 
 		logger.Info("deleting job -- backoff limit reached")
 		m.eventRecorder.Eventf(m.owner, job, corev1.EventTypeWarning,
@@ -495,15 +813,78 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 
 	// Stop here if the job hasn't completed yet
 	if job.Status.Succeeded == 0 {
+		if utils.PvcIsBlockMode(dataPVC) {
+			m.updateBlockProgress(ctx, job)
+		}
 		return nil, nil
 	}
 
 	logger.Info("job completed")
+	m.setProgress(nil)
 
 	// update status with mover logs from successful job
-	utils.UpdateMoverStatusForSuccessfulJob(ctx, m.logger, m.latestMoverStatus, job.GetName(), job.GetNamespace(),
-		LogLineFilterSuccess)
+	logLineFilter := LogLineFilterSuccess
+	if m.VerifyRequested() && utils.PvcIsBlockMode(dataPVC) {
+		m.blockVerification, err = utils.GetTerminationMessageForJob(ctx, m.logger, job.GetName(),
+			job.GetNamespace())
+		if err != nil {
+			return nil, err
+		}
+	} else if m.VerifyRequested() {
+		logLineFilter = utils.LogLineFilterRsyncVerify
+	}
+	utils.UpdateMoverStatusForSuccessfulJob(ctx, m.logger, r.moverStatus, job.GetName(), job.GetNamespace(),
+		logLineFilter)
+	m.updateLatestMoverStatus(r.moverStatus)
 
 	// We only continue reconciling if the rsync job has completed
 	return job, nil
 }
+
+// Checks whether the destination mover failed because its volume is full. If
+// so, the volume is expanded and the Job is deleted so that the transfer is
+// retried with the larger volume.
+func (m *Mover) expandIfFull(ctx context.Context, logger logr.Logger, job *batchv1.Job,
+	dataPVC *corev1.PersistentVolumeClaim) (bool, error) {
+	full, required, err := utils.GetNoSpaceReportForJob(ctx, logger, job.GetName(), job.GetNamespace())
+	if !full || err != nil {
+		return false, err
+	}
+	utils.UpdateMoverStatusForFailedJob(ctx, m.logger, m.latestMoverStatus, job.GetName(), job.GetNamespace(),
+		LogLineFilterFailure)
+	m.setProgress(nil)
+	if err := m.vh.ExpandFullPVC(ctx, logger, dataPVC, required); err != nil {
+		return true, err
+	}
+
+	logger.Info("deleting job -- destination volume is full")
+	return true, m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+}
+
+// Publishes the progress reported by the mover while a block volume is being
+// transferred. The previous progress is kept if it can't be retrieved.
+func (m *Mover) updateBlockProgress(ctx context.Context, job *batchv1.Job) {
+	progress, err := utils.GetBlockTransferProgress(ctx, m.logger, job.GetName(), job.GetNamespace())
+	if err != nil {
+		m.logger.Error(err, "unable to get the progress of the transfer")
+		return
+	}
+	if progress != nil {
+		m.setProgress(progress)
+	}
+}
+
+func (m *Mover) setProgress(progress *volsyncv1alpha1.BlockTransferProgress) {
+	if m.isSource {
+		m.sourceStatus.Progress = progress
+	} else {
+		m.destStatus.Progress = progress
+	}
+}
+
+// The most recent per-destination mover status is also the overall latest
+func (m *Mover) updateLatestMoverStatus(moverStatus *volsyncv1alpha1.MoverStatus) {
+	if moverStatus != m.latestMoverStatus {
+		*m.latestMoverStatus = *moverStatus
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/events"
 	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -370,21 +371,14 @@ var _ = Describe("RsyncTLS as a source", func() {
 
 		//nolint:dupl
 		Context("Service and address are handled properly", func() {
-			JustBeforeEach(func() {
-				// No service should be created regardless here as this is the replicationsource
-				// enasureServiecAndPublishAddress should return true,nil immediately
-				result, err := mover.ensureServiceAndPublishAddress(ctx)
-				Expect(err).To(BeNil())
-				Expect(result).To(BeTrue())
-
-				svc := &corev1.Service{
+			var svc *corev1.Service
+			BeforeEach(func() {
+				svc = &corev1.Service{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "volsync-rsync-tls-src-" + rs.Name,
 						Namespace: rs.Namespace,
 					},
 				}
-				// No service should be created
-				Expect(kerrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc))).To(BeTrue())
 			})
 
 			When("when a remote address is specified", func() {
@@ -398,7 +392,14 @@ var _ = Describe("RsyncTLS as a source", func() {
 					}
 				})
 				It("No Service is created", func() {
-					// Actual tests for svc not created are in the outer JustBeforeEach
+					// enasureServiecAndPublishAddress should return true,nil immediately
+					result, err := mover.ensureServiceAndPublishAddress(ctx)
+					Expect(err).To(BeNil())
+					Expect(result).To(BeTrue())
+
+					// No service should be created
+					Expect(kerrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc))).To(BeTrue())
+					Expect(rs.Status.RsyncTLS.Address).To(BeNil())
 				})
 			})
 			When("When a remote address is not supplied", func() {
@@ -409,8 +410,49 @@ var _ = Describe("RsyncTLS as a source", func() {
 						},
 					}
 				})
-				It("No Service is created since this is the source", func() {
-					// Actual tests for svc not created are in the outer JustBeforeEach
+				It("Creates a Service for the destination to connect to", func() {
+					_, err := mover.ensureServiceAndPublishAddress(ctx)
+					Expect(err).To(BeNil())
+
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).To(Succeed())
+					Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+					Expect(svc.Spec.Ports[0].Port).To(Equal(int32(8000)))
+
+					// Check for default annotation VolSync adds
+					defaultAnnotation, ok := svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"]
+					Expect(ok).To(BeTrue())
+					Expect(defaultAnnotation).To(Equal("nlb"))
+
+					Eventually(func() bool {
+						gotAddr, err := mover.ensureServiceAndPublishAddress(ctx)
+						return err == nil && gotAddr
+					}, maxWait, interval).Should(BeTrue())
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).To(Succeed())
+					Expect(*rs.Status.RsyncTLS.Address).To(Equal(svc.Spec.ClusterIP))
+					Expect(*rs.Status.RsyncTLS.Port).To(Equal(int32(8000)))
+				})
+			})
+			When("When a remote address is not supplied and the service is customized", func() {
+				myCustAnnotations := map[string]string{
+					"custom-svc-annotation1": "apples",
+				}
+				var port int32 = 8123
+				BeforeEach(func() {
+					rs.Spec.RsyncTLS = &volsyncv1alpha1.ReplicationSourceRsyncTLSSpec{
+						ReplicationSourceVolumeOptions: volsyncv1alpha1.ReplicationSourceVolumeOptions{
+							CopyMethod: volsyncv1alpha1.CopyMethodClone,
+						},
+						Port:               &port,
+						ServiceAnnotations: &myCustAnnotations,
+					}
+				})
+				It("Creates a Service using the spec settings", func() {
+					_, err := mover.ensureServiceAndPublishAddress(ctx)
+					Expect(err).To(BeNil())
+
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).To(Succeed())
+					Expect(svc.Annotations).To(Equal(myCustAnnotations))
+					Expect(svc.Spec.Ports[0].Port).To(Equal(port))
 				})
 			})
 		})
@@ -445,6 +487,9 @@ var _ = Describe("RsyncTLS as a source", func() {
 						Namespace: rs.Namespace}, secret1)).To(Succeed())
 					Expect(secret1.Data).To(HaveKey("psk.txt"))
 					Expect(ownerMatches(secret1, rs.GetName(), true)).To(BeTrue())
+					// The direction is part of the name so it doesn't collide
+					// with the key of a destination of the same name
+					Expect(*keyName).To(Equal("volsync-rsync-tls-src-" + rs.Name))
 				})
 			})
 
@@ -641,8 +686,10 @@ var _ = Describe("RsyncTLS as a source", func() {
 					job = &batchv1.Job{}
 					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
 					Expect(len(job.Spec.Template.Spec.Containers)).To(Equal(1))
+					// No address, so the source waits for the destination to connect
 					Expect(job.Spec.Template.Spec.Containers[0].Command).To(Equal(
-						[]string{"/bin/bash", "-c", "/mover-rsync-tls/client.sh"}))
+						[]string{"/bin/bash", "-c", "/mover-rsync-tls/server.sh"}))
+					validateEnvVar(job.Spec.Template.Spec.Containers[0].Env, "MOVER_ROLE", "source")
 				})
 
 				It("should use the specified container image", func() {
@@ -887,6 +934,74 @@ var _ = Describe("RsyncTLS as a source", func() {
 					// Validate job env vars
 					env := job.Spec.Template.Spec.Containers[0].Env
 					validateEnvVar(env, "DESTINATION_ADDRESS", address)
+					validateEnvVar(env, "MOVER_ROLE", "source")
+					Expect(job.Spec.Template.Spec.Containers[0].Command).To(Equal(
+						[]string{"/bin/bash", "-c", "/mover-rsync-tls/client.sh"}))
+				})
+			})
+
+			When("a verification is requested", func() {
+				BeforeEach(func() {
+					address := "testserver.mydomain"
+					rs.Spec.RsyncTLS.Address = &address
+					rs.Spec.RsyncTLS.Verify = "v1"
+				})
+				It("should run the mover in verify mode", func() {
+					Expect(mover.VerifyRequested()).To(BeTrue())
+					j, e := mover.ensureJob(ctx, sPVC, sa, tlsKeySecret.GetName()) // Using sPVC as dataPVC (i.e. direct)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					validateEnvVar(job.Spec.Template.Spec.Containers[0].Env, "VERIFY_ONLY", "true")
+				})
+				When("the verification has already been performed", func() {
+					JustBeforeEach(func() {
+						mover.sourceStatus.Verification = &volsyncv1alpha1.RsyncVerificationStatus{Tag: "v1"}
+					})
+					It("should run the mover normally", func() {
+						Expect(mover.VerifyRequested()).To(BeFalse())
+						j, e := mover.ensureJob(ctx, sPVC, sa, tlsKeySecret.GetName()) // Using sPVC as dataPVC (i.e. direct)
+						Expect(e).NotTo(HaveOccurred())
+						Expect(j).To(BeNil()) // hasn't completed
+						nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+						job = &batchv1.Job{}
+						Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+						for _, envVar := range job.Spec.Template.Spec.Containers[0].Env {
+							Expect(envVar.Name).NotTo(Equal("VERIFY_ONLY"))
+						}
+					})
+				})
+			})
+
+			When("transfer options are specified in rsync spec", func() {
+				BeforeEach(func() {
+					address := "testserver.mydomain"
+					rs.Spec.RsyncTLS.Address = &address
+
+					bwLimit := resource.MustParse("1Mi")
+					rs.Spec.RsyncTLS.BandwidthLimit = &bwLimit
+					rs.Spec.RsyncTLS.CompressionLevel = ptr.To(int32(0))
+					rs.Spec.RsyncTLS.WholeFile = ptr.To(false)
+					rs.Spec.RsyncTLS.Checksum = ptr.To(true)
+					rs.Spec.RsyncTLS.Partial = ptr.To(true)
+				})
+				It("should pass the transfer options to the mover in env vars", func() {
+					j, e := mover.ensureJob(ctx, sPVC, sa, tlsKeySecret.GetName()) // Using sPVC as dataPVC (i.e. direct)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+
+					// Validate job env vars
+					env := job.Spec.Template.Spec.Containers[0].Env
+					validateEnvVar(env, "BANDWIDTH_LIMIT", "1024")
+					validateEnvVar(env, "COMPRESSION_LEVEL", "0")
+					validateEnvVar(env, "WHOLE_FILE", "false")
+					validateEnvVar(env, "CHECKSUM", "true")
+					validateEnvVar(env, "PARTIAL", "true")
 				})
 			})
 
@@ -958,6 +1073,41 @@ var _ = Describe("RsyncTLS as a source", func() {
 				})
 			})
 
+			When("the mover affinity is removed and the copyMethod is Snapshot", func() {
+				BeforeEach(func() {
+					rs.Spec.RsyncTLS.CopyMethod = volsyncv1alpha1.CopyMethodSnapshot
+				})
+				It("should recreate the job without it", func() {
+					mover.podOptions.MoverAffinity = &corev1.Affinity{
+						PodAntiAffinity: &corev1.PodAntiAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+								LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "backup"}},
+								TopologyKey:   "kubernetes.io/hostname",
+							}},
+						},
+					}
+					j, e := mover.ensureJob(ctx, sPVC, sa, tlsKeySecret.GetName())
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					Expect(job.Spec.Template.Spec.Affinity).To(Equal(mover.podOptions.MoverAffinity))
+
+					// The pod template can't be updated, so the job is replaced
+					mover.podOptions.MoverAffinity = nil
+					_, e = mover.ensureJob(ctx, sPVC, sa, tlsKeySecret.GetName())
+					Expect(e).To(HaveOccurred())
+					Expect(kerrors.IsNotFound(k8sClient.Get(ctx, nsn, job))).To(BeTrue())
+
+					j, e = mover.ensureJob(ctx, sPVC, sa, tlsKeySecret.GetName())
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil())
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					Expect(job.Spec.Template.Spec.Affinity).To(BeNil())
+				})
+			})
+
 			When("the job has failed", func() {
 				It("should be restarted", func() {
 					j, e := mover.ensureJob(ctx, sPVC, sa, tlsKeySecret.GetName()) // Using sPVC as dataPVC (i.e. direct)
@@ -988,6 +1138,217 @@ var _ = Describe("RsyncTLS as a source", func() {
 					Expect(job.Status.Failed).Should(Equal(int32(0)))
 				})
 			})
+
+			When("a block volume is being transferred", func() {
+				It("should keep the progress until the job completes", func() {
+					j, e := mover.ensureJob(ctx, sBlockPVC, sa, tlsKeySecret.GetName())
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+
+					// No mover pod reports any progress, so the previous
+					// progress is kept
+					progress := &volsyncv1alpha1.BlockTransferProgress{Percent: 42}
+					mover.sourceStatus.Progress = progress
+					j, e = mover.ensureJob(ctx, sBlockPVC, sa, tlsKeySecret.GetName())
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil())
+					Expect(mover.sourceStatus.Progress).To(Equal(progress))
+
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					now := metav1.Now()
+					job.Status.StartTime = &now
+					job.Status.CompletionTime = &now
+					job.Status.Succeeded = 1
+					Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+
+					j, e = mover.ensureJob(ctx, sBlockPVC, sa, tlsKeySecret.GetName())
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).NotTo(BeNil())
+					Expect(mover.sourceStatus.Progress).To(BeNil())
+				})
+
+				It("should identify the transfer by the source volume and the sync", func() {
+					syncStart := metav1.Now()
+					rs.Status.LastSyncStartTime = &syncStart
+					transferID := string(sBlockPVC.UID) + "-" + strconv.FormatInt(syncStart.Unix(), 10)
+
+					j, e := mover.ensureJob(ctx, sBlockPVC, sa, tlsKeySecret.GetName())
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					validateEnvVar(job.Spec.Template.Spec.Containers[0].Env, "TRANSFER_ID", transferID)
+
+					// A retry of the same sync resumes the same transfer
+					j, e = mover.ensureJob(ctx, sBlockPVC, sa, tlsKeySecret.GetName())
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil())
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					validateEnvVar(job.Spec.Template.Spec.Containers[0].Env, "TRANSFER_ID", transferID)
+				})
+
+				When("a verification is requested", func() {
+					BeforeEach(func() {
+						rs.Spec.RsyncTLS.Verify = "v1"
+					})
+					It("should be verified even when the destination connects to the source", func() {
+						Expect(rs.Spec.RsyncTLS.Address).To(BeNil())
+						Expect(mover.VerifyRequested()).To(BeTrue())
+						Expect(mover.verifyNotSupportedReason(sBlockPVC)).To(BeEmpty())
+						Expect(mover.verifyNotSupportedReason(sPVC)).NotTo(BeEmpty())
+
+						j, e := mover.ensureJob(ctx, sBlockPVC, sa, tlsKeySecret.GetName())
+						Expect(e).NotTo(HaveOccurred())
+						Expect(j).To(BeNil()) // hasn't completed
+						nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+						job = &batchv1.Job{}
+						Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+						validateEnvVar(job.Spec.Template.Spec.Containers[0].Env, "VERIFY_ONLY", "true")
+					})
+				})
+			})
+
+			When("multiple destinations are specified", func() {
+				var port int32 = 4567
+				var otherKeySecret *corev1.Secret
+				BeforeEach(func() {
+					otherKeySecret = &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "otherkeys",
+							Namespace: rs.Namespace,
+						},
+						StringData: map[string]string{
+							"psk.txt": "1:11111111111111111111111111111111",
+						},
+					}
+					rs.Spec.RsyncTLS.Destinations = []volsyncv1alpha1.ReplicationSourceRsyncTLSDestinationSpec{
+						{Address: "dest-a.mydomain"},
+						{Address: "dest-b.mydomain", Port: &port, KeySecret: &otherKeySecret.Name},
+					}
+				})
+				JustBeforeEach(func() {
+					Expect(k8sClient.Create(ctx, otherKeySecret)).To(Succeed())
+				})
+
+				completeJob := func(nsn types.NamespacedName) {
+					job := &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					now := metav1.Now()
+					job.Status.StartTime = &now
+					job.Status.CompletionTime = &now
+					job.Status.Succeeded = 1
+					Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+				}
+
+				It("does not create a Service", func() {
+					result, err := mover.ensureServiceAndPublishAddress(ctx)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(BeTrue())
+				})
+
+				It("should sync to each destination in turn", func() {
+					nsnA := types.NamespacedName{Name: jobName + "-0", Namespace: ns.Name}
+					nsnB := types.NamespacedName{Name: jobName + "-1", Namespace: ns.Name}
+
+					done, err := mover.ensureDestinationJobs(ctx, sPVC, sa, tlsKeySecret.GetName())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(done).To(BeFalse())
+
+					// Only the 1st destination's job should exist
+					jobA := &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsnA, jobA)).To(Succeed())
+					validateEnvVar(jobA.Spec.Template.Spec.Containers[0].Env, "DESTINATION_ADDRESS", "dest-a.mydomain")
+					Expect(keySecretForJob(jobA)).To(Equal(tlsKeySecret.GetName()))
+					Expect(kerrors.IsNotFound(k8sClient.Get(ctx, nsnB, &batchv1.Job{}))).To(BeTrue())
+
+					Expect(rs.Status.RsyncTLS.Destinations).To(HaveLen(2))
+					Expect(rs.Status.RsyncTLS.Destinations[0].Address).To(Equal("dest-a.mydomain"))
+					Expect(rs.Status.RsyncTLS.Destinations[0].LastSyncTime).To(BeNil())
+
+					completeJob(nsnA)
+					done, err = mover.ensureDestinationJobs(ctx, sPVC, sa, tlsKeySecret.GetName())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(done).To(BeFalse())
+					Expect(rs.Status.RsyncTLS.Destinations[0].LastSyncTime).NotTo(BeNil())
+					Expect(rs.Status.RsyncTLS.Destinations[0].LatestMoverStatus.Result).To(
+						Equal(volsyncv1alpha1.MoverResultSuccessful))
+
+					// Now the 2nd destination, using its own key
+					jobB := &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsnB, jobB)).To(Succeed())
+					env := jobB.Spec.Template.Spec.Containers[0].Env
+					validateEnvVar(env, "DESTINATION_ADDRESS", "dest-b.mydomain")
+					validateEnvVar(env, "DESTINATION_PORT", strconv.Itoa(int(port)))
+					Expect(keySecretForJob(jobB)).To(Equal(otherKeySecret.GetName()))
+					Expect(rs.Status.RsyncTLS.Destinations[1].LastSyncTime).To(BeNil())
+
+					completeJob(nsnB)
+					done, err = mover.ensureDestinationJobs(ctx, sPVC, sa, tlsKeySecret.GetName())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(done).To(BeTrue())
+					Expect(rs.Status.RsyncTLS.Destinations[1].LastSyncTime).NotTo(BeNil())
+					Expect(rs.Status.LatestMoverStatus.Result).To(Equal(volsyncv1alpha1.MoverResultSuccessful))
+				})
+
+				It("moves on to the next destination once one has failed", func() {
+					nsnA := types.NamespacedName{Name: jobName + "-0", Namespace: ns.Name}
+					nsnB := types.NamespacedName{Name: jobName + "-1", Namespace: ns.Name}
+					recorder := events.NewFakeRecorder(10)
+					mover.eventRecorder = recorder
+
+					done, err := mover.ensureDestinationJobs(ctx, sPVC, sa, tlsKeySecret.GetName())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(done).To(BeFalse())
+
+					// The 1st destination's mover fails up to the backoff limit
+					jobA := &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsnA, jobA)).To(Succeed())
+					jobA.Status.Failed = *jobA.Spec.BackoffLimit
+					Expect(k8sClient.Status().Update(ctx, jobA)).To(Succeed())
+
+					done, err = mover.ensureDestinationJobs(ctx, sPVC, sa, tlsKeySecret.GetName())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(done).To(BeFalse())
+					destA := rs.Status.RsyncTLS.Destinations[0]
+					Expect(destA.LastSyncTime).To(BeNil())
+					Expect(destA.LastFailureTime).NotTo(BeNil())
+					Expect(destA.LatestMoverStatus.Result).To(Equal(volsyncv1alpha1.MoverResultFailed))
+					Expect(recorder.Events).To(HaveLen(3)) // job A started and failed, job B started
+
+					// The failed Job is kept so it isn't retried, the 2nd destination is synced
+					Expect(k8sClient.Get(ctx, nsnA, &batchv1.Job{})).To(Succeed())
+					completeJob(nsnB)
+					done, err = mover.ensureDestinationJobs(ctx, sPVC, sa, tlsKeySecret.GetName())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(done).To(BeTrue())
+					Expect(rs.Status.RsyncTLS.Destinations[0].LastFailureTime).To(Equal(destA.LastFailureTime))
+					Expect(recorder.Events).To(HaveLen(3))
+					Expect(rs.Status.RsyncTLS.Destinations[1].LastSyncTime).NotTo(BeNil())
+				})
+
+				It("keeps the status of destinations that share an address", func() {
+					rs.Spec.RsyncTLS.Destinations = []volsyncv1alpha1.ReplicationSourceRsyncTLSDestinationSpec{
+						{Address: "dest-a.mydomain"},
+						{Address: "dest-a.mydomain", Port: &port},
+					}
+					syncTime := metav1.Now()
+					rs.Status.RsyncTLS.Destinations = []volsyncv1alpha1.ReplicationSourceRsyncTLSDestinationStatus{
+						{Address: "dest-a.mydomain", Port: &port, LastSyncTime: &syncTime},
+						{Address: "dest-a.mydomain"},
+					}
+					mover.destinations = rs.Spec.RsyncTLS.Destinations
+
+					mover.updateStatusDestinations()
+					Expect(rs.Status.RsyncTLS.Destinations).To(HaveLen(2))
+					Expect(rs.Status.RsyncTLS.Destinations[0].Port).To(BeNil())
+					Expect(rs.Status.RsyncTLS.Destinations[0].LastSyncTime).To(BeNil())
+					Expect(rs.Status.RsyncTLS.Destinations[1].Port).To(Equal(&port))
+					Expect(rs.Status.RsyncTLS.Destinations[1].LastSyncTime).To(Equal(&syncTime))
+				})
+			})
 		})
 	})
 })
@@ -1208,6 +1569,29 @@ var _ = Describe("Rsync as a destination", func() {
 			})
 		})
 
+		Context("Service is not needed when connecting to a listening source", func() {
+			remoteAddr := "testing.remote.source.com"
+			BeforeEach(func() {
+				rd.Spec.RsyncTLS = &volsyncv1alpha1.ReplicationDestinationRsyncTLSSpec{
+					Address: &remoteAddr,
+				}
+			})
+			It("No Service is created", func() {
+				result, err := mover.ensureServiceAndPublishAddress(ctx)
+				Expect(err).To(BeNil())
+				Expect(result).To(BeTrue())
+
+				svc := &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "volsync-rsync-tls-dst-" + rd.Name,
+						Namespace: rd.Namespace,
+					},
+				}
+				Expect(kerrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc))).To(BeTrue())
+				Expect(rd.Status.RsyncTLS.Address).To(BeNil())
+			})
+		})
+
 		//nolint:dupl
 		Context("TLS key is handled properly", func() {
 			When("key is not specified", func() {
@@ -1237,6 +1621,31 @@ var _ = Describe("Rsync as a destination", func() {
 						Namespace: rd.Namespace}, secret)).To(Succeed())
 					Expect(secret.Data).To(HaveKey("psk.txt"))
 					Expect(ownerMatches(secret, rd.GetName(), false)).To(BeTrue())
+					Expect(*keyName).To(Equal("volsync-rsync-tls-dst-" + rd.Name))
+				})
+				When("a key was generated before the direction was part of its name", func() {
+					var legacySecret *corev1.Secret
+					BeforeEach(func() {
+						legacySecret = &corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "volsync-rsync-tls-" + rd.Name,
+								Namespace: rd.Namespace,
+							},
+							StringData: map[string]string{
+								"psk.txt": "volsync:abcdef",
+							},
+						}
+					})
+					JustBeforeEach(func() {
+						Expect(ctrl.SetControllerReference(rd, legacySecret, k8sClient.Scheme())).To(Succeed())
+						Expect(k8sClient.Create(ctx, legacySecret)).To(Succeed())
+					})
+					It("keeps using it", func() {
+						keyName, err := mover.ensureSecrets(ctx)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(*keyName).To(Equal(legacySecret.Name))
+						Expect(*rd.Status.RsyncTLS.KeySecret).To(Equal(legacySecret.Name))
+					})
 				})
 			})
 
@@ -1421,6 +1830,127 @@ var _ = Describe("Rsync as a destination", func() {
 					Expect(len(job.Spec.Template.Spec.Containers)).To(Equal(1))
 					Expect(job.Spec.Template.Spec.Containers[0].Command).To(Equal(
 						[]string{"/bin/bash", "-c", "/mover-rsync-tls/server.sh"}))
+					validateEnvVar(job.Spec.Template.Spec.Containers[0].Env, "MOVER_ROLE", "destination")
+				})
+			})
+			When("the source address and port are specified in the rsync spec", func() {
+				var address string
+				var port int32
+				BeforeEach(func() {
+					address = "testsource.mydomain"
+					rd.Spec.RsyncTLS.Address = &address
+
+					port = 4567
+					rd.Spec.RsyncTLS.Port = &port
+				})
+				It("should connect to the source", func() {
+					j, e := mover.ensureJob(ctx, dPVC, sa, testKey)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					Expect(job.Spec.Template.Spec.Containers[0].Command).To(Equal(
+						[]string{"/bin/bash", "-c", "/mover-rsync-tls/client.sh"}))
+
+					env := job.Spec.Template.Spec.Containers[0].Env
+					validateEnvVar(env, "SOURCE_ADDRESS", address)
+					validateEnvVar(env, "SOURCE_PORT", strconv.Itoa(int(port)))
+					validateEnvVar(env, "MOVER_ROLE", "destination")
+				})
+			})
+			When("the destination volume is a block device", func() {
+				BeforeEach(func() {
+					blockVolumeMode := corev1.PersistentVolumeBlock
+					dPVC.Spec.VolumeMode = &blockVolumeMode
+				})
+				It("should keep the progress of the transfer on a volume of its own", func() {
+					Expect(mover.ensureCheckpointPVC(ctx)).To(Succeed())
+					checkpointPVC := &corev1.PersistentVolumeClaim{}
+					Expect(k8sClient.Get(ctx, types.NamespacedName{
+						Name:      "volsync-rsync-tls-checkpoint-" + rd.Name,
+						Namespace: ns.Name,
+					}, checkpointPVC)).To(Succeed())
+					Expect(checkpointPVC.OwnerReferences).To(HaveLen(1))
+					Expect(checkpointPVC.OwnerReferences[0].UID).To(Equal(rd.UID))
+					// It's kept between synchronizations
+					Expect(checkpointPVC.Labels).NotTo(HaveKey("volsync.backube/cleanup"))
+
+					j, e := mover.ensureJob(ctx, dPVC, sa, testKey)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					podSpec := job.Spec.Template.Spec
+					Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+						Name: "checkpoint",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+								ClaimName: checkpointPVC.Name,
+							},
+						},
+					}))
+					Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+						Name:      "checkpoint",
+						MountPath: "/checkpoint",
+					}))
+				})
+			})
+			When("the mover finds the destination volume full", func() {
+				BeforeEach(func() {
+					capacity := resource.MustParse("1Gi")
+					rd.Spec.RsyncTLS.Capacity = &capacity
+					rd.Spec.RsyncTLS.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+				})
+				It("should record the capacity it needs and restart the job", func() {
+					dataPVC, e := mover.ensureDestinationPVC(ctx)
+					Expect(e).NotTo(HaveOccurred())
+					dataPVC.Status.Phase = corev1.ClaimBound
+					dataPVC.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}
+					Expect(k8sClient.Status().Update(ctx, dataPVC)).To(Succeed())
+
+					j, e := mover.ensureJob(ctx, dataPVC, sa, testKey)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+
+					// The mover pod fails, reporting the size of the data
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      jobName + "-full",
+							Namespace: ns.Name,
+							Labels:    map[string]string{"job-name": jobName},
+						},
+						Spec: job.Spec.Template.Spec,
+					}
+					Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+					pod.Status.Phase = corev1.PodFailed
+					pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+						Name: pod.Spec.Containers[0].Name,
+						State: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{
+								Message: `{"noSpace":true,"requiredBytes":2147483648}`,
+							},
+						},
+					}}
+					Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+					job.Status.Failed = 1
+					Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+
+					Eventually(func() bool {
+						j, e = mover.ensureJob(ctx, dataPVC, sa, testKey)
+						Expect(e).NotTo(HaveOccurred())
+						Expect(j).To(BeNil())
+						return kerrors.IsNotFound(k8sClient.Get(ctx, nsn, job))
+					}, maxWait, interval).Should(BeTrue())
+
+					// The volume grows to hold the data on the next reconcile
+					dataPVC, e = mover.ensureDestinationPVC(ctx)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(dataPVC.Annotations).To(HaveKeyWithValue("volsync.backube/required-capacity", "2253Mi"))
 				})
 			})
 		})
@@ -1575,6 +2105,15 @@ func validateSaRoleAndRoleBinding(sa *corev1.ServiceAccount, namespace string, p
 	}
 }
 
+func keySecretForJob(job *batchv1.Job) string {
+	for _, v := range job.Spec.Template.Spec.Volumes {
+		if v.Name == "keys" && v.Secret != nil {
+			return v.Secret.SecretName
+		}
+	}
+	return ""
+}
+
 func validateEnvVar(env []corev1.EnvVar, envVarName, envVarExpectedValue string) {
 	found := false
 	for _, envVar := range env {
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 
@@ -19,6 +21,7 @@ var _ = Describe("Syncthing connection", func() {
 			var (
 				ts          *httptest.Server
 				serverState *Syncthing
+				folders     *testFolderDetails
 				myID, _     = protocol.DeviceIDFromString(
 					"ZNWFSWE-RWRV2BD-45BLMCV-LTDE2UR-4LJDW6J-R5BPWEB-TXD27XJ-IZF5RA4",
 				)
@@ -27,6 +30,7 @@ var _ = Describe("Syncthing connection", func() {
 
 			BeforeEach(func() {
 				serverState = &Syncthing{}
+				folders = &testFolderDetails{state: serverState}
 			})
 
 			JustBeforeEach(func() {
@@ -35,7 +39,7 @@ var _ = Describe("Syncthing connection", func() {
 				serverState.SystemStatus.MyID = myID.GoString()
 				serverState.SystemConnections.Total = TotalStats{At: "test"}
 
-				ts = CreateSyncthingTestServer(serverState, serverAPIKey)
+				ts = CreateSyncthingTestServer(serverState, serverAPIKey, folders)
 			})
 
 			JustAfterEach(func() {
@@ -67,6 +71,87 @@ var _ = Describe("Syncthing connection", func() {
 					Expect(syncthing.SystemConnections.Total.At).To(Equal("test"))
 				})
 
+				When("folders are configured", func() {
+					BeforeEach(func() {
+						serverState.Configuration.Folders = []config.FolderConfiguration{
+							{ID: "folder-1", Path: "/data"},
+							{ID: "folder-2", Path: "/other"},
+						}
+						serverState.FolderStatuses = map[string]FolderStatus{
+							"folder-1": {State: "idle", GlobalBytes: 42, InSyncBytes: 42, Sequence: 7},
+						}
+					})
+
+					It("fetches the status of each folder", func() {
+						syncthing, err := syncthingConnection.Fetch()
+						Expect(err).NotTo(HaveOccurred())
+						Expect(syncthing.FolderStatuses).To(HaveLen(2))
+						Expect(syncthing.FolderStatuses["folder-1"]).To(Equal(serverState.FolderStatuses["folder-1"]))
+						Expect(syncthing.FolderStatuses["folder-2"]).To(BeZero())
+
+						folder, ok := syncthing.GetFolderFromPath("/other")
+						Expect(ok).To(BeTrue())
+						Expect(folder.ID).To(Equal("folder-2"))
+						_, ok = syncthing.GetFolderFromID("folder-3")
+						Expect(ok).To(BeFalse())
+					})
+
+					It("fetches the completion, errors and conflicts of a folder", func() {
+						folders.completions = map[string]map[string]FolderCompletion{
+							"folder-1": {myID.GoString(): {Completion: 75, NeedBytes: 25, NeedItems: 1}},
+						}
+						folders.errors = map[string][]FolderError{
+							"folder-1": {{Path: "a/b", Error: "no space left on device"}},
+						}
+						folders.trees = map[string][]TreeEntry{
+							"folder-1": {
+								{Name: "a", Children: []TreeEntry{
+									{Name: "b"},
+									{Name: "b.sync-conflict-20231002-101010-ABCDEFG"},
+								}},
+								{Name: "c.sync-conflict-20231002-101010-ABCDEFG.txt"},
+							},
+						}
+
+						completion, err := syncthingConnection.FetchFolderCompletion("folder-1", myID.GoString())
+						Expect(err).NotTo(HaveOccurred())
+						Expect(completion).To(Equal(&FolderCompletion{Completion: 75, NeedBytes: 25, NeedItems: 1}))
+
+						folderErrors, err := syncthingConnection.FetchFolderErrors("folder-1")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(folderErrors).To(Equal(folders.errors["folder-1"]))
+
+						conflicts, err := syncthingConnection.FetchFolderConflicts("folder-1")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(conflicts).To(Equal([]string{
+							"a/b.sync-conflict-20231002-101010-ABCDEFG",
+							"c.sync-conflict-20231002-101010-ABCDEFG.txt",
+						}))
+
+						conflicts, err = syncthingConnection.FetchFolderConflicts("folder-2")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(conflicts).To(BeEmpty())
+
+						_, err = syncthingConnection.FetchFolderCompletion("folder-3", myID.GoString())
+						Expect(err).To(HaveOccurred())
+						_, err = syncthingConnection.FetchFolderErrors("folder-3")
+						Expect(err).To(HaveOccurred())
+					})
+
+					It("updates the ignore patterns of a folder", func() {
+						ignores := []string{"*.tmp", "lost+found"}
+						Expect(syncthingConnection.PublishIgnores("folder-1", ignores)).To(Succeed())
+						Expect(serverState.FolderIgnores["folder-1"]).To(Equal(ignores))
+
+						syncthing, err := syncthingConnection.Fetch()
+						Expect(err).NotTo(HaveOccurred())
+						Expect(syncthing.FolderIgnores["folder-1"]).To(Equal(ignores))
+						Expect(syncthing.FolderIgnores["folder-2"]).To(BeEmpty())
+
+						Expect(syncthingConnection.PublishIgnores("folder-3", ignores)).NotTo(Succeed())
+					})
+				})
+
 				It("updates the Syncthing Config", func() {
 					syncthing := &Syncthing{
 						Configuration: config.Configuration{
@@ -319,3 +404,44 @@ var _ = Describe("APIConfig", func() {
 		})
 	})
 })
+
+// testFolderDetails serves the completion, errors and files of the folders of
+// the test server, which Syncthing only reports for a given folder
+type testFolderDetails struct {
+	state *Syncthing
+	// completions are keyed by the folder ID and then by the device ID
+	completions map[string]map[string]FolderCompletion
+	errors      map[string][]FolderError
+	trees       map[string][]TreeEntry
+}
+
+func (d *testFolderDetails) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	folderID := r.URL.Query().Get("folder")
+	if _, ok := d.state.GetFolderFromID(folderID); !ok {
+		http.Error(w, "no such folder", http.StatusNotFound)
+		return
+	}
+	var res interface{}
+	switch r.URL.Path {
+	case DBCompletionEndpoint:
+		// devices without a completion set are reported as in sync
+		completion, ok := d.completions[folderID][r.URL.Query().Get("device")]
+		if !ok {
+			completion = FolderCompletion{Completion: 100}
+		}
+		res = completion
+	case FolderErrorsEndpoint:
+		res = FolderErrors{Folder: folderID, Errors: d.errors[folderID], Page: 1, PerPage: 1 << 16}
+	case DBBrowseEndpoint:
+		tree := d.trees[folderID]
+		if tree == nil {
+			tree = []TreeEntry{}
+		}
+		res = tree
+	default:
+		http.Error(w, "the resource path doesn't exist", http.StatusNotFound)
+		return
+	}
+	resBytes, _ := json.Marshal(res)
+	fmt.Fprintln(w, string(resBytes))
+}
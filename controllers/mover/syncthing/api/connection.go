@@ -18,7 +18,9 @@ package api
 
 import (
 	"crypto/tls"
+	"encoding/json"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -30,6 +32,11 @@ const (
 	SystemStatusEndpoint      = "/rest/system/status"
 	SystemConnectionsEndpoint = "/rest/system/connections"
 	ConfigEndpoint            = "/rest/config"
+	DBStatusEndpoint          = "/rest/db/status"
+	DBIgnoresEndpoint         = "/rest/db/ignores"
+	DBCompletionEndpoint      = "/rest/db/completion"
+	DBBrowseEndpoint          = "/rest/db/browse"
+	FolderErrorsEndpoint      = "/rest/folder/errors"
 )
 
 // Fetch Pulls all of Syncthing's latest information from the API and stores it
@@ -53,10 +60,29 @@ func (s *syncthingAPIConnection) Fetch() (*Syncthing, error) {
 		return nil, err
 	}
 
+	// get and store the status and ignore patterns of each folder
+	folderStatuses := make(map[string]FolderStatus, len(conf.Folders))
+	folderIgnores := make(map[string][]string, len(conf.Folders))
+	for _, folder := range conf.Folders {
+		folderStatus, err := s.fetchFolderStatus(folder.ID)
+		if err != nil {
+			return nil, err
+		}
+		folderStatuses[folder.ID] = *folderStatus
+
+		ignores, err := s.fetchFolderIgnores(folder.ID)
+		if err != nil {
+			return nil, err
+		}
+		folderIgnores[folder.ID] = ignores.Ignore
+	}
+
 	return &Syncthing{
 		Configuration:     *conf,
 		SystemConnections: *systemConnections,
 		SystemStatus:      *systemStatus,
+		FolderStatuses:    folderStatuses,
+		FolderIgnores:     folderIgnores,
 	}, nil
 }
 
@@ -72,6 +98,69 @@ func (s *syncthingAPIConnection) PublishConfig(conf config.Configuration) error
 	return err
 }
 
+// PublishIgnores Replaces the ignore patterns of the given folder, which Syncthing keeps
+// in the folder's .stignore file rather than in its configuration.
+// An error is returned in the case of a failure.
+func (s *syncthingAPIConnection) PublishIgnores(folderID string, ignores []string) error {
+	s.logger.Info("Updating Syncthing ignore patterns", "folder", folderID)
+	_, err := s.jsonRequest(folderEndpoint(DBIgnoresEndpoint, folderID), "POST", FolderIgnores{Ignore: ignores})
+	if err != nil {
+		s.logger.Error(err, "Failed to update Syncthing ignore patterns")
+	}
+	return err
+}
+
+// FetchFolderCompletion Returns how much of the given folder the device with the given ID
+// has in sync with the global state. Syncthing only knows this for the local device and
+// the devices the folder is shared with.
+func (s *syncthingAPIConnection) FetchFolderCompletion(folderID string, deviceID string) (*FolderCompletion, error) {
+	responseBody := &FolderCompletion{}
+	s.logger.V(1).Info("Fetching Syncthing folder completion", "folder", folderID, "device", deviceID)
+	endpoint := folderEndpoint(DBCompletionEndpoint, folderID) + "&device=" + url.QueryEscape(deviceID)
+	data, err := s.jsonRequest(endpoint, "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, responseBody); err != nil {
+		return nil, err
+	}
+	return responseBody, nil
+}
+
+// FetchFolderErrors Returns the files of the given folder which couldn't be synced
+// during the last pull.
+func (s *syncthingAPIConnection) FetchFolderErrors(folderID string) ([]FolderError, error) {
+	responseBody := &FolderErrors{}
+	s.logger.V(1).Info("Fetching Syncthing folder errors", "folder", folderID)
+	data, err := s.jsonRequest(folderEndpoint(FolderErrorsEndpoint, folderID), "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, responseBody); err != nil {
+		return nil, err
+	}
+	return responseBody.Errors, nil
+}
+
+// FetchFolderConflicts Returns the paths of the conflict copies in the given folder.
+// Syncthing doesn't keep track of these, so the whole tree of the folder is browsed
+// for the files it has renamed after a conflict.
+func (s *syncthingAPIConnection) FetchFolderConflicts(folderID string) ([]string, error) {
+	tree := []TreeEntry{}
+	s.logger.V(1).Info("Browsing Syncthing folder for conflicts", "folder", folderID)
+	data, err := s.jsonRequest(folderEndpoint(DBBrowseEndpoint, folderID), "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return findConflicts(tree, ""), nil
+}
+
 // NewConnection accepts an APIConfig object and a logger and creates a SyncthingConnection
 // object in return.
 func NewConnection(cfg APIConfig, logger logr.Logger) SyncthingConnection {
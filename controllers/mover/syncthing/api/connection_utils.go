@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"path"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -136,6 +138,61 @@ func (api *syncthingAPIConnection) fetchSystemConnections() (*SystemConnections,
 	return responseBody, nil
 }
 
+// fetchFolderStatus Fetches the status of the folder with the given ID from the Syncthing API.
+// Returns a FolderStatus object if successful, error otherwise.
+func (api *syncthingAPIConnection) fetchFolderStatus(folderID string) (*FolderStatus, error) {
+	responseBody := &FolderStatus{}
+	api.logger.Info("Fetching Syncthing folder status", "folder", folderID)
+	data, err := api.jsonRequest(folderEndpoint(DBStatusEndpoint, folderID), "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, responseBody); err != nil {
+		return nil, err
+	}
+	return responseBody, nil
+}
+
+// fetchFolderIgnores Fetches the ignore patterns of the folder with the given ID from the Syncthing API.
+// Returns a FolderIgnores object if successful, error otherwise.
+func (api *syncthingAPIConnection) fetchFolderIgnores(folderID string) (*FolderIgnores, error) {
+	responseBody := &FolderIgnores{}
+	api.logger.Info("Fetching Syncthing folder ignore patterns", "folder", folderID)
+	data, err := api.jsonRequest(folderEndpoint(DBIgnoresEndpoint, folderID), "GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, responseBody); err != nil {
+		return nil, err
+	}
+	return responseBody, nil
+}
+
+// folderEndpoint Returns the given endpoint with a query selecting the folder with the given ID.
+func folderEndpoint(endpoint string, folderID string) string {
+	return endpoint + "?folder=" + url.QueryEscape(folderID)
+}
+
+// conflictMarker Is part of the name Syncthing gives to the copy of a file which was changed
+// on more than one device at the same time: <name>.sync-conflict-<date>-<time>-<device><ext>
+const conflictMarker = ".sync-conflict-"
+
+// findConflicts Returns the paths of the conflict copies in the given tree, relative to its root,
+// which is located at the given path.
+func findConflicts(entries []TreeEntry, dir string) []string {
+	conflicts := []string{}
+	for _, entry := range entries {
+		entryPath := path.Join(dir, entry.Name)
+		if strings.Contains(entry.Name, conflictMarker) {
+			conflicts = append(conflicts, entryPath)
+		}
+		conflicts = append(conflicts, findConflicts(entry.Children, entryPath)...)
+	}
+	return conflicts
+}
+
 // checkResponse Returns an error if one exists in the response, or nil otherwise.
 // This function was extracted from the Syncthing repository
 // due to the overlapping functionality between our API access & the Syncthing CLI.
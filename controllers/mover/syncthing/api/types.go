@@ -71,6 +71,65 @@ type SystemConnections struct {
 	Connections map[string]ConnectionStats `json:"connections"`
 }
 
+// FolderStatus Describes how far along a folder is in syncing with the other devices,
+// as returned for each folder by the db/status endpoint.
+type FolderStatus struct {
+	Errors           int    `json:"errors"`
+	GlobalBytes      int64  `json:"globalBytes"`
+	GlobalTotalItems int    `json:"globalTotalItems"`
+	InSyncBytes      int64  `json:"inSyncBytes"`
+	NeedBytes        int64  `json:"needBytes"`
+	NeedTotalItems   int    `json:"needTotalItems"`
+	State            string `json:"state"`
+	StateChanged     string `json:"stateChanged"`
+	Error            string `json:"error"`
+	// Sequence Is increased every time a file in the folder changes on any device.
+	Sequence int64 `json:"sequence"`
+}
+
+// FolderCompletion Describes how much of a folder a device has in sync with the global state,
+// as returned by the db/completion endpoint.
+type FolderCompletion struct {
+	// Completion Is the percentage of the folder that is in sync.
+	Completion  float64 `json:"completion"`
+	GlobalBytes int64   `json:"globalBytes"`
+	NeedBytes   int64   `json:"needBytes"`
+	GlobalItems int     `json:"globalItems"`
+	NeedItems   int     `json:"needItems"`
+	NeedDeletes int     `json:"needDeletes"`
+	Sequence    int64   `json:"sequence"`
+	RemoteState string  `json:"remoteState"`
+}
+
+// FolderError Describes a file of a folder which couldn't be synced.
+type FolderError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// FolderErrors Describes the files which couldn't be synced in a folder,
+// as returned by the folder/errors endpoint.
+type FolderErrors struct {
+	Folder  string        `json:"folder"`
+	Errors  []FolderError `json:"errors"`
+	Page    int           `json:"page"`
+	PerPage int           `json:"perpage"`
+}
+
+// TreeEntry Describes a file or directory of a folder as returned by the db/browse endpoint.
+// Only directories have children.
+type TreeEntry struct {
+	Name     string      `json:"name"`
+	Size     int64       `json:"size"`
+	Children []TreeEntry `json:"children,omitempty"`
+}
+
+// FolderIgnores Describes the ignore patterns of a folder, as read from and written to
+// its .stignore file through the db/ignores endpoint.
+type FolderIgnores struct {
+	Ignore []string `json:"ignore"`
+}
+
 // APIConfig Describes the necessary elements needed to configure a client
 // with the Syncthing API, included the credentials, URL, TLS Certs.
 // This requires nolint:revive because the package it's in is called "api,"
@@ -89,13 +148,23 @@ type SyncthingConnection interface {
 	// API Functions, these are meant to define communication with the Syncthing API.
 	Fetch() (*Syncthing, error)
 	PublishConfig(config.Configuration) error
+	PublishIgnores(folderID string, ignores []string) error
+	FetchFolderCompletion(folderID string, deviceID string) (*FolderCompletion, error)
+	FetchFolderErrors(folderID string) ([]FolderError, error)
+	FetchFolderConflicts(folderID string) ([]string, error)
 }
 
 // Syncthing Defines a Syncthing API object which contains a subset of the information
 // exposed through Syncthing's API. Namely, this struct exposes the configuration,
-// system status, and connections contained by the given object.
+// system status, and connections contained by the given object, along with
+// the status and ignore patterns of each of its folders, keyed by the folder ID.
+//
+// Computing the completion, errors and files of a folder is costlier for Syncthing, so they
+// aren't retrieved by Fetch and must be requested for a given folder instead.
 type Syncthing struct {
 	Configuration     config.Configuration
 	SystemConnections SystemConnections
 	SystemStatus      SystemStatus
+	FolderStatuses    map[string]FolderStatus
+	FolderIgnores     map[string][]string
 }
@@ -36,6 +36,28 @@ func (s *Syncthing) GetDeviceFromID(id string) (*config.DeviceConfiguration, boo
 	return nil, false
 }
 
+// GetFolderFromID Returns a pointer to the folder with the given ID,
+// along with a boolean indicating whether the folder was found.
+func (s *Syncthing) GetFolderFromID(id string) (*config.FolderConfiguration, bool) {
+	for i := range s.Configuration.Folders {
+		if s.Configuration.Folders[i].ID == id {
+			return &s.Configuration.Folders[i], true
+		}
+	}
+	return nil, false
+}
+
+// GetFolderFromPath Returns a pointer to the folder syncing the given path,
+// along with a boolean indicating whether the folder was found.
+func (s *Syncthing) GetFolderFromPath(path string) (*config.FolderConfiguration, bool) {
+	for i := range s.Configuration.Folders {
+		if s.Configuration.Folders[i].Path == path {
+			return &s.Configuration.Folders[i], true
+		}
+	}
+	return nil, false
+}
+
 // MyID Is a convenience method which returns the current Syncthing device's ID.
 func (s *Syncthing) MyID() string { return s.SystemStatus.MyID }
 
@@ -64,13 +86,15 @@ func (s *Syncthing) ShareFoldersWithDevices() {
 }
 
 // CreateSyncthingTestServer Returns a test server that mimics the Syncthing API by exposing
-// the endpoints for config, system status, and system connections.
+// the endpoints for config, system status, system connections, and the folders.
 // The server also accepts an API Key, which is used for authenticating between the client and server.
 //
 // The accepted arguments are pointers so that the state can be changed externally and the server
-// will be updated accordingly.
+// will be updated accordingly. The completion, errors and files of the folders aren't part of
+// the Syncthing object, requests for them are passed to folderHandler if it isn't nil.
 // nolint:funlen
-func CreateSyncthingTestServer(state *Syncthing, serverAPIKey string) *httptest.Server {
+func CreateSyncthingTestServer(state *Syncthing, serverAPIKey string,
+	folderHandler http.Handler) *httptest.Server {
 	setConnections := func(s *Syncthing) {
 		connections := make(map[string]ConnectionStats, 0)
 		for _, device := range s.Configuration.Devices {
@@ -117,7 +141,43 @@ func CreateSyncthingTestServer(state *Syncthing, serverAPIKey string) *httptest.
 			resBytes, _ := json.Marshal(res)
 			fmt.Fprintln(w, string(resBytes))
 			return
+		case DBStatusEndpoint:
+			folderID := r.URL.Query().Get("folder")
+			if _, ok := state.GetFolderFromID(folderID); !ok {
+				http.Error(w, "no such folder", http.StatusNotFound)
+				return
+			}
+			// folders without a status set are reported as empty
+			res := state.FolderStatuses[folderID]
+			resBytes, _ := json.Marshal(res)
+			fmt.Fprintln(w, string(resBytes))
+			return
+		case DBIgnoresEndpoint:
+			folderID := r.URL.Query().Get("folder")
+			if _, ok := state.GetFolderFromID(folderID); !ok {
+				http.Error(w, "no such folder", http.StatusNotFound)
+				return
+			}
+			if r.Method == "POST" {
+				ignores := FolderIgnores{}
+				if err := json.NewDecoder(r.Body).Decode(&ignores); err != nil {
+					http.Error(w, "Error decoding request body", http.StatusBadRequest)
+					return
+				}
+				if state.FolderIgnores == nil {
+					state.FolderIgnores = map[string][]string{}
+				}
+				state.FolderIgnores[folderID] = ignores.Ignore
+			}
+			res := FolderIgnores{Ignore: state.FolderIgnores[folderID]}
+			resBytes, _ := json.Marshal(res)
+			fmt.Fprintln(w, string(resBytes))
+			return
 		default:
+			if folderHandler != nil {
+				folderHandler.ServeHTTP(w, r)
+				return
+			}
 			// the endpoint doesn't exist
 			http.Error(w, "the resource path doesn't exist", http.StatusNotFound)
 			return
@@ -18,11 +18,13 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package syncthing
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/viper"
+	"github.com/syncthing/syncthing/lib/config"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,6 +33,7 @@ import (
 	"github.com/backube/volsync/controllers/mover"
 	"github.com/backube/volsync/controllers/mover/syncthing/api"
 	"github.com/backube/volsync/controllers/utils"
+	"github.com/backube/volsync/controllers/volumehandler"
 )
 
 // syncthingContainerImage is the container image name of the syncthing data mover
@@ -97,6 +100,10 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 	if source.Spec.Syncthing == nil {
 		return nil, nil
 	}
+	// Syncthing continuously syncs the live volume
+	if source.Spec.SourceSnapshot != nil {
+		return nil, errors.New("syncthing does not support a sourceSnapshot")
+	}
 
 	// Create ReplicationSourceSyncthingStatus to write syncthing status
 	if source.Status.Syncthing == nil {
@@ -111,6 +118,11 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		serviceType = corev1.ServiceTypeClusterIP
 	}
 
+	folderType, err := folderTypeFromSpec(source.Spec.Syncthing.FolderType)
+	if err != nil {
+		return nil, err
+	}
+
 	saHandler := utils.NewSAHandler(client, source, true, privileged,
 		source.Spec.Syncthing.MoverServiceAccount)
 
@@ -127,6 +139,9 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		configAccessModes:    source.Spec.Syncthing.ConfigAccessModes,
 		containerImage:       rb.getSyncthingContainerImage(),
 		peerList:             source.Spec.Syncthing.Peers,
+		peerSelector:         source.Spec.Syncthing.PeerSelector,
+		peerNSSelector:       source.Spec.Syncthing.PeerNamespaceSelector,
+		connectivity:         source.Spec.Syncthing.SyncthingConnectivityOptions,
 		paused:               source.Spec.Paused,
 		dataPVCName:          &source.Spec.SourcePVC,
 		status:               source.Status.Syncthing,
@@ -135,13 +150,82 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		apiConfig:            api.APIConfig{},
 		privileged:           privileged,
 		moverSecurityContext: source.Spec.Syncthing.MoverSecurityContext,
+		podOptions:           source.Spec.Syncthing.MoverPodOptions,
+		isSource:             true,
+		folderType:           folderType,
+		versioning:           source.Spec.Syncthing.Versioning,
+		ignorePatterns:       source.Spec.Syncthing.IgnorePatterns,
 		// defer setting the VolumeHandler
 	}, nil
 }
 
-// FromDestination Doesn't implement Syncthing, so nil is returned in both cases.
-func (rb *Builder) FromDestination(_ client.Client, _ logr.Logger,
-	_ events.EventRecorder,
-	_ *volsyncv1alpha1.ReplicationDestination, _ bool) (mover.Mover, error) {
-	return nil, nil
+// FromDestination Builds a Syncthing mover object from a given ReplicationDestination object.
+// The destination volume is shared as a receive-only folder.
+func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
+	eventRecorder events.EventRecorder,
+	destination *volsyncv1alpha1.ReplicationDestination, privileged bool) (mover.Mover, error) {
+	// Only build if the CR belongs to us
+	if destination.Spec.Syncthing == nil {
+		return nil, nil
+	}
+
+	// Create ReplicationDestinationSyncthingStatus to write syncthing status
+	if destination.Status.Syncthing == nil {
+		destination.Status.Syncthing = &volsyncv1alpha1.ReplicationDestinationSyncthingStatus{}
+	}
+
+	// servicetype or default
+	var serviceType corev1.ServiceType
+	if destination.Spec.Syncthing.ServiceType != nil {
+		serviceType = *destination.Spec.Syncthing.ServiceType
+	} else {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+
+	vh, err := volumehandler.NewVolumeHandler(
+		volumehandler.WithClient(client),
+		volumehandler.WithRecorder(eventRecorder),
+		volumehandler.WithOwner(destination),
+		volumehandler.FromDestination(&destination.Spec.Syncthing.ReplicationDestinationVolumeOptions),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	saHandler := utils.NewSAHandler(client, destination, false, privileged,
+		destination.Spec.Syncthing.MoverServiceAccount)
+
+	syncthingLogger := logger.WithValues("method", "Syncthing")
+
+	// Without a trigger, each sync waits for new data to arrive rather than
+	// completing again right away
+	trigger := destination.Spec.Trigger
+	waitForChanges := trigger == nil || ((trigger.Schedule == nil || *trigger.Schedule == "") && trigger.Manual == "")
+
+	return &Mover{
+		client:               client,
+		logger:               syncthingLogger,
+		owner:                destination,
+		saHandler:            saHandler,
+		eventRecorder:        eventRecorder,
+		configCapacity:       destination.Spec.Syncthing.ConfigCapacity,
+		configStorageClass:   destination.Spec.Syncthing.ConfigStorageClassName,
+		configAccessModes:    destination.Spec.Syncthing.ConfigAccessModes,
+		containerImage:       rb.getSyncthingContainerImage(),
+		peerList:             destination.Spec.Syncthing.Peers,
+		connectivity:         destination.Spec.Syncthing.SyncthingConnectivityOptions,
+		paused:               destination.Spec.Paused,
+		dataPVCName:          destination.Spec.Syncthing.DestinationPVC,
+		destStatus:           destination.Status.Syncthing,
+		serviceType:          serviceType,
+		syncthingConnection:  nil,
+		apiConfig:            api.APIConfig{},
+		privileged:           privileged,
+		moverSecurityContext: destination.Spec.Syncthing.MoverSecurityContext,
+		podOptions:           destination.Spec.Syncthing.MoverPodOptions,
+		isSource:             false,
+		vh:                   vh,
+		folderType:           config.FolderTypeReceiveOnly,
+		waitForChanges:       waitForChanges,
+	}, nil
 }
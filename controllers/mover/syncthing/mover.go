@@ -19,13 +19,18 @@ package syncthing
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/syncthing/syncthing/lib/config"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -82,6 +87,9 @@ const (
 	apiKeyDataKey    = "apikey"
 	usernameDataKey  = "username"
 	passwordDataKey  = "password"
+
+	// the certificate replaced by the latest renewal, trusted until Syncthing has restarted with the new one
+	httpsPreviousCertDataKey = "httpsPreviousCertPEM"
 )
 
 // Filepaths for where the HTTPS certificate and key will be
@@ -97,6 +105,18 @@ const (
 	configCapacity = "1Gi"
 	// resourcePrefix Prefixes every name for resources created by the VolSync controller.
 	resourcePrefix = "volsync-"
+	// folderStateIdle Is the state of a Syncthing folder which is neither scanning nor syncing.
+	folderStateIdle = "idle"
+	// apiCertRenewBefore Is how long before its expiry the certificate of the Syncthing API is replaced.
+	apiCertRenewBefore = 30 * 24 * time.Hour
+	// apiCertHashAnnotation Holds a hash of the API certificate in the Deployment's pod template,
+	// so that Syncthing is restarted with the new certificate once it has been renewed.
+	apiCertHashAnnotation = utils.VolsyncLabelPrefix + "/api-cert-hash"
+	// maxReportedFolderFiles Limits how many of the files with errors or conflicts are listed in the status.
+	maxReportedFolderFiles = 10
+	// peerNamespacesAnnotation Lists the other namespaces (comma-separated) whose ReplicationSources may
+	// discover this ReplicationSource as a peer.
+	peerNamespacesAnnotation = utils.VolsyncLabelPrefix + "/syncthing-peer-namespaces"
 )
 
 // Mover is the reconciliation logic for the Restic-based data mover.
@@ -113,12 +133,23 @@ type Mover struct {
 	paused               bool
 	dataPVCName          *string
 	peerList             []volsyncv1alpha1.SyncthingPeer
+	peerSelector         *metav1.LabelSelector
+	peerNSSelector       *metav1.LabelSelector
+	connectivity         volsyncv1alpha1.SyncthingConnectivityOptions
 	status               *volsyncv1alpha1.ReplicationSourceSyncthingStatus
+	destStatus           *volsyncv1alpha1.ReplicationDestinationSyncthingStatus
+	isSource             bool
+	vh                   *volumehandler.VolumeHandler
+	folderType           config.FolderType
+	versioning           *volsyncv1alpha1.SyncthingVersioningSpec
+	ignorePatterns       []string
+	waitForChanges       bool
 	serviceType          corev1.ServiceType
 	syncthingConnection  api.SyncthingConnection
 	apiConfig            api.APIConfig
 	privileged           bool
 	moverSecurityContext *corev1.PodSecurityContext
+	podOptions           volsyncv1alpha1.MoverPodOptions
 }
 
 var _ mover.Mover = &Mover{}
@@ -146,93 +177,160 @@ func (m *Mover) Name() string { return "syncthing" }
 // Synchronize also updates the ReplicationSource's status
 // with information about our local Syncthing instance, as well
 // as any connections that have been made to the Syncthing instance.
+//
+// For a ReplicationDestination, Synchronize completes with an image
+// of the volume once its folder is idle and in sync with the peers.
 func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
-	dataService, secretAPIKey, err := m.ensureNecessaryResources(ctx)
-	if err != nil {
+	dataPVC, dataService, secretAPIKey, err := m.ensureNecessaryResources(ctx)
+	if dataService == nil || err != nil {
+		return mover.InProgress(), err
+	}
+	if err = m.ensurePeersAreDiscovered(ctx); err != nil {
 		return mover.InProgress(), err
 	}
-	if err = m.interactWithSyncthing(dataService, secretAPIKey); err != nil {
+	syncthingState, err := m.interactWithSyncthing(dataService, secretAPIKey)
+	if err != nil {
 		return mover.InProgress(), err
 	}
 	var retryAfter = 20 * time.Second
+	if !m.isSource {
+		return m.ensureSyncedImage(ctx, dataPVC, syncthingState, retryAfter)
+	}
 	return mover.RetryAfter(retryAfter), nil
 }
 
 // ensureNecessaryResources Creates the resources required for VolSync to operate the Syncthing mover,
-// and returns references to the data service exposing the Syncthing connection along with
-// the secret where necessary credentials are stored.
+// and returns references to the data PVC and the data service exposing the Syncthing connection
+// along with the secret where necessary credentials are stored.
 // If VolSync is unable to ensure the necessary resources, an error is returned.
-func (m *Mover) ensureNecessaryResources(ctx context.Context) (*corev1.Service, *corev1.Secret, error) {
+func (m *Mover) ensureNecessaryResources(ctx context.Context) (*corev1.PersistentVolumeClaim,
+	*corev1.Service, *corev1.Secret, error) {
 	var err error
 	dataPVC, err := m.ensureDataPVC(ctx)
 	if dataPVC == nil || err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	configPVC, err := m.ensureConfigPVC(ctx, dataPVC)
 	if configPVC == nil || err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	secretAPIKey, err := m.ensureSecretAPIKey(ctx)
 	if secretAPIKey == nil || err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	sa, err := m.saHandler.Reconcile(ctx, m.logger)
 	if sa == nil || err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	deployment, err := m.ensureDeployment(ctx, dataPVC, configPVC, sa, secretAPIKey)
 	if deployment == nil || err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	APIService, err := m.ensureAPIService(ctx, deployment)
 	if APIService == nil || err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	dataService, err := m.ensureDataService(ctx, deployment)
 	if dataService == nil || err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	return dataService, secretAPIKey, nil
+	return dataPVC, dataService, secretAPIKey, nil
 }
 
 // interactWithSyncthing Updates the Syncthing instance with the required connections as defined by VolSync,
 // and sets the status of the ReplicationSource to reflect the current state of the Syncthing instance.
-// An error is returned when it is unable to do so.
-func (m *Mover) interactWithSyncthing(dataService *corev1.Service, apiSecret *corev1.Secret) error {
+// The latest state of the Syncthing instance is returned, or an error when it is unable to do so.
+func (m *Mover) interactWithSyncthing(dataService *corev1.Service, apiSecret *corev1.Secret) (*api.Syncthing, error) {
 	// get the API key from the secret
 	var err error
 	if err = m.validatePeerList(); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err = m.configureSyncthingAPIClient(apiSecret); err != nil {
-		return err
+		return nil, err
 	}
 
 	// fetch the latest data from Syncthing
 	syncthingState, err := m.syncthingConnection.Fetch()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// configure syncthing before grabbing info & updating status
 	if err = m.ensureIsConfigured(apiSecret, syncthingState); err != nil {
-		return err
+		return nil, err
 	}
 
 	// obtain the latest state
 	if syncthingState, err = m.syncthingConnection.Fetch(); err != nil {
-		return err
+		return nil, err
+	}
+
+	if err = m.ensureStatusIsUpdated(dataService, syncthingState); err != nil {
+		return nil, err
+	}
+	return syncthingState, nil
+}
+
+// ensureSyncedImage Returns a completed result with an image of the data PVC once the
+// Syncthing folder has finished syncing with the peers, or a result to retry later otherwise.
+func (m *Mover) ensureSyncedImage(ctx context.Context, dataPVC *corev1.PersistentVolumeClaim,
+	syncthing *api.Syncthing, retryAfter time.Duration) (mover.Result, error) {
+	folder, ok := syncthing.GetFolderFromPath(dataDirMountPath)
+	if !ok {
+		return mover.InProgress(), fmt.Errorf("could not find the folder syncing %s", dataDirMountPath)
+	}
+	folderStatus := syncthing.FolderStatuses[folder.ID]
+	if !m.syncCompleted(syncthing, folderStatus) {
+		return mover.RetryAfter(retryAfter), nil
+	}
+
+	image, err := m.vh.EnsureImage(ctx, m.logger, dataPVC)
+	if image == nil || err != nil {
+		return mover.InProgress(), err
+	}
+	m.logger.Info("folder is in sync", "folder", folder.ID, "sequence", folderStatus.Sequence)
+	m.destStatus.LastSyncedSequence = ptr.To(folderStatus.Sequence)
+	return mover.CompleteWithImage(image), nil
+}
+
+// syncCompleted Determines whether the given folder is idle and has everything the connected peers
+// have to offer. Nothing counts as synced until some data has been received, and when there is no
+// trigger the folder must also have changed since the last completed sync.
+func (m *Mover) syncCompleted(syncthing *api.Syncthing, folderStatus api.FolderStatus) bool {
+	if folderStatus.State != folderStateIdle || folderStatus.NeedTotalItems > 0 {
+		return false
+	}
+
+	// there is nothing to be in sync with until a peer is connected
+	connected := false
+	for deviceID, connectionInfo := range syncthing.SystemConnections.Connections {
+		if deviceID != syncthing.MyID() && connectionInfo.Connected {
+			connected = true
+			break
+		}
+	}
+	if !connected {
+		return false
 	}
 
-	return m.ensureStatusIsUpdated(dataService, syncthingState)
+	lastSynced := m.destStatus.LastSyncedSequence
+	switch {
+	case lastSynced == nil:
+		return folderStatus.Sequence > 0
+	case m.waitForChanges:
+		return folderStatus.Sequence != *lastSynced
+	default:
+		return true
+	}
 }
 
 // ensureConfigPVC Ensures that there is a PVC persisting Syncthing's config data.
@@ -285,6 +383,10 @@ func (m *Mover) ensureConfigPVC(
 // ensureDataPVC Ensures that the PVC holding the data meant to be synced is available.
 // A VolumeHandler will be created based on the provided source PVC.
 func (m *Mover) ensureDataPVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	if !m.isSource {
+		return m.ensureDestinationPVC(ctx)
+	}
+
 	// check if the data PVC exists, error if it doesn't
 	dataPVC := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -299,6 +401,23 @@ func (m *Mover) ensureDataPVC(ctx context.Context) (*corev1.PersistentVolumeClai
 	return dataPVC, nil
 }
 
+// ensureDestinationPVC Ensures that the PVC receiving the synced data is available, using either
+// the PVC provided in the ReplicationDestination or one allocated by the VolumeHandler.
+func (m *Mover) ensureDestinationPVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	if m.dataPVCName != nil {
+		return m.vh.UseProvidedPVC(ctx, *m.dataPVCName)
+	}
+	return m.vh.EnsureNewDataPVC(ctx, m.logger, m.getDestinationPVCName())
+}
+
+// getDestinationPVCName Returns the name of the PVC receiving the synced data.
+func (m *Mover) getDestinationPVCName() string {
+	if m.dataPVCName != nil {
+		return *m.dataPVCName
+	}
+	return resourcePrefix + m.owner.GetName() + "-dst"
+}
+
 // serviceSelector Returns a mapping of standardized Kubernetes labels.
 func (m *Mover) serviceSelector() map[string]string {
 	return map[string]string{
@@ -321,7 +440,7 @@ func (m *Mover) ensureSecretAPIKey(ctx context.Context) (*corev1.Secret, error)
 
 	// make sure we don't need to do extra work
 	if err == nil {
-		return secret, nil
+		return m.ensureAPICertificateIsValid(ctx, secret)
 	} else if !errors.IsNotFound(err) {
 		return nil, err
 	}
@@ -365,9 +484,105 @@ func (m *Mover) ensureSecretAPIKey(ctx context.Context) (*corev1.Secret, error)
 		return nil, err
 	}
 	m.logger.Info("created secret", secret.Name, secret)
+	return m.ensureAPICertificateIsValid(ctx, secret)
+}
+
+// ensureAPICertificateIsValid Replaces the TLS certificate of the Syncthing API in the given secret
+// when it's about to expire, or when it can't be used for the API service, and records its expiry
+// in the status. The rest of the secret is kept, and the identity of the Syncthing device is not
+// affected since its own certificate is stored in the config volume.
+func (m *Mover) ensureAPICertificateIsValid(ctx context.Context, secret *corev1.Secret) (*corev1.Secret, error) {
+	apiServiceDNS := m.getAPIServiceDNS()
+	cert, err := parseCertificatePEM(secret.Data[httpsCertDataKey])
+	if err == nil && !certificateNeedsRenewal(cert, apiServiceDNS, time.Now(), apiCertRenewBefore) {
+		m.setAPICertificateExpiry(cert.NotAfter)
+		return m.ensurePreviousAPICertificateIsDropped(ctx, secret)
+	}
+
+	m.logger.Info("renewing TLS certificates", "DNS", apiServiceDNS)
+	certPEM, certPrivKeyPEM, err := generateTLSCertificatesForSyncthing(apiServiceDNS)
+	if err != nil {
+		return nil, err
+	}
+	if cert, err = parseCertificatePEM(certPEM.Bytes()); err != nil {
+		return nil, err
+	}
+
+	secret.Data[httpsPreviousCertDataKey] = secret.Data[httpsCertDataKey]
+	secret.Data[httpsCertDataKey] = certPEM.Bytes()
+	secret.Data[httpsKeyDataKey] = certPrivKeyPEM.Bytes()
+	if err := m.client.Update(ctx, secret); err != nil {
+		return nil, err
+	}
+	m.eventRecorder.Eventf(m.owner, secret, corev1.EventTypeNormal,
+		volsyncv1alpha1.EvRCertRenewed, volsyncv1alpha1.EvANone,
+		"renewed the Syncthing API certificate, valid until %s", cert.NotAfter.Format(time.RFC3339))
+	m.setAPICertificateExpiry(cert.NotAfter)
+	return secret, nil
+}
+
+// ensurePreviousAPICertificateIsDropped Removes the previous TLS certificate of the Syncthing API
+// from the given secret once it's no longer served, so that it stops being trusted. This is the
+// case once it has expired, or once Syncthing has been restarted with the current certificate.
+func (m *Mover) ensurePreviousAPICertificateIsDropped(ctx context.Context,
+	secret *corev1.Secret) (*corev1.Secret, error) {
+	previousCertPEM, ok := secret.Data[httpsPreviousCertDataKey]
+	if !ok {
+		return secret, nil
+	}
+	previousCert, err := parseCertificatePEM(previousCertPEM)
+	if err == nil && time.Now().Before(previousCert.NotAfter) {
+		rolledOver, err := m.apiCertificateIsRolledOut(ctx, secret.Data[httpsCertDataKey])
+		if !rolledOver || err != nil {
+			return secret, err
+		}
+	}
+
+	m.logger.Info("dropping the previous TLS certificate")
+	delete(secret.Data, httpsPreviousCertDataKey)
+	if err := m.client.Update(ctx, secret); err != nil {
+		return nil, err
+	}
 	return secret, nil
 }
 
+// apiCertificateIsRolledOut Determines whether every Syncthing pod has been restarted with the
+// given API certificate, or whether there is no Syncthing pod at all.
+func (m *Mover) apiCertificateIsRolledOut(ctx context.Context, certPEM []byte) (bool, error) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourcePrefix + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	err := m.client.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)
+	if errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	status := deployment.Status
+	return deployment.Spec.Template.Annotations[apiCertHashAnnotation] == apiCertificateHash(certPEM) &&
+		status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas == status.Replicas &&
+		status.AvailableReplicas == status.Replicas, nil
+}
+
+// apiCertificateHash Returns the hash of the API certificate recorded in the Deployment's pod template.
+func apiCertificateHash(certPEM []byte) string {
+	certHash := sha256.Sum256(certPEM)
+	return hex.EncodeToString(certHash[:8])
+}
+
+// setAPICertificateExpiry Records the expiry of the API certificate in the status.
+func (m *Mover) setAPICertificateExpiry(expiry time.Time) {
+	if m.isSource {
+		m.status.APICertificateExpiry = &metav1.Time{Time: expiry}
+	} else {
+		m.destStatus.APICertificateExpiry = &metav1.Time{Time: expiry}
+	}
+}
+
 // ensureDeployment Will ensure that a Deployment for the Syncthing mover exists, or it will be created.
 //
 //nolint:funlen
@@ -417,11 +632,17 @@ func (m *Mover) ensureDeployment(ctx context.Context, dataPVC *corev1.Persistent
 		utils.SetOwnedByVolSync(&deployment.Spec.Template)
 		deployment.Spec.Template.ObjectMeta.Name = deployment.Name
 		utils.AddAllLabels(&deployment.Spec.Template, m.serviceSelector())
+		// restart Syncthing whenever the API certificate is renewed
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+		deployment.Spec.Template.Annotations[apiCertHashAnnotation] = apiCertificateHash(apiSecret.Data[httpsCertDataKey])
 
 		podSpec := &deployment.Spec.Template.Spec
 
 		podSpec.NodeSelector = affinity.NodeSelector
 		podSpec.Tolerations = affinity.Tolerations
+		podSpec.Affinity = affinity.Affinity
 
 		podSpec.ServiceAccountName = sa.Name
 		podSpec.RestartPolicy = corev1.RestartPolicyAlways
@@ -534,6 +755,7 @@ func (m *Mover) ensureDeployment(ctx context.Context, dataPVC *corev1.Persistent
 			})
 		}
 
+		utils.ApplyMoverPodOptions(&deployment.Spec.Template, &m.podOptions)
 		return nil
 	})
 
@@ -638,8 +860,15 @@ func (m *Mover) GetDataServiceAddress(service *corev1.Service) (string, error) {
 }
 
 // Cleanup will remove any resources that were created by the mover.
-// This is currently a no-op since Syncthing is always-on.
+// Syncthing is always-on, so for a ReplicationDestination this only
+// allows the next sync to take a new snapshot of the data PVC.
 func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
+	if !m.isSource {
+		err := m.vh.RemoveSnapshotAnnotationFromPVC(ctx, m.logger, m.getDestinationPVCName())
+		if err != nil {
+			return mover.InProgress(), err
+		}
+	}
 	err := utils.CleanupObjects(ctx, m.client, m.logger, m.owner, []client.Object{})
 	if err != nil {
 		return mover.InProgress(), err
@@ -676,6 +905,98 @@ func (m *Mover) configureSyncthingAPIClient(
 	return err
 }
 
+// ensurePeersAreDiscovered Adds the Syncthing ReplicationSources matching the peer selectors to the
+// peerList. The peers listed in the spec take precedence over the discovered ones, and the
+// ReplicationSources that haven't reported their Syncthing ID and address yet are skipped.
+func (m *Mover) ensurePeersAreDiscovered(ctx context.Context) error {
+	if m.peerSelector == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(m.peerSelector)
+	if err != nil {
+		return err
+	}
+	namespaces, err := m.getPeerNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	peers := append([]volsyncv1alpha1.SyncthingPeer{}, m.peerList...)
+	knownPeers := make(map[string]bool)
+	for _, peer := range peers {
+		knownPeers[peer.ID] = true
+	}
+	for _, namespace := range namespaces {
+		rsList := &volsyncv1alpha1.ReplicationSourceList{}
+		listOptions := []client.ListOption{
+			client.MatchingLabelsSelector{
+				Selector: selector,
+			},
+			client.InNamespace(namespace),
+		}
+		if err = m.client.List(ctx, rsList, listOptions...); err != nil {
+			return err
+		}
+		for _, rs := range rsList.Items {
+			if rs.GetUID() == m.owner.GetUID() || rs.Spec.Syncthing == nil ||
+				rs.Status == nil || rs.Status.Syncthing == nil {
+				continue
+			}
+			id, address := rs.Status.Syncthing.ID, rs.Status.Syncthing.Address
+			if id == "" || address == "" || knownPeers[id] {
+				continue
+			}
+			if !allowsPeersFrom(&rs, m.owner.GetNamespace()) {
+				m.logger.V(1).Info("peer doesn't allow discovery from this namespace",
+					"replicationsource", client.ObjectKeyFromObject(&rs))
+				continue
+			}
+			m.logger.V(1).Info("discovered peer", "replicationsource", client.ObjectKeyFromObject(&rs), "ID", id)
+			peers = append(peers, volsyncv1alpha1.SyncthingPeer{ID: id, Address: address})
+			knownPeers[id] = true
+		}
+	}
+	m.peerList = peers
+	return nil
+}
+
+// getPeerNamespaces Returns the namespaces in which peers are discovered, which is the namespace of
+// the owner unless a namespace selector is given.
+func (m *Mover) getPeerNamespaces(ctx context.Context) ([]string, error) {
+	if m.peerNSSelector == nil {
+		return []string{m.owner.GetNamespace()}, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(m.peerNSSelector)
+	if err != nil {
+		return nil, err
+	}
+	nsList := &corev1.NamespaceList{}
+	if err = m.client.List(ctx, nsList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	namespaces := []string{}
+	for _, ns := range nsList.Items {
+		namespaces = append(namespaces, ns.GetName())
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// allowsPeersFrom Returns true if the ReplicationSources in namespace may discover rs as a peer. The
+// ReplicationSources in the same namespace always may, those in other namespaces only if rs lists their
+// namespace in its peer namespaces annotation.
+func allowsPeersFrom(rs *volsyncv1alpha1.ReplicationSource, namespace string) bool {
+	if rs.GetNamespace() == namespace {
+		return true
+	}
+	for _, ns := range strings.Split(rs.GetAnnotations()[peerNamespacesAnnotation], ",") {
+		if strings.TrimSpace(ns) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 // validatePeerList Checks to make sure that there are no duplicate entries within the provided peerList,
 // and errors if there are.
 func (m *Mover) validatePeerList() error {
@@ -721,6 +1042,20 @@ func (m *Mover) ensureIsConfigured(apiSecret *corev1.Secret, syncthing *api.Sync
 		hasChanged = true
 	}
 
+	// configure the data folder as described in the spec
+	if folder, ok := syncthing.GetFolderFromPath(dataDirMountPath); ok && m.ensureFolderIsConfigured(folder) {
+		hasChanged = true
+	}
+
+	// apply the discovery, relay and bandwidth settings
+	options := optionsFromSpec(m.connectivity, syncthing.Configuration.Options)
+	if !optionsEqual(options, syncthing.Configuration.Options) {
+		m.logger.Info("setting connectivity options", "globalDiscovery", options.GlobalAnnEnabled,
+			"relays", options.RelaysEnabled)
+		syncthing.Configuration.Options = options
+		hasChanged = true
+	}
+
 	// set the user and password if not already set
 	if syncthing.Configuration.GUI.User != string(apiSecret.Data[usernameDataKey]) ||
 		syncthing.Configuration.GUI.Password == "" {
@@ -741,10 +1076,56 @@ func (m *Mover) ensureIsConfigured(apiSecret *corev1.Secret, syncthing *api.Sync
 			return err
 		}
 	}
+
+	// Syncthing keeps the ignore patterns in the folder itself rather than in its config
+	return m.ensureIgnoresAreConfigured(syncthing)
+}
+
+// ensureFolderIsConfigured Updates the type and versioning of the given folder to match
+// the spec, and returns 'true' if the folder was changed, 'false' otherwise.
+func (m *Mover) ensureFolderIsConfigured(folder *config.FolderConfiguration) bool {
+	hasChanged := false
+
+	// share the data folder in the direction the mover needs
+	if folder.Type != m.folderType {
+		m.logger.Info("setting folder type", "folder", folder.ID, "type", m.folderType)
+		folder.Type = m.folderType
+		hasChanged = true
+	}
+
+	versioning := versioningFromSpec(m.versioning, folder.Versioning)
+	if !versioningEqual(versioning, folder.Versioning) {
+		m.logger.Info("setting folder versioning", "folder", folder.ID, "type", versioning.Type)
+		folder.Versioning = versioning
+		hasChanged = true
+	}
+	return hasChanged
+}
+
+// ensureIgnoresAreConfigured Makes sure that the ignore patterns from the spec are in use by
+// the data folder, and errors if they could not be updated.
+func (m *Mover) ensureIgnoresAreConfigured(syncthing *api.Syncthing) error {
+	folder, ok := syncthing.GetFolderFromPath(dataDirMountPath)
+	if !ok {
+		return nil
+	}
+
+	currentIgnores := syncthing.FolderIgnores[folder.ID]
+	ignores := ignoresWithPatterns(currentIgnores, m.ignorePatterns)
+	if stringSlicesEqual(ignores, currentIgnores) {
+		return nil
+	}
+
+	m.logger.Info("setting folder ignore patterns", "folder", folder.ID)
+	if err := m.syncthingConnection.PublishIgnores(folder.ID, ignores); err != nil {
+		m.logger.Error(err, "error updating syncthing ignore patterns")
+		return err
+	}
 	return nil
 }
 
-// ensureStatusIsUpdated Updates the mover's status to be reported by the ReplicationSource object.
+// ensureStatusIsUpdated Updates the mover's status to be reported by the ReplicationSource
+// or ReplicationDestination object.
 func (m *Mover) ensureStatusIsUpdated(dataSVC *corev1.Service,
 	syncthing *api.Syncthing) error {
 	// fail until we can get the address
@@ -753,14 +1134,103 @@ func (m *Mover) ensureStatusIsUpdated(dataSVC *corev1.Service,
 		return err
 	}
 
+	var previousFolderStatus *volsyncv1alpha1.SyncthingFolderStatus
+	if m.isSource {
+		previousFolderStatus = m.status.Folder
+	} else {
+		previousFolderStatus = m.destStatus.Folder
+	}
+	peers := m.getConnectedPeers(syncthing)
+	folderStatus, err := m.getFolderStatus(syncthing, previousFolderStatus, peers)
+	if err != nil {
+		return err
+	}
+
 	// set syncthing-related info
-	m.status.Address = asTCPAddress(addr)
-	m.status.ID = syncthing.MyID()
-	m.status.Peers = m.getConnectedPeers(syncthing)
+	if m.isSource {
+		m.status.Address = asTCPAddress(addr)
+		m.status.ID = syncthing.MyID()
+		m.status.Peers = peers
+		m.status.Folder = folderStatus
+	} else {
+		m.destStatus.Address = asTCPAddress(addr)
+		m.destStatus.ID = syncthing.MyID()
+		m.destStatus.Peers = peers
+		m.destStatus.Folder = folderStatus
+	}
 
 	return nil
 }
 
+// getFolderStatus Retrieves how far along the data folder is in syncing, and fills in the completion
+// of the given peers the folder is shared with. The folder is only browsed for conflicts when it has
+// changed since the previous status, otherwise the conflicts from the previous status are kept.
+func (m *Mover) getFolderStatus(syncthing *api.Syncthing, previous *volsyncv1alpha1.SyncthingFolderStatus,
+	peers []volsyncv1alpha1.SyncthingPeerStatus) (*volsyncv1alpha1.SyncthingFolderStatus, error) {
+	folder, ok := syncthing.GetFolderFromPath(dataDirMountPath)
+	if !ok {
+		// the folder hasn't been configured yet
+		return nil, nil
+	}
+	folderStatus := syncthing.FolderStatuses[folder.ID]
+
+	completion, err := m.syncthingConnection.FetchFolderCompletion(folder.ID, syncthing.MyID())
+	if err != nil {
+		return nil, err
+	}
+	status := &volsyncv1alpha1.SyncthingFolderStatus{
+		ID:             folder.ID,
+		State:          folderStatus.State,
+		Sequence:       folderStatus.Sequence,
+		Completion:     completionPercent(completion.Completion),
+		OutOfSyncBytes: folderStatus.NeedBytes,
+		OutOfSyncItems: int64(folderStatus.NeedTotalItems),
+	}
+
+	folderErrors, err := m.syncthingConnection.FetchFolderErrors(folder.ID)
+	if err != nil {
+		return nil, err
+	}
+	status.ErrorCount = int32(len(folderErrors))
+	for i := 0; i < len(folderErrors) && i < maxReportedFolderFiles; i++ {
+		status.Errors = append(status.Errors, volsyncv1alpha1.SyncthingFileError{
+			Path:  folderErrors[i].Path,
+			Error: folderErrors[i].Error,
+		})
+	}
+
+	if previous != nil && previous.ID == status.ID && previous.Sequence == status.Sequence {
+		status.ConflictCount = previous.ConflictCount
+		status.Conflicts = previous.Conflicts
+	} else {
+		conflicts, err := m.syncthingConnection.FetchFolderConflicts(folder.ID)
+		if err != nil {
+			return nil, err
+		}
+		status.ConflictCount = int32(len(conflicts))
+		if len(conflicts) > maxReportedFolderFiles {
+			conflicts = conflicts[:maxReportedFolderFiles]
+		}
+		if len(conflicts) > 0 {
+			status.Conflicts = conflicts
+		}
+	}
+
+	// Syncthing only tracks the completion of the devices the folder is shared with
+	for i := range peers {
+		if !folderIsSharedWith(folder, peers[i].ID) {
+			continue
+		}
+		peerCompletion, err := m.syncthingConnection.FetchFolderCompletion(folder.ID, peers[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		peers[i].Completion = ptr.To(completionPercent(peerCompletion.Completion))
+		peers[i].OutOfSyncBytes = ptr.To(peerCompletion.NeedBytes)
+	}
+	return status, nil
+}
+
 // getConnectedPeers Retrieves a list of all the peers connected to our Syncthing instance.
 func (m *Mover) getConnectedPeers(syncthing *api.Syncthing) []volsyncv1alpha1.SyncthingPeerStatus {
 	connectedPeers := []volsyncv1alpha1.SyncthingPeerStatus{}
@@ -835,6 +1305,10 @@ func (m *Mover) loadTLSConfigFromSecret(apiSecret *corev1.Secret) (*tls.Config,
 	// create the CA CertPool
 	caCertPool := x509.NewCertPool()
 	caCertPool.AppendCertsFromPEM(serverCert)
+	// Syncthing keeps serving the previous certificate until it has restarted after a renewal
+	if previousCert, ok := apiSecret.Data[httpsPreviousCertDataKey]; ok {
+		caCertPool.AppendCertsFromPEM(previousCert)
+	}
 
 	// create the TLS config
 	conf := &tls.Config{
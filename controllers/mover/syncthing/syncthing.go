@@ -19,12 +19,15 @@ package syncthing
 import (
 	"crypto/rand"
 	"fmt"
+	"math"
 	"regexp"
+	"strconv"
 
 	"github.com/backube/volsync/api/v1alpha1"
 	"github.com/backube/volsync/controllers/mover/syncthing/api"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/protocol"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // updateSyncthingDevices Updates the Syncthing's connected devices with the provided peerList.
@@ -118,6 +121,189 @@ func syncthingNeedsReconfigure(
 	return false
 }
 
+// Lines surrounding the ignore patterns from the spec within a folder's .stignore file.
+const (
+	ignorePatternsBegin = "// Begin of the ignorePatterns managed by VolSync"
+	ignorePatternsEnd   = "// End of the ignorePatterns managed by VolSync"
+)
+
+// Defaults for the versioning parameters that aren't set in the spec.
+const (
+	defaultVersioningKeep = 5
+	secondsPerDay         = 24 * 60 * 60
+)
+
+// Addresses used in the options of the Syncthing configuration.
+const (
+	// dataListenAddress Is where Syncthing accepts the connections of the peers, as in the config template.
+	dataListenAddress = "tcp://0.0.0.0:22000"
+	// publicRelayPool Is the address Syncthing listens on to be reachable through the public relays.
+	publicRelayPool = "dynamic+https://relays.syncthing.net/endpoint"
+	// publicDiscoveryServers Stands for Syncthing's public global discovery servers.
+	publicDiscoveryServers = "default"
+)
+
+// folderTypeFromSpec Converts the folder type from the spec into the Syncthing folder type,
+// defaulting to sendreceive. An error is returned for unsupported folder types.
+func folderTypeFromSpec(folderType *v1alpha1.SyncthingFolderType) (config.FolderType, error) {
+	if folderType == nil {
+		return config.FolderTypeSendReceive, nil
+	}
+	switch *folderType {
+	case v1alpha1.SyncthingFolderTypeSendReceive:
+		return config.FolderTypeSendReceive, nil
+	case v1alpha1.SyncthingFolderTypeSendOnly:
+		return config.FolderTypeSendOnly, nil
+	case v1alpha1.SyncthingFolderTypeReceiveOnly:
+		return config.FolderTypeReceiveOnly, nil
+	}
+	return config.FolderTypeSendReceive, fmt.Errorf("unsupported folder type: %s", *folderType)
+}
+
+// versioningFromSpec Returns the Syncthing versioning configuration described by the given spec,
+// keeping the settings from the current configuration which can't be set in the spec.
+// Versioning is disabled when the spec is nil.
+func versioningFromSpec(spec *v1alpha1.SyncthingVersioningSpec,
+	current config.VersioningConfiguration) config.VersioningConfiguration {
+	versioning := current
+	versioning.Type = ""
+	versioning.Params = map[string]string{}
+	if spec == nil {
+		return versioning
+	}
+
+	var retentionDays int32
+	if spec.RetentionDays != nil {
+		retentionDays = *spec.RetentionDays
+	}
+	versioning.Type = string(spec.Type)
+	switch spec.Type {
+	case v1alpha1.SyncthingVersioningSimple:
+		var keep int32 = defaultVersioningKeep
+		if spec.Keep != nil {
+			keep = *spec.Keep
+		}
+		versioning.Params["keep"] = strconv.Itoa(int(keep))
+		versioning.Params["cleanoutDays"] = strconv.Itoa(int(retentionDays))
+	case v1alpha1.SyncthingVersioningTrashcan:
+		versioning.Params["cleanoutDays"] = strconv.Itoa(int(retentionDays))
+	case v1alpha1.SyncthingVersioningStaggered:
+		// the maximum age is in seconds, 0 keeps versions forever like the others
+		versioning.Params["maxAge"] = strconv.Itoa(int(retentionDays) * secondsPerDay)
+	}
+	return versioning
+}
+
+// versioningEqual Determines whether two versioning configurations use the same strategy
+// with the same parameters.
+func versioningEqual(a, b config.VersioningConfiguration) bool {
+	if a.Type != b.Type || len(a.Params) != len(b.Params) {
+		return false
+	}
+	for key, value := range a.Params {
+		if bValue, ok := b.Params[key]; !ok || bValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ignoresWithPatterns Returns the given lines of a .stignore file with the ignore patterns
+// from the spec placed first, replacing the ones which were previously added by VolSync.
+// Syncthing uses the first pattern matching a file, so the spec takes precedence
+// over the rest of the file.
+func ignoresWithPatterns(lines []string, patterns []string) []string {
+	ignores := []string{}
+	if len(patterns) > 0 {
+		ignores = append(ignores, ignorePatternsBegin)
+		ignores = append(ignores, patterns...)
+		ignores = append(ignores, ignorePatternsEnd)
+	}
+
+	// keep the lines which weren't added by VolSync
+	managed := false
+	for _, line := range lines {
+		switch {
+		case line == ignorePatternsBegin:
+			managed = true
+		case line == ignorePatternsEnd:
+			managed = false
+		case !managed:
+			ignores = append(ignores, line)
+		}
+	}
+	return ignores
+}
+
+// optionsFromSpec Returns the given Syncthing options with the discovery, relay and bandwidth
+// settings from the spec applied. Global discovery and relays are disabled unless enabled in the spec.
+func optionsFromSpec(spec v1alpha1.SyncthingConnectivityOptions,
+	current config.OptionsConfiguration) config.OptionsConfiguration {
+	options := current
+
+	options.GlobalAnnEnabled = spec.GlobalDiscovery != nil && spec.GlobalDiscovery.Enabled
+	if options.GlobalAnnEnabled {
+		options.RawGlobalAnnServers = []string{publicDiscoveryServers}
+		if len(spec.GlobalDiscovery.Servers) > 0 {
+			options.RawGlobalAnnServers = append([]string{}, spec.GlobalDiscovery.Servers...)
+		}
+	}
+
+	// Syncthing uses the relays it listens on
+	options.RelaysEnabled = spec.Relays != nil && spec.Relays.Enabled
+	options.RawListenAddresses = []string{dataListenAddress}
+	if options.RelaysEnabled {
+		if len(spec.Relays.Servers) > 0 {
+			options.RawListenAddresses = append(options.RawListenAddresses, spec.Relays.Servers...)
+		} else {
+			options.RawListenAddresses = append(options.RawListenAddresses, publicRelayPool)
+		}
+	}
+
+	options.MaxSendKbps, options.MaxRecvKbps = 0, 0
+	if spec.Bandwidth != nil {
+		options.MaxSendKbps = bandwidthLimitKiB(spec.Bandwidth.SendLimit)
+		options.MaxRecvKbps = bandwidthLimitKiB(spec.Bandwidth.ReceiveLimit)
+	}
+	// the peers are usually on private networks, where Syncthing doesn't limit the rate otherwise
+	options.LimitBandwidthInLan = options.MaxSendKbps > 0 || options.MaxRecvKbps > 0
+	return options
+}
+
+// optionsEqual Determines whether two sets of Syncthing options have the same discovery,
+// relay and bandwidth settings.
+func optionsEqual(a, b config.OptionsConfiguration) bool {
+	return a.GlobalAnnEnabled == b.GlobalAnnEnabled &&
+		(!a.GlobalAnnEnabled || stringSlicesEqual(a.RawGlobalAnnServers, b.RawGlobalAnnServers)) &&
+		a.RelaysEnabled == b.RelaysEnabled &&
+		stringSlicesEqual(a.RawListenAddresses, b.RawListenAddresses) &&
+		a.MaxSendKbps == b.MaxSendKbps &&
+		a.MaxRecvKbps == b.MaxRecvKbps &&
+		a.LimitBandwidthInLan == b.LimitBandwidthInLan
+}
+
+// bandwidthLimitKiB Converts a rate in bytes per second into the KiB per second used by Syncthing.
+// The rate is rounded up so that a small limit never becomes unlimited.
+func bandwidthLimitKiB(limit *resource.Quantity) int {
+	if limit == nil {
+		return 0
+	}
+	return int((limit.Value() + 1023) / 1024)
+}
+
+// stringSlicesEqual Determines whether both slices contain the same strings in the same order.
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // GenerateRandomBytes Generates random bytes of the given length using the OS's RNG.
 func GenerateRandomBytes(length int) ([]byte, error) {
 	// generates random bytes of given length
@@ -168,3 +354,19 @@ func asTCPAddress(address string) string {
 
 	return "tcp://" + address
 }
+
+// completionPercent Converts the completion reported by Syncthing to a whole percentage,
+// rounded down so that a folder is only reported as complete once it is fully in sync.
+func completionPercent(completion float64) int32 {
+	return int32(math.Floor(completion))
+}
+
+// folderIsSharedWith Returns whether the given folder is shared with the device with the given ID.
+func folderIsSharedWith(folder *config.FolderConfiguration, deviceID string) bool {
+	for _, device := range folder.Devices {
+		if device.DeviceID.GoString() == deviceID {
+			return true
+		}
+	}
+	return false
+}
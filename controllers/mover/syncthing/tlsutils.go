@@ -120,6 +120,22 @@ func getCertificatePEMs(certBytes []byte, certPrivKey *rsa.PrivateKey) (*bytes.B
 	return certPEM, certPrivKeyPEM, nil
 }
 
+// parseCertificatePEM Returns the first certificate of the given PEM data.
+func parseCertificatePEM(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("could not find a certificate in the PEM data")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// certificateNeedsRenewal Determines whether the given certificate must be replaced, either
+// because it expires within renewBefore of now or because it isn't valid for the given DNS name.
+func certificateNeedsRenewal(cert *x509.Certificate, DNSName string, now time.Time,
+	renewBefore time.Duration) bool {
+	return now.Add(renewBefore).After(cert.NotAfter) || cert.VerifyHostname(DNSName) != nil
+}
+
 // generateTLSCertificatesForSyncthing generates a self-signed PEM-encoded certificate and key for Syncthing
 // which the VolSync client and Syncthing API Server will use to communicate with each other.
 func generateTLSCertificatesForSyncthing(
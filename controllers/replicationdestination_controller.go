@@ -90,13 +90,18 @@ func (r *ReplicationDestinationReconciler) Reconcile(ctx context.Context, req ct
 		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	// Keep what the deletion policy retains from being garbage collected
+	deleted, err := reconcileDeletionPolicy(ctx, r.Client, logger, inst, inst.Spec.DeletionPolicy,
+		latestImageName(inst), previousImageNames(inst))
+	if deleted || err != nil {
+		return ctrl.Result{}, err
+	}
 	// Prepare the .Status fields if necessary
 	if inst.Status == nil {
 		inst.Status = &volsyncv1alpha1.ReplicationDestinationStatus{}
 	}
 
 	var result ctrl.Result
-	var err error
 
 	// Check if any volume snapshots are marked with do-not-delete label and remove ownership if so
 	err = utils.RelinquishOwnedSnapshotsWithDoNotDeleteLabel(ctx, r.Client, logger, inst)
@@ -212,6 +217,13 @@ func (m *rdMachine) SetLastManualTag(tag string) {
 	m.rd.Status.LastManualSync = tag
 }
 
+func (m *rdMachine) VerifyRequested() bool {
+	if v, ok := m.mover.(mover.Verifier); ok {
+		return v.VerifyRequested()
+	}
+	return false
+}
+
 func (m *rdMachine) NextSyncTime() *metav1.Time {
 	return m.rd.Status.NextSyncTime
 }
@@ -268,9 +280,8 @@ func (m *rdMachine) Synchronize(ctx context.Context) (mover.Result, error) {
 	result, err := m.mover.Synchronize(ctx)
 
 	if result.Completed && result.Image != nil {
-		// Mark previous latestImage for cleanup if it was a snapshot
-		err = utils.MarkOldSnapshotForCleanup(ctx, m.client, m.logger, m.rd,
-			m.rd.Status.LatestImage, result.Image)
+		// Mark previous images for cleanup if they are snapshots that are not retained
+		err = m.retainImages(ctx, result.Image)
 		if err != nil {
 			return mover.InProgress(), err
 		}
@@ -278,9 +289,83 @@ func (m *rdMachine) Synchronize(ctx context.Context) (mover.Result, error) {
 		m.rd.Status.LatestImage = result.Image
 	}
 
+	if m.rd.Status.Syncthing != nil {
+		m.metrics.SetSyncthingFolder(m.rd.Status.Syncthing.Folder)
+	}
+
 	return result, err
 }
 
+// retainImages records the new image in the retained images and marks the
+// snapshots that are no longer selected by the retain policy for cleanup.
+// Without a policy, only the latest image is kept.
+func (m *rdMachine) retainImages(ctx context.Context, image *corev1.TypedLocalObjectReference) error {
+	policy := destinationRetainPolicy(m.rd)
+	if policy == nil || !utils.IsSnapshot(image) {
+		// Also clean up the images that were retained before the policy was removed
+		for i := range m.rd.Status.RetainedImages {
+			err := utils.MarkOldSnapshotForCleanup(ctx, m.client, m.logger, m.rd,
+				&m.rd.Status.RetainedImages[i].Image, image)
+			if err != nil {
+				return err
+			}
+		}
+		m.rd.Status.RetainedImages = nil
+		return utils.MarkOldSnapshotForCleanup(ctx, m.client, m.logger, m.rd,
+			m.rd.Status.LatestImage, image)
+	}
+
+	images := m.rd.Status.RetainedImages
+	if len(images) == 0 && utils.IsSnapshot(m.rd.Status.LatestImage) {
+		// The policy was just set, so start with the image of the previous sync
+		created := metav1.Now()
+		if m.rd.Status.LastSyncTime != nil {
+			created = *m.rd.Status.LastSyncTime
+		}
+		images = append(images, volsyncv1alpha1.RetainedImage{
+			Image:        *m.rd.Status.LatestImage,
+			CreationTime: created,
+		})
+	}
+	if len(images) == 0 || images[0].Image.Name != image.Name {
+		images = append([]volsyncv1alpha1.RetainedImage{{
+			Image:        *image,
+			CreationTime: metav1.Now(),
+		}}, images...)
+	}
+
+	retained, err := utils.MarkExpiredSnapshotsForCleanup(ctx, m.client, m.logger, m.rd, images, policy)
+	if err != nil {
+		return err
+	}
+	m.rd.Status.RetainedImages = retained
+	return nil
+}
+
+// destinationRetainPolicy returns the retain policy from the volume options of
+// the mover used by the ReplicationDestination, if any.
+func destinationRetainPolicy(rd *volsyncv1alpha1.ReplicationDestination) *volsyncv1alpha1.SnapshotRetainPolicy {
+	var options *volsyncv1alpha1.ReplicationDestinationVolumeOptions
+	switch {
+	case rd.Spec.Rsync != nil:
+		options = &rd.Spec.Rsync.ReplicationDestinationVolumeOptions
+	case rd.Spec.RsyncTLS != nil:
+		options = &rd.Spec.RsyncTLS.ReplicationDestinationVolumeOptions
+	case rd.Spec.Rclone != nil:
+		options = &rd.Spec.Rclone.ReplicationDestinationVolumeOptions
+	case rd.Spec.Restic != nil:
+		options = &rd.Spec.Restic.ReplicationDestinationVolumeOptions
+	case rd.Spec.Syncthing != nil:
+		options = &rd.Spec.Syncthing.ReplicationDestinationVolumeOptions
+	default:
+		return nil
+	}
+	if options.CopyMethod != volsyncv1alpha1.CopyMethodSnapshot {
+		return nil
+	}
+	return options.Retain
+}
+
 func (m *rdMachine) Cleanup(ctx context.Context) (mover.Result, error) {
 	return m.mover.Cleanup(ctx)
 }
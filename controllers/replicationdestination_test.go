@@ -16,9 +16,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
 )
 
 var _ = Describe("ReplicationDestination", func() {
@@ -66,6 +68,144 @@ var _ = Describe("ReplicationDestination", func() {
 		})
 	})
 
+	Context("when a deletionPolicy is specified", func() {
+		BeforeEach(func() {
+			rd.Spec.External = &volsyncv1alpha1.ReplicationDestinationExternalSpec{}
+		})
+		When("the policy is Delete", func() {
+			BeforeEach(func() {
+				rd.Spec.DeletionPolicy = volsyncv1alpha1.DeletionPolicyDelete
+			})
+			It("no finalizer is added", func() {
+				Consistently(func() []string {
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rd), rd)).To(Succeed())
+					return rd.GetFinalizers()
+				}, duration, interval).ShouldNot(ContainElement(utils.DeletionPolicyFinalizer))
+			})
+		})
+		When("the policy retains objects", func() {
+			var pvc, cache *corev1.PersistentVolumeClaim
+			var latest, previous, temporary *snapv1.VolumeSnapshot
+			newOwnedPVC := func(name string) *corev1.PersistentVolumeClaim {
+				pvc := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: rd.Namespace,
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								"storage": resource.MustParse("1Gi"),
+							},
+						},
+					},
+				}
+				Expect(ctrl.SetControllerReference(rd, pvc, k8sClient.Scheme())).To(Succeed())
+				return pvc
+			}
+			newOwnedSnapshot := func(name string) *snapv1.VolumeSnapshot {
+				snap := &snapv1.VolumeSnapshot{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: rd.Namespace,
+					},
+					Spec: snapv1.VolumeSnapshotSpec{
+						Source: snapv1.VolumeSnapshotSource{
+							PersistentVolumeClaimName: &pvc.Name,
+						},
+					},
+				}
+				Expect(ctrl.SetControllerReference(rd, snap, k8sClient.Scheme())).To(Succeed())
+				return snap
+			}
+			JustBeforeEach(func() {
+				Eventually(func() []string {
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rd), rd)).To(Succeed())
+					return rd.GetFinalizers()
+				}, maxWait, interval).Should(ContainElement(utils.DeletionPolicyFinalizer))
+
+				// The volume receiving the data, and the cache of a restic mover
+				pvc = newOwnedPVC("volsync-instance-dest")
+				pvc.Labels = map[string]string{utils.DataVolumeLabelKey: "true"}
+				createWithCacheReload(ctx, k8sClient, pvc)
+				cache = newOwnedPVC("volsync-instance-cache")
+				createWithCacheReload(ctx, k8sClient, cache)
+
+				latest = newOwnedSnapshot("latest")
+				createWithCacheReload(ctx, k8sClient, latest)
+				previous = newOwnedSnapshot("previous")
+				createWithCacheReload(ctx, k8sClient, previous)
+				temporary = newOwnedSnapshot("temporary")
+				utils.MarkForCleanup(rd, temporary)
+				createWithCacheReload(ctx, k8sClient, temporary)
+
+				image := func(name string) corev1.TypedLocalObjectReference {
+					return corev1.TypedLocalObjectReference{
+						APIGroup: &snapv1.SchemeGroupVersion.Group,
+						Kind:     "VolumeSnapshot",
+						Name:     name,
+					}
+				}
+				latestImage := image(latest.Name)
+				rd.Status = &volsyncv1alpha1.ReplicationDestinationStatus{
+					LatestImage: &latestImage,
+					RetainedImages: []volsyncv1alpha1.RetainedImage{
+						{Image: image(latest.Name), CreationTime: metav1.Now()},
+						{Image: image(previous.Name), CreationTime: metav1.NewTime(time.Now().Add(-time.Hour))},
+					},
+				}
+				Expect(k8sClient.Status().Update(ctx, rd)).To(Succeed())
+
+				Expect(k8sClient.Delete(ctx, rd)).To(Succeed())
+				Eventually(func() bool {
+					return kerrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(rd), rd))
+				}, maxWait, interval).Should(BeTrue())
+			})
+
+			When("the policy is Retain", func() {
+				BeforeEach(func() {
+					rd.Spec.DeletionPolicy = volsyncv1alpha1.DeletionPolicyRetain
+				})
+				It("the volume and retained images are kept when the CR is deleted", func() {
+					// Without ownership, they won't be garbage collected
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+					Expect(pvc.GetOwnerReferences()).To(BeEmpty())
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(latest), latest)).To(Succeed())
+					Expect(latest.GetOwnerReferences()).To(BeEmpty())
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(previous), previous)).To(Succeed())
+					Expect(previous.GetOwnerReferences()).To(BeEmpty())
+
+					// The restic cache is still owned, so it gets garbage collected
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cache), cache)).To(Succeed())
+					Expect(cache.GetOwnerReferences()).To(HaveLen(1))
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(temporary), temporary)).To(Succeed())
+					Expect(temporary.GetOwnerReferences()).To(HaveLen(1))
+				})
+			})
+
+			When("the policy is RetainLatest", func() {
+				BeforeEach(func() {
+					rd.Spec.DeletionPolicy = volsyncv1alpha1.DeletionPolicyRetainLatest
+				})
+				It("only the volume and latest image are kept when the CR is deleted", func() {
+					// Without ownership, they won't be garbage collected
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+					Expect(pvc.GetOwnerReferences()).To(BeEmpty())
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(latest), latest)).To(Succeed())
+					Expect(latest.GetOwnerReferences()).To(BeEmpty())
+
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(previous), previous)).To(Succeed())
+					Expect(previous.GetOwnerReferences()).To(HaveLen(1))
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cache), cache)).To(Succeed())
+					Expect(cache.GetOwnerReferences()).To(HaveLen(1))
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(temporary), temporary)).To(Succeed())
+					Expect(temporary.GetOwnerReferences()).To(HaveLen(1))
+				})
+			})
+		})
+	})
+
 	Context("when no replication method is specified", func() {
 		It("the CR is reports an error in the status", func() {
 			Eventually(func() *volsyncv1alpha1.ReplicationDestinationStatus {
@@ -42,6 +42,7 @@ import (
 	"github.com/backube/volsync/controllers/mover"
 	sm "github.com/backube/volsync/controllers/statemachine"
 	"github.com/backube/volsync/controllers/utils"
+	"github.com/backube/volsync/controllers/volumehandler"
 )
 
 // ReplicationSourceReconciler reconciles a ReplicationSource object
@@ -92,13 +93,11 @@ func (r *ReplicationSourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-
 	if inst.Status == nil {
 		inst.Status = &volsyncv1alpha1.ReplicationSourceStatus{}
 	}
 
 	var result ctrl.Result
-	var err error
 
 	// Check if privileged movers are allowed via namespace annotation
 	privilegedMoverOk, err := utils.PrivilegedMoversOk(ctx, r.Client, logger, inst.GetNamespace())
@@ -154,6 +153,7 @@ func (r *ReplicationSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		}).
 		Owns(&batchv1.Job{}).
 		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&corev1.Pod{}).
 		Owns(&corev1.Secret{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.ServiceAccount{}).
@@ -208,6 +208,13 @@ func (m *rsMachine) SetLastManualTag(tag string) {
 	m.rs.Status.LastManualSync = tag
 }
 
+func (m *rsMachine) VerifyRequested() bool {
+	if v, ok := m.mover.(mover.Verifier); ok {
+		return v.VerifyRequested()
+	}
+	return false
+}
+
 func (m *rsMachine) NextSyncTime() *metav1.Time {
 	return m.rs.Status.NextSyncTime
 }
@@ -261,7 +268,22 @@ func (m *rsMachine) ObserveSyncDuration(duration time.Duration) {
 }
 
 func (m *rsMachine) Synchronize(ctx context.Context) (mover.Result, error) {
-	return m.mover.Synchronize(ctx)
+	result, err := m.mover.Synchronize(ctx)
+	if errors.Is(err, volumehandler.ErrSourceSnapshotReplicated) {
+		// The destination is already up to date, there is nothing to transfer
+		return mover.Complete(), nil
+	}
+	if err == nil && result.Completed && m.rs.Spec.SourceSnapshot != nil {
+		if err := volumehandler.RecordReplicatedSourceSnapshot(ctx, m.client, m.rs); err != nil {
+			return mover.InProgress(), err
+		}
+	}
+
+	if m.rs.Status.Syncthing != nil {
+		m.metrics.SetSyncthingFolder(m.rs.Status.Syncthing.Folder)
+	}
+
+	return result, err
 }
 
 func (m *rsMachine) Cleanup(ctx context.Context) (mover.Result, error) {
@@ -31,6 +31,7 @@ type fakeMachine struct {
 	CS                  string
 	MT                  string
 	LMT                 string
+	VR                  bool
 	NST                 *metav1.Time
 	LSST                *metav1.Time
 	LST                 *metav1.Time
@@ -59,6 +60,7 @@ func (f *fakeMachine) Cronspec() string                       { return f.CS }
 func (f *fakeMachine) ManualTag() string                      { return f.MT }
 func (f *fakeMachine) LastManualTag() string                  { return f.LMT }
 func (f *fakeMachine) SetLastManualTag(t string)              { f.LMT = t }
+func (f *fakeMachine) VerifyRequested() bool                  { return f.VR }
 func (f *fakeMachine) NextSyncTime() *metav1.Time             { return f.NST }
 func (f *fakeMachine) SetNextSyncTime(t *metav1.Time)         { f.NST = t }
 func (f *fakeMachine) LastSyncStartTime() *metav1.Time        { return f.LSST }
@@ -72,6 +74,10 @@ func (f *fakeMachine) SetOutOfSync(oos bool)                  { f.OOSync = oos }
 func (f *fakeMachine) IncMissedIntervals()                    { f.MissedIntervals++ }
 func (f *fakeMachine) ObserveSyncDuration(t time.Duration)    { f.DurationObservation = t }
 func (f *fakeMachine) Synchronize(_ context.Context) (mover.Result, error) {
+	// Like the movers, a completed verification is no longer requested
+	if f.SyncResult.Completed && f.SyncErr == nil {
+		f.VR = false
+	}
 	return f.SyncResult, f.SyncErr
 }
 func (f *fakeMachine) Cleanup(_ context.Context) (mover.Result, error) {
@@ -33,6 +33,9 @@ type ReplicationMachine interface {
 	ManualTag() string
 	LastManualTag() string
 	SetLastManualTag(string)
+	// VerifyRequested returns true if the next iteration should verify the
+	// destination instead of synchronizing it
+	VerifyRequested() bool
 
 	NextSyncTime() *metav1.Time
 	SetNextSyncTime(*metav1.Time)
@@ -101,11 +101,16 @@ func doInitialState(_ context.Context, r ReplicationMachine, l logr.Logger) (ctr
 }
 
 func doSynchronizingState(ctx context.Context, r ReplicationMachine, l logr.Logger) (ctrl.Result, error) {
+	verifying := r.VerifyRequested()
 	result, err := r.Synchronize(ctx)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	if result.Completed {
+	if result.Completed && verifying {
+		// A verification doesn't transfer any data, so it doesn't count as a
+		// sync
+		transitionToCleaningUpAfterVerify(r, l)
+	} else if result.Completed {
 		// Just finished a sync, so we're in-sync
 		r.SetOutOfSync(false)
 		err = transitionToCleaningUp(r, l)
@@ -221,8 +226,22 @@ func transitionToCleaningUp(r ReplicationMachine, l logr.Logger) error {
 	return nil
 }
 
+func transitionToCleaningUpAfterVerify(r ReplicationMachine, l logr.Logger) {
+	l.V(1).Info("transitioning to cleanup state after verification")
+
+	// Leave the sync times and manual tag alone so that the next sync is
+	// triggered as if the verification had not happened
+	r.SetLastSyncStartTime(nil)
+
+	setConditionCleanup(r, l)
+}
+
 // Given that we've finished cleanup, should we start syncing again?
 func shouldSync(r ReplicationMachine, l logr.Logger) bool {
+	if r.VerifyRequested() {
+		// A verification is run as soon as it is requested
+		return true
+	}
 	switch getTrigger(r) {
 	case scheduleTrigger:
 		// When schedule-based, we trigger a sync once we pass the appointed
@@ -154,6 +154,30 @@ var _ = When("in cleanup", func() {
 			Expect(currentState(m)).To(Equal(synchronizingState))
 			Expect(apimeta.IsStatusConditionTrue(m.Cond, volsyncv1alpha1.ConditionSynchronizing)).To(BeTrue())
 		})
+		It("runs a verification when requested without counting it as a sync", func() {
+			m.CleanupResult = mover.Complete()
+			_, err := Run(ctx, m, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(currentState(m)).To(Equal(cleaningUpState))
+			lastSync := m.LST
+
+			// Requesting a verification should start an iteration
+			m.VR = true
+			_, err = Run(ctx, m, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(currentState(m)).To(Equal(synchronizingState))
+
+			// Once complete, go back to waiting for the manual trigger
+			_, err = Run(ctx, m, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(m.VR).To(BeFalse())
+			Expect(currentState(m)).To(Equal(cleaningUpState))
+			Expect(m.LST).To(Equal(lastSync))
+			Expect(m.LMT).To(Equal("1"))
+			_, err = Run(ctx, m, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(currentState(m)).To(Equal(cleaningUpState))
+		})
 	})
 	When("the trigger is scheduled", func() {
 		BeforeEach(func() {
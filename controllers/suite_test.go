@@ -87,6 +87,8 @@ var _ = BeforeSuite(func() {
 		},
 		ErrorIfCRDPathMissing: true,
 	}
+	// Allow PVCs to use a ReplicationDestination in another namespace as their dataSourceRef
+	testEnv.ControlPlane.GetAPIServer().Configure().Append("feature-gates", "CrossNamespaceVolumeDataSource=true")
 
 	var err error
 	cfg, err = testEnv.Start()
@@ -143,10 +145,11 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&VolumePopulatorReconciler{
-		Client:        k8sManager.GetClient(),
-		Log:           ctrl.Log.WithName("controllers").WithName("VolumePopulator"),
-		Scheme:        k8sManager.GetScheme(),
-		EventRecorder: &record.FakeRecorder{},
+		Client:             k8sManager.GetClient(),
+		Log:                ctrl.Log.WithName("controllers").WithName("VolumePopulator"),
+		Scheme:             k8sManager.GetScheme(),
+		EventRecorder:      &record.FakeRecorder{},
+		CopyContainerImage: rsync.ContainerImage(),
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -23,7 +23,9 @@ import (
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -32,12 +34,21 @@ const nodeHostnameLabelKey = "kubernetes.io/hostname" // Standard kube node host
 type AffinityInfo struct {
 	NodeSelector map[string]string
 	Tolerations  []corev1.Toleration
+	Affinity     *corev1.Affinity
 }
 
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
 
 // Determine the proper affinity to apply based on the current users of a PVC
+//
+// The mover is always restricted to the nodes that can access the volume, as
+// described by the nodeAffinity of its PV (which is where CSI drivers record the
+// topology of the volume). If the volume is RWO and used by a running or
+// pending Pod, the mover is co-located with that Pod through a pod affinity on
+// the Pod's labels. When those labels also match Pods that aren't using the
+// volume, the mover is pinned to the Pod's node with a nodeSelector instead.
 func AffinityFromVolume(ctx context.Context, c client.Client, logger logr.Logger,
 	pvc *corev1.PersistentVolumeClaim) (*AffinityInfo, error) {
 	if pvc == nil {
@@ -46,18 +57,29 @@ func AffinityFromVolume(ctx context.Context, c client.Client, logger logr.Logger
 		return nil, err
 	}
 
-	// If it's an RWX, it doesn't matter where we schedule
+	nodeAffinity, err := nodeAffinityForVolume(ctx, c, logger, pvc)
+	if err != nil {
+		return nil, err
+	}
+	volumeAffinity := &AffinityInfo{}
+	if nodeAffinity != nil {
+		volumeAffinity.Affinity = &corev1.Affinity{NodeAffinity: nodeAffinity}
+	}
+
+	// If it's an RWX, it doesn't matter which Pods are using it
 	for _, am := range pvc.Status.AccessModes {
 		if am == corev1.ReadWriteMany {
-			return &AffinityInfo{}, nil
+			return volumeAffinity, nil
 		}
 	}
 
 	// Find all the Pods that are using the PVC
-	podsUsing, err := podsUsingPVC(ctx, c, logger, pvc)
-	if err != nil {
+	podList := corev1.PodList{}
+	if err := c.List(ctx, &podList, client.InNamespace(pvc.Namespace)); err != nil {
+		logger.Error(err, "unable to list pods in namespace")
 		return nil, err
 	}
+	podsUsing := podsUsingPVC(podList.Items, pvc)
 
 	// Loop through all the volumes and find:
 	// - A running Pod using the volume
@@ -74,8 +96,19 @@ func AffinityFromVolume(ctx context.Context, c client.Client, logger logr.Logger
 	}
 
 	if candidatePod == nil {
-		// Nobody is using the volume
-		return &AffinityInfo{}, nil
+		// Nobody is using the volume (or the Pods using it have terminated)
+		return volumeAffinity, nil
+	}
+
+	if podAffinityTerm := podAffinityTermForPod(candidatePod, podList.Items, podsUsing); podAffinityTerm != nil {
+		if volumeAffinity.Affinity == nil {
+			volumeAffinity.Affinity = &corev1.Affinity{}
+		}
+		volumeAffinity.Affinity.PodAffinity = &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{*podAffinityTerm},
+		}
+		volumeAffinity.Tolerations = candidatePod.Spec.Tolerations
+		return volumeAffinity, nil
 	}
 
 	nodeSelector, err := getNodeSelectorForNode(ctx, c, logger, candidatePod.Spec.NodeName)
@@ -86,6 +119,7 @@ func AffinityFromVolume(ctx context.Context, c client.Client, logger logr.Logger
 	affinity := AffinityInfo{
 		NodeSelector: nodeSelector,
 		Tolerations:  candidatePod.Spec.Tolerations,
+		Affinity:     volumeAffinity.Affinity,
 	}
 
 	return &affinity, nil
@@ -117,16 +151,9 @@ func getNodeSelectorForNode(ctx context.Context, c client.Client, logger logr.Lo
 }
 
 // Find all the Pods using a PVC
-func podsUsingPVC(ctx context.Context, c client.Client, logger logr.Logger,
-	pvc *corev1.PersistentVolumeClaim) ([]corev1.Pod, error) {
-	podList := corev1.PodList{}
-	if err := c.List(ctx, &podList, client.InNamespace(pvc.Namespace)); err != nil {
-		logger.Error(err, "unable to list pods in namespace")
-		return nil, err
-	}
-
+func podsUsingPVC(pods []corev1.Pod, pvc *corev1.PersistentVolumeClaim) []corev1.Pod {
 	podsUsing := []corev1.Pod{}
-	for _, pod := range podList.Items {
+	for _, pod := range pods {
 		for _, volume := range pod.Spec.Volumes {
 			if volume.PersistentVolumeClaim != nil &&
 				volume.PersistentVolumeClaim.ClaimName == pvc.Name {
@@ -135,5 +162,67 @@ func podsUsingPVC(ctx context.Context, c client.Client, logger logr.Logger,
 		}
 	}
 
-	return podsUsing, nil
+	return podsUsing
+}
+
+// Returns the nodes that can access the volume bound to the PVC, as required
+// by its PV. nil is returned when the volume is accessible from all nodes or
+// the PVC isn't bound yet.
+func nodeAffinityForVolume(ctx context.Context, c client.Client, logger logr.Logger,
+	pvc *corev1.PersistentVolumeClaim) (*corev1.NodeAffinity, error) {
+	if pvc.Spec.VolumeName == "" {
+		return nil, nil
+	}
+
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: pvc.Spec.VolumeName,
+		},
+	}
+	err := c.Get(ctx, client.ObjectKeyFromObject(pv), pv)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error(err, "Error getting PV", "pvName", pv.GetName())
+		return nil, err
+	}
+
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return nil, nil
+	}
+	return &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: pv.Spec.NodeAffinity.Required.DeepCopy(),
+	}, nil
+}
+
+// Returns a pod affinity term that co-locates the mover with the given Pod, or
+// nil if the Pod's labels can't be used to select only the Pods using the
+// volume.
+func podAffinityTermForPod(pod *corev1.Pod, pods []corev1.Pod, podsUsing []corev1.Pod) *corev1.PodAffinityTerm {
+	if len(pod.GetLabels()) == 0 {
+		return nil
+	}
+	selector := labels.SelectorFromSet(pod.GetLabels())
+
+	for i := range pods {
+		if !selector.Matches(labels.Set(pods[i].GetLabels())) {
+			continue
+		}
+		isUsing := false
+		for j := range podsUsing {
+			if podsUsing[j].GetUID() == pods[i].GetUID() {
+				isUsing = true
+				break
+			}
+		}
+		if !isUsing {
+			return nil
+		}
+	}
+
+	return &corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: pod.GetLabels()},
+		TopologyKey:   nodeHostnameLabelKey,
+	}
 }
@@ -185,6 +185,71 @@ var _ = Describe("Volume affinity", func() {
 		})
 	})
 
+	Context("When the PV of the PVC has a node affinity", func() {
+		var pv *corev1.PersistentVolume
+		var pvc *corev1.PersistentVolumeClaim
+		zoneSelector := &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "topology.kubernetes.io/zone",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{"zone-a"},
+				}},
+			}},
+		}
+
+		BeforeEach(func() {
+			pv = &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "zonal-",
+				},
+				Spec: corev1.PersistentVolumeSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+					Capacity: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse("1Gi"),
+					},
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{
+							Driver:       "zonal.csi.example.com",
+							VolumeHandle: "vol-1",
+						},
+					},
+					NodeAffinity: &corev1.VolumeNodeAffinity{Required: zoneSelector},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pv)).To(Succeed())
+
+			pvc = &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "zonal",
+					Namespace: ns.Name,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: resource.MustParse("1Gi"),
+						},
+					},
+					VolumeName: pv.Name,
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, pv)).To(Succeed())
+		})
+
+		It("will restrict the mover to the nodes that can access the volume", func() {
+			ai, err := utils.AffinityFromVolume(ctx, k8sClient, logger, pvc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ai.NodeSelector).To(BeEmpty())
+			Expect(ai.Affinity).NotTo(BeNil())
+			Expect(ai.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(Equal(zoneSelector))
+			Expect(ai.Affinity.PodAffinity).To(BeNil())
+		})
+	})
+
 	Context("When a pod is using the PVC", func() {
 		// These tests will require looking up the node hosting the pod to find the proper nodeSelector
 		var runningPodNode, pendingPodNode, vsPodNode *corev1.Node
@@ -272,6 +337,62 @@ var _ = Describe("Volume affinity", func() {
 			})
 		})
 
+		When("the Pods using a PVC have labels", func() {
+			BeforeEach(func() {
+				runningPod.Labels = map[string]string{"app": "database", "instance": "primary"}
+				Expect(k8sClient.Update(ctx, runningPod)).To(Succeed())
+			})
+
+			It("will have a pod affinity that co-locates the mover with the Running pod", func() {
+				ai, err := utils.AffinityFromVolume(ctx, k8sClient, logger, rwoBoth)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ai.NodeSelector).To(BeEmpty())
+				Expect(ai.Tolerations).To(Equal(runningPod.Spec.Tolerations))
+				Expect(ai.Affinity).NotTo(BeNil())
+				Expect(ai.Affinity.NodeAffinity).To(BeNil())
+				Expect(ai.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(Equal(
+					[]corev1.PodAffinityTerm{{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: runningPod.Labels},
+						TopologyKey:   "kubernetes.io/hostname",
+					}},
+				))
+			})
+
+			When("the labels also match a Pod that isn't using the PVC", func() {
+				var otherPod *corev1.Pod
+				BeforeEach(func() {
+					otherPod = makePod("other", []corev1.PersistentVolumeClaim{*rwoNone}, corev1.PodRunning, false)
+					otherPod.Labels = runningPod.Labels
+					Expect(k8sClient.Update(ctx, otherPod)).To(Succeed())
+				})
+
+				It("will pin the mover to the node of the Running pod instead", func() {
+					ai, err := utils.AffinityFromVolume(ctx, k8sClient, logger, rwoBoth)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ai.NodeSelector).To(Equal(
+						map[string]string{
+							"kubernetes.io/hostname": runningPod.Spec.NodeName,
+						},
+					))
+					Expect(ai.Affinity).To(BeNil())
+				})
+			})
+		})
+
+		When("the Pods using a PVC have terminated", func() {
+			BeforeEach(func() {
+				pendingPod.Status.Phase = corev1.PodSucceeded
+				Expect(k8sClient.Status().Update(ctx, pendingPod)).To(Succeed())
+			})
+
+			It("will not restrict where the mover runs", func() {
+				ai, err := utils.AffinityFromVolume(ctx, k8sClient, logger, rwoPending)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ai.NodeSelector).To(BeEmpty())
+				Expect(ai.Affinity).To(BeNil())
+			})
+		})
+
 		// Disabled since the code was removed. VolSync ignores its own pods now
 		XWhen("a PVC is being used only by a VolSync-owned pod", func() {
 			It("will have an affinity that matches that pod", func() {
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// diskrsync-tcp periodically prints its progress as JSON on a line starting
+// with this prefix
+const diskrsyncProgressPrefix = "diskrsync-progress: "
+
+// Number of lines at the end of the mover logs to search for the most recent
+// progress report
+const blockProgressTailLines int64 = 100
+
+// The progress report printed by diskrsync-tcp
+type diskrsyncProgress struct {
+	Time              time.Time `json:"time"`
+	TotalBytes        int64     `json:"totalBytes"`
+	BytesHashed       int64     `json:"bytesHashed"`
+	BytesSynchronized int64     `json:"bytesSynchronized"`
+	BytesSent         int64     `json:"bytesSent"`
+	Percent           int32     `json:"percent"`
+	ETASeconds        *int64    `json:"etaSeconds"`
+}
+
+// LogLineFilterBlockProgress keeps the progress reports of diskrsync-tcp
+func LogLineFilterBlockProgress(line string) *string {
+	if strings.HasPrefix(line, diskrsyncProgressPrefix) {
+		return &line
+	}
+	return nil
+}
+
+// ParseBlockTransferProgress returns the most recent progress report found in
+// the mover logs, or nil if there isn't one
+func ParseBlockTransferProgress(logs string) *volsyncv1alpha1.BlockTransferProgress {
+	var latest *diskrsyncProgress
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, diskrsyncProgressPrefix) {
+			continue
+		}
+		report := &diskrsyncProgress{}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, diskrsyncProgressPrefix)), report); err != nil {
+			continue
+		}
+		latest = report
+	}
+	if latest == nil {
+		return nil
+	}
+
+	progress := &volsyncv1alpha1.BlockTransferProgress{
+		Percent:           latest.Percent,
+		TotalBytes:        latest.TotalBytes,
+		BytesHashed:       latest.BytesHashed,
+		BytesSynchronized: latest.BytesSynchronized,
+		BytesSent:         latest.BytesSent,
+	}
+	if !latest.Time.IsZero() {
+		progress.LastUpdateTime = &metav1.Time{Time: latest.Time}
+		if latest.ETASeconds != nil {
+			progress.EstimatedCompletionTime = &metav1.Time{
+				Time: latest.Time.Add(time.Duration(*latest.ETASeconds) * time.Second),
+			}
+		}
+	}
+	return progress
+}
+
+// GetBlockTransferProgress returns the most recent progress reported by the
+// running mover pod of the job. It returns nil if there is no running pod or
+// it hasn't reported any progress yet.
+func GetBlockTransferProgress(ctx context.Context, logger logr.Logger,
+	jobName, jobNamespace string) (*volsyncv1alpha1.BlockTransferProgress, error) {
+	runningPods, _, _, err := GetPodsForJob(ctx, logger, jobName, jobNamespace)
+	if err != nil {
+		return nil, err
+	}
+	pod := getNewestPod(runningPods)
+	if pod == nil {
+		return nil, nil
+	}
+
+	logs, err := getPodLogsTail(ctx, logger, pod.GetName(), jobNamespace, blockProgressTailLines,
+		LogLineFilterBlockProgress)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBlockTransferProgress(logs), nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/backube/volsync/controllers/utils"
+)
+
+var _ = Describe("Block transfer progress tests", func() {
+	logs := `2023-10-01T10:00:00.000Z	INFO	Connecting to remote
+diskrsync-progress: {"time":"2023-10-01T10:00:10Z","totalBytes":1000,"bytesHashed":200,"bytesSynchronized":0,"bytesSent":10,"percent":0}
+2023-10-01T10:00:15.000Z	INFO	some other line
+diskrsync-progress: {"time":"2023-10-01T10:00:20Z","totalBytes":1000,"bytesHashed":600,"bytesSynchronized":400,"bytesSent":420,"percent":40,"etaSeconds":30}
+diskrsync-progress: not json`
+
+	It("Should keep only the progress reports", func() {
+		filteredLines, err := utils.FilterLogs(strings.NewReader(logs), utils.LogLineFilterBlockProgress)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Split(filteredLines, "\n")).To(HaveLen(3))
+	})
+
+	It("Should use the most recent valid report", func() {
+		progress := utils.ParseBlockTransferProgress(logs)
+		Expect(progress).NotTo(BeNil())
+		Expect(progress.Percent).To(Equal(int32(40)))
+		Expect(progress.TotalBytes).To(Equal(int64(1000)))
+		Expect(progress.BytesHashed).To(Equal(int64(600)))
+		Expect(progress.BytesSynchronized).To(Equal(int64(400)))
+		Expect(progress.BytesSent).To(Equal(int64(420)))
+
+		reportTime := time.Date(2023, 10, 1, 10, 0, 20, 0, time.UTC)
+		Expect(progress.LastUpdateTime.Time).To(BeTemporally("==", reportTime))
+		Expect(progress.EstimatedCompletionTime.Time).To(BeTemporally("==", reportTime.Add(30*time.Second)))
+	})
+
+	It("Should not estimate the completion time before the mover does", func() {
+		progress := utils.ParseBlockTransferProgress(strings.Split(logs, "\n")[1])
+		Expect(progress).NotTo(BeNil())
+		Expect(progress.EstimatedCompletionTime).To(BeNil())
+	})
+
+	It("Should return nothing when there is no report", func() {
+		Expect(utils.ParseBlockTransferProgress("rsync completed in 2s")).To(BeNil())
+	})
+})
@@ -19,7 +19,10 @@ package utils
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
@@ -27,7 +30,10 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
 )
 
 // MarkForCleanup marks the provided "obj" to be deleted at the end of the
@@ -42,6 +48,12 @@ func UnmarkForCleanup(obj metav1.Object) bool {
 	return RemoveLabel(obj, cleanupLabelKey)
 }
 
+// IsMarkedForCleanup returns true if the provided "obj" has been marked to be
+// deleted by its owner
+func IsMarkedForCleanup(obj metav1.Object) bool {
+	return HasLabel(obj, cleanupLabelKey)
+}
+
 // CleanupObjects deletes all objects that have been marked. The objects to be
 // cleaned up must have been previously marked via MarkForCleanup() and
 // associated with "owner". The "types" array should contain one object of each
@@ -240,15 +252,66 @@ func RemoveOwnerReference(obj metav1.Object, owner client.Object) bool {
 	return updated
 }
 
+// RelinquishRetainedObjects removes the ownership of "owner" from its data
+// volume and from the VolumeSnapshots named in "retainSnapshots", so that they
+// are not garbage collected along with it. Its other objects, like caches or
+// the objects marked for cleanup, are left to be garbage collected.
+func RelinquishRetainedObjects(ctx context.Context, c client.Client, logger logr.Logger,
+	owner client.Object, retainSnapshots []string) error {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := c.List(ctx, pvcList, client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels{DataVolumeLabelKey: "true"}); err != nil {
+		return err
+	}
+	for i := range pvcList.Items {
+		if err := relinquishIfRetained(ctx, c, logger, owner, &pvcList.Items[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range retainSnapshots {
+		snapshot := &snapv1.VolumeSnapshot{}
+		err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, snapshot)
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := relinquishIfRetained(ctx, c, logger, owner, snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func relinquishIfRetained(ctx context.Context, c client.Client, logger logr.Logger,
+	owner client.Object, obj client.Object) error {
+	if !hasOwnerRef(obj, owner) || IsMarkedForCleanup(obj) {
+		return nil
+	}
+	logger.Info("retaining object after the deletion of its owner", "name", obj.GetName())
+	if UnMarkForCleanupAndRemoveOwnership(obj, owner) {
+		if err := c.Update(ctx, obj); err != nil {
+			logger.Error(err, "error removing cleanup label or ownerRef from object",
+				"name", obj.GetName(), "namespace", obj.GetNamespace())
+			return err
+		}
+	}
+	return nil
+}
+
 func snapInUseByOther(snapshot *snapv1.VolumeSnapshot, owner client.Object) bool {
-	return hasOtherOwnerRef(snapshot, owner) || snapInUseByVolumePopulatorPVC(snapshot)
+	return hasOtherOwnerRef(snapshot, owner) || SnapInUseByVolumePopulatorPVC(snapshot)
 }
 
-func snapInUseByVolumePopulatorPVC(snapshot *snapv1.VolumeSnapshot) bool {
+// SnapInUseByVolumePopulatorPVC returns true if a PVC of the volume populator
+// is being populated from the snapshot
+func SnapInUseByVolumePopulatorPVC(snapshot *snapv1.VolumeSnapshot) bool {
 	// Volume Populator will put on a label with a specific prefix on a snapshot while
 	// it's populating the PVC from that snapshot - this indicates at least one pvc for
 	// the volume populator is actively using this snapshot
-	for _, labelKey := range snapshot.GetLabels() {
+	for labelKey := range snapshot.GetLabels() {
 		if strings.HasPrefix(labelKey, SnapInUseByVolumePopulatorLabelPrefix) {
 			return true
 		}
@@ -256,6 +319,15 @@ func snapInUseByVolumePopulatorPVC(snapshot *snapv1.VolumeSnapshot) bool {
 	return false
 }
 
+func hasOwnerRef(obj metav1.Object, owner client.Object) bool {
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
 func hasOtherOwnerRef(obj metav1.Object, owner client.Object) bool {
 	for _, ownerRef := range obj.GetOwnerReferences() {
 		if ownerRef.UID != owner.GetUID() {
@@ -316,6 +388,86 @@ func MarkOldSnapshotForCleanup(ctx context.Context, c client.Client, logger logr
 	return nil
 }
 
+// MarkExpiredSnapshotsForCleanup marks the snapshots among "images" that are
+// no longer selected by the retain policy for cleanup. The first of the images
+// must be the latest one. The images that are still retained are returned,
+// newest first.
+func MarkExpiredSnapshotsForCleanup(ctx context.Context, c client.Client, logger logr.Logger,
+	owner metav1.Object, images []volsyncv1alpha1.RetainedImage,
+	policy *volsyncv1alpha1.SnapshotRetainPolicy) ([]volsyncv1alpha1.RetainedImage, error) {
+	if len(images) == 0 {
+		return nil, nil
+	}
+	latestImage := images[0].Image
+
+	retained, expired := SelectRetainedImages(images, policy)
+	for i := range expired {
+		logger.Info("snapshot is no longer retained, marking for cleanup", "name", expired[i].Image.Name)
+		err := MarkOldSnapshotForCleanup(ctx, c, logger, owner, &expired[i].Image, &latestImage)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return retained, nil
+}
+
+// SelectRetainedImages splits the images into those that are selected by the
+// retain policy and those that have expired. Like restic's forget, each
+// hourly/daily/weekly/monthly rule keeps the most recent image of that many
+// periods which have an image. The most recent image is always retained.
+func SelectRetainedImages(images []volsyncv1alpha1.RetainedImage,
+	policy *volsyncv1alpha1.SnapshotRetainPolicy) (retained, expired []volsyncv1alpha1.RetainedImage) {
+	sorted := make([]volsyncv1alpha1.RetainedImage, len(images))
+	copy(sorted, images)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreationTime.After(sorted[j].CreationTime.Time)
+	})
+
+	keep := make([]bool, len(sorted))
+	if len(keep) > 0 {
+		keep[0] = true
+	}
+	if policy != nil {
+		if policy.Last != nil {
+			for i := 0; i < len(sorted) && i < int(*policy.Last); i++ {
+				keep[i] = true
+			}
+		}
+		keepPeriods := func(count *int32, period func(t time.Time) string) {
+			if count == nil {
+				return
+			}
+			remaining := *count
+			lastPeriod := ""
+			for i := 0; i < len(sorted) && remaining > 0; i++ {
+				p := period(sorted[i].CreationTime.UTC())
+				if p == lastPeriod {
+					continue
+				}
+				lastPeriod = p
+				keep[i] = true
+				remaining--
+			}
+		}
+		keepPeriods(policy.Hourly, func(t time.Time) string { return t.Format("2006-01-02T15") })
+		keepPeriods(policy.Daily, func(t time.Time) string { return t.Format("2006-01-02") })
+		keepPeriods(policy.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		})
+		keepPeriods(policy.Monthly, func(t time.Time) string { return t.Format("2006-01") })
+	}
+
+	for i := range sorted {
+		if keep[i] {
+			retained = append(retained, sorted[i])
+		} else {
+			expired = append(expired, sorted[i])
+		}
+	}
+	return retained, expired
+}
+
 func IsSnapshot(image *corev1.TypedLocalObjectReference) bool {
 	if image == nil {
 		return false
@@ -18,6 +18,9 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package utils_test
 
 import (
+	"fmt"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -355,6 +358,50 @@ var _ = Describe("Cleanup", func() {
 		})
 	})
 
+	Describe("Snapshot retention", func() {
+		var images []volsyncv1alpha1.RetainedImage
+		var retained []volsyncv1alpha1.RetainedImage
+
+		BeforeEach(func() {
+			now := time.Now()
+			images = []volsyncv1alpha1.RetainedImage{
+				retainedImage(snapA2.GetName(), now),
+				retainedImage(snapA1.GetName(), now.Add(-time.Minute)),
+				retainedImage("snap-gone", now.Add(-2*time.Minute)),
+			}
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			retained, err = utils.MarkExpiredSnapshotsForCleanup(ctx, k8sClient, logger, rdA, images,
+				&volsyncv1alpha1.SnapshotRetainPolicy{Last: ptr.To[int32](1)})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should mark the snapshots that are no longer retained for cleanup", func() {
+			Expect(retained).To(HaveLen(1))
+			Expect(retained[0].Image.Name).To(Equal(snapA2.GetName()))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snapA1), snapA1)).To(Succeed())
+			Expect(snapA1.GetLabels()).To(HaveKeyWithValue("volsync.backube/cleanup", string(rdA.GetUID())))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snapA2), snapA2)).To(Succeed())
+			Expect(snapA2.GetLabels()).NotTo(HaveKey("volsync.backube/cleanup"))
+		})
+
+		Context("When an expired snapshot is marked do-not-delete", func() {
+			BeforeEach(func() {
+				utils.MarkDoNotDelete(snapA1)
+				Expect(k8sClient.Update(ctx, snapA1)).To(Succeed())
+			})
+
+			It("Should not mark it for cleanup", func() {
+				Expect(retained).To(HaveLen(1))
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snapA1), snapA1)).To(Succeed())
+				Expect(snapA1.GetLabels()).NotTo(HaveKey("volsync.backube/cleanup"))
+			})
+		})
+	})
+
 	Describe("Relinquish snapshots", func() {
 		Context("When some snapshots have the do-not-delete label", func() {
 			BeforeEach(func() {
@@ -389,6 +436,46 @@ var _ = Describe("Cleanup", func() {
 			})
 		})
 	})
+
+	Describe("Relinquish retained objects", func() {
+		BeforeEach(func() {
+			// pvcA2 holds the data of rdA, pvcA1 is one of its other volumes (like a cache)
+			pvcA2.Labels = map[string]string{utils.DataVolumeLabelKey: "true"}
+			Expect(k8sClient.Update(ctx, pvcA2)).To(Succeed())
+			// snapA1 is a temporary object of rdA
+			utils.MarkForCleanup(rdA, snapA1)
+			Expect(k8sClient.Update(ctx, snapA1)).To(Succeed())
+		})
+
+		It("Should remove the ownership of the data volume and the retained snapshots", func() {
+			Expect(utils.RelinquishRetainedObjects(ctx, k8sClient, logger, rdA,
+				[]string{snapA2.GetName(), snapA1.GetName(), "missing-snap"})).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snapA2), snapA2)).To(Succeed())
+			validateCleanupLabelAndOwnerRefRemoved(snapA2)
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcA2), pvcA2)).To(Succeed())
+			validateCleanupLabelAndOwnerRefRemoved(pvcA2)
+
+			// The other volumes and the temporary objects are still owned, so they get garbage collected
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcA1), pvcA1)).To(Succeed())
+			Expect(pvcA1.GetOwnerReferences()).To(HaveLen(1))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snapA1), snapA1)).To(Succeed())
+			validateCleanupLabelAndOwnerRef(snapA1, rdA)
+
+			// Objects of other owners are left alone
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snapB1), snapB1)).To(Succeed())
+			Expect(snapB1.GetOwnerReferences()).To(HaveLen(1))
+		})
+
+		It("Should keep the ownership of the snapshots that are not retained", func() {
+			Expect(utils.RelinquishRetainedObjects(ctx, k8sClient, logger, rdA, nil)).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snapA2), snapA2)).To(Succeed())
+			Expect(snapA2.GetOwnerReferences()).To(HaveLen(1))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcA2), pvcA2)).To(Succeed())
+			validateCleanupLabelAndOwnerRefRemoved(pvcA2)
+		})
+	})
 })
 
 // This assumes there was only 1 owner ref at the start
@@ -411,3 +498,65 @@ func validateCleanupLabelAndOwnerRef(obj client.Object, owner client.Object) {
 	Expect(ownerRef.Kind).To(Equal("ReplicationDestination"))
 	Expect(ownerRef.Name).To(Equal(owner.GetName()))
 }
+
+var _ = Describe("Selecting retained images", func() {
+	// One image every 6 hours over 60 days, newest first
+	var images []volsyncv1alpha1.RetainedImage
+	latest := time.Date(2024, time.March, 20, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4*60; i++ {
+		images = append(images, retainedImage(fmt.Sprintf("snap-%d", i), latest.Add(-6*time.Hour*time.Duration(i))))
+	}
+
+	It("Should only keep the latest image without a policy", func() {
+		retained, expired := utils.SelectRetainedImages(images, nil)
+		Expect(retained).To(HaveLen(1))
+		Expect(retained[0].Image.Name).To(Equal("snap-0"))
+		Expect(expired).To(HaveLen(len(images) - 1))
+	})
+
+	It("Should keep the most recent images", func() {
+		retained, _ := utils.SelectRetainedImages(images,
+			&volsyncv1alpha1.SnapshotRetainPolicy{Last: ptr.To[int32](3)})
+		Expect(retained).To(HaveLen(3))
+		Expect(retained[2].Image.Name).To(Equal("snap-2"))
+	})
+
+	It("Should keep the most recent image of each period", func() {
+		retained, expired := utils.SelectRetainedImages(images, &volsyncv1alpha1.SnapshotRetainPolicy{
+			Daily:   ptr.To[int32](3),
+			Weekly:  ptr.To[int32](2),
+			Monthly: ptr.To[int32](3),
+		})
+		names := []string{}
+		for _, image := range retained {
+			names = append(names, image.Image.Name)
+		}
+		Expect(names).To(Equal([]string{
+			"snap-0",   // Wed Mar 20 12:00 - latest, today, this week, this month
+			"snap-3",   // Tue Mar 19 18:00 - yesterday
+			"snap-7",   // Mon Mar 18 18:00 - 2 days ago
+			"snap-11",  // Sun Mar 17 18:00 - the previous week
+			"snap-79",  // Thu Feb 29 18:00 - the previous month
+			"snap-195", // Wed Jan 31 18:00 - the month before that
+		}))
+		Expect(len(retained) + len(expired)).To(Equal(len(images)))
+	})
+
+	It("Should sort the images from newest to oldest", func() {
+		reversed := []volsyncv1alpha1.RetainedImage{images[2], images[0], images[1]}
+		retained, expired := utils.SelectRetainedImages(reversed, nil)
+		Expect(retained[0].Image.Name).To(Equal("snap-0"))
+		Expect(expired[0].Image.Name).To(Equal("snap-1"))
+	})
+})
+
+func retainedImage(name string, created time.Time) volsyncv1alpha1.RetainedImage {
+	return volsyncv1alpha1.RetainedImage{
+		Image: corev1.TypedLocalObjectReference{
+			APIGroup: &snapv1.SchemeGroupVersion.Group,
+			Kind:     "VolumeSnapshot",
+			Name:     name,
+		},
+		CreationTime: metav1.NewTime(created),
+	}
+}
@@ -25,6 +25,13 @@ const (
 	OwnedByLabelValue   = "volsync"
 
 	SnapInUseByVolumePopulatorLabelPrefix = VolsyncLabelPrefix + "/volpop-pvc-"
+
+	// Finalizer that applies the deletion policy of a ReplicationSource or
+	// ReplicationDestination before its owned objects are garbage collected
+	DeletionPolicyFinalizer = VolsyncLabelPrefix + "/deletion-policy"
+	// Marks the volume holding the replicated data of a ReplicationSource or
+	// ReplicationDestination, which is kept by its deletion policy
+	DataVolumeLabelKey = VolsyncLabelPrefix + "/data-volume"
 )
 
 type Labelable interface {
@@ -0,0 +1,54 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// noSpaceReport is what a destination mover leaves in its termination message
+// when it runs out of space. The source reports how much data it holds when it
+// is the one that detected it.
+type noSpaceReport struct {
+	NoSpace       bool  `json:"noSpace"`
+	RequiredBytes int64 `json:"requiredBytes,omitempty"`
+}
+
+// GetNoSpaceReportForJob returns true if the newest failed pod of the job
+// reported that its volume is full. The capacity the data requires is also
+// returned, or nil if it isn't known.
+func GetNoSpaceReportForJob(ctx context.Context, logger logr.Logger,
+	jobName, jobNamespace string) (bool, *resource.Quantity, error) {
+	message, err := getTerminationMessageForJob(ctx, logger, jobName, jobNamespace, true)
+	if err != nil || message == "" {
+		return false, nil, err
+	}
+	report := &noSpaceReport{}
+	if err := json.Unmarshal([]byte(message), report); err != nil || !report.NoSpace {
+		// Any other termination message isn't a report of a full volume
+		return false, nil, nil
+	}
+	if report.RequiredBytes <= 0 {
+		return true, nil, nil
+	}
+	return true, resource.NewQuantity(report.RequiredBytes, resource.BinarySI), nil
+}
@@ -106,6 +106,13 @@ func IsMoverLogDebug() bool {
 }
 
 func getPodLogs(ctx context.Context, logger logr.Logger, podName, podNamespace string,
+	lineFilter func(line string) *string) (string, error) {
+	return getPodLogsTail(ctx, logger, podName, podNamespace, GetMoverLogTailLines(), lineFilter)
+}
+
+// Gets the logs from the pod, only looking at the last tailLines lines (all
+// lines if negative)
+func getPodLogsTail(ctx context.Context, logger logr.Logger, podName, podNamespace string, tailLines int64,
 	lineFilter func(line string) *string) (string, error) {
 	l := logger.WithValues("podName", podName, "podNamespace", podNamespace)
 
@@ -114,7 +121,6 @@ func getPodLogs(ctx context.Context, logger logr.Logger, podName, podNamespace s
 		Follow: false,
 	}
 
-	tailLines := GetMoverLogTailLines()
 	if tailLines >= 0 {
 		podLogOptions.TailLines = &tailLines
 	}
@@ -219,6 +225,27 @@ func TruncateString(s string, maxBytes int) string {
 	return s
 }
 
+// GetTerminationMessageForJob returns the termination message of the newest
+// successful pod of the job, or "" if there is none
+func GetTerminationMessageForJob(ctx context.Context, logger logr.Logger,
+	jobName, jobNamespace string) (string, error) {
+	return getTerminationMessageForJob(ctx, logger, jobName, jobNamespace, false)
+}
+
+func getTerminationMessageForJob(ctx context.Context, logger logr.Logger,
+	jobName, jobNamespace string, jobFailed bool) (string, error) {
+	pod, err := GetNewestPodForJob(ctx, logger, jobName, jobNamespace, jobFailed)
+	if err != nil || pod == nil {
+		return "", err
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.State.Terminated != nil && containerStatus.State.Terminated.Message != "" {
+			return containerStatus.State.Terminated.Message, nil
+		}
+	}
+	return "", nil
+}
+
 // Attempts to get the newest successful pod when jobFailed==false
 // Attempts to get the newest failed pod (or newest running pod if no failed pods) if jobFailed==true
 func GetNewestPodForJob(ctx context.Context, logger logr.Logger,
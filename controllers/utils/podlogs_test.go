@@ -28,6 +28,7 @@ import (
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -151,6 +152,49 @@ var _ = Describe("Pod Logs Tests", func() {
 				Expect(pod.GetName()).To(Equal(pod7Failed.GetName()))
 			})
 
+			It("Should get the termination message of the latest successful pod", func() {
+				message, err := utils.GetTerminationMessageForJob(ctx, logger, job.GetName(), job.GetNamespace())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(message).To(BeEmpty())
+
+				pod5Succeeded.Status.ContainerStatuses = []corev1.ContainerStatus{{
+					Name: "test",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{Message: `{"match":true}`},
+					},
+				}}
+				Expect(k8sClient.Status().Update(ctx, pod5Succeeded)).To(Succeed())
+				Eventually(func() string {
+					message, err = utils.GetTerminationMessageForJob(ctx, logger, job.GetName(), job.GetNamespace())
+					Expect(err).NotTo(HaveOccurred())
+					return message
+				}, timeout, interval).Should(Equal(`{"match":true}`))
+			})
+
+			It("Should get a report of a full volume from the latest failed pod", func() {
+				full, required, err := utils.GetNoSpaceReportForJob(ctx, logger, job.GetName(), job.GetNamespace())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(full).To(BeFalse())
+				Expect(required).To(BeNil())
+
+				pod7Failed.Status.ContainerStatuses = []corev1.ContainerStatus{{
+					Name: "test",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							Message: `{"noSpace":true,"requiredBytes":2147483648}`,
+						},
+					},
+				}}
+				Expect(k8sClient.Status().Update(ctx, pod7Failed)).To(Succeed())
+				Eventually(func() bool {
+					full, required, err = utils.GetNoSpaceReportForJob(ctx, logger, job.GetName(), job.GetNamespace())
+					Expect(err).NotTo(HaveOccurred())
+					return full
+				}, timeout, interval).Should(BeTrue())
+				Expect(required).NotTo(BeNil())
+				Expect(required.Cmp(resource.MustParse("2Gi"))).To(Equal(0))
+			})
+
 			When("No failed pods exist for a job", func() {
 				BeforeEach(func() {
 					// Update the failed pods - set them to phase unknown
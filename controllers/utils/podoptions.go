@@ -0,0 +1,328 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// appliedPodOptionsAnnotation records on a pod template the labels,
+// annotations, node selector, tolerations, affinity, resources and priority
+// class that were added from the pod options, so they can be removed once they
+// are no longer requested
+const appliedPodOptionsAnnotation = VolsyncLabelPrefix + "/applied-pod-options"
+
+// appliedPodOptions are the parts of the pod options that were added to a pod
+// template, as opposed to the ones that were already set by VolSync
+type appliedPodOptions struct {
+	Labels            map[string]string   `json:"labels,omitempty"`
+	Annotations       map[string]string   `json:"annotations,omitempty"`
+	NodeSelector      map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations       []corev1.Toleration `json:"tolerations,omitempty"`
+	PriorityClassName string              `json:"priorityClassName,omitempty"`
+	// The affinity and resources replace the ones of VolSync, which are kept
+	// so they can be restored
+	Affinity          *corev1.Affinity              `json:"affinity,omitempty"`
+	PreviousAffinity  *corev1.Affinity              `json:"previousAffinity,omitempty"`
+	Resources         *corev1.ResourceRequirements  `json:"resources,omitempty"`
+	PreviousResources []corev1.ResourceRequirements `json:"previousResources,omitempty"`
+}
+
+// ApplyMoverPodOptions applies the user-supplied pod options to the pod
+// template of a data mover. It should be called once VolSync has finished
+// setting up the template, since the labels, annotations, node selector and
+// affinity chosen by VolSync are not overridden. The options that were applied
+// to the template before are replaced, so that the template always consists of
+// the settings of VolSync and the current options.
+func ApplyMoverPodOptions(template *corev1.PodTemplateSpec, options *volsyncv1alpha1.MoverPodOptions) {
+	removeAppliedPodOptions(template)
+	if options == nil {
+		return
+	}
+	applied := appliedPodOptions{}
+
+	for k, v := range options.MoverPodLabels {
+		if _, ok := template.Labels[k]; !ok {
+			AddLabel(template, k, v)
+			applied.Labels = addToMap(applied.Labels, k, v)
+		}
+	}
+	for k, v := range options.MoverPodAnnotations {
+		if _, ok := template.Annotations[k]; !ok {
+			template.Annotations = addToMap(template.Annotations, k, v)
+			applied.Annotations = addToMap(applied.Annotations, k, v)
+		}
+	}
+
+	podSpec := &template.Spec
+	if options.MoverResources != nil {
+		applied.Resources = options.MoverResources.DeepCopy()
+		for i := range podSpec.Containers {
+			applied.PreviousResources = append(applied.PreviousResources, podSpec.Containers[i].Resources)
+			podSpec.Containers[i].Resources = *options.MoverResources.DeepCopy()
+		}
+	}
+	for k, v := range options.MoverNodeSelector {
+		if _, ok := podSpec.NodeSelector[k]; !ok {
+			podSpec.NodeSelector = addToMap(podSpec.NodeSelector, k, v)
+			applied.NodeSelector = addToMap(applied.NodeSelector, k, v)
+		}
+	}
+	for i := range options.MoverTolerations {
+		if !hasToleration(podSpec.Tolerations, &options.MoverTolerations[i]) {
+			podSpec.Tolerations = append(podSpec.Tolerations, options.MoverTolerations[i])
+			applied.Tolerations = append(applied.Tolerations, options.MoverTolerations[i])
+		}
+	}
+	if options.MoverAffinity != nil {
+		applied.PreviousAffinity = podSpec.Affinity.DeepCopy()
+		podSpec.Affinity = mergeAffinity(podSpec.Affinity, options.MoverAffinity)
+		applied.Affinity = podSpec.Affinity.DeepCopy()
+	}
+	if options.MoverPriorityClassName != nil {
+		podSpec.PriorityClassName = *options.MoverPriorityClassName
+		applied.PriorityClassName = *options.MoverPriorityClassName
+	}
+
+	if !reflect.DeepEqual(applied, appliedPodOptions{}) {
+		// Marshaling these types can't fail
+		data, _ := json.Marshal(&applied)
+		template.Annotations = addToMap(template.Annotations, appliedPodOptionsAnnotation, string(data))
+	}
+}
+
+// removeAppliedPodOptions removes the pod options that were applied to the
+// template by ApplyMoverPodOptions. Settings that VolSync has changed since are
+// kept.
+func removeAppliedPodOptions(template *corev1.PodTemplateSpec) {
+	data, ok := template.Annotations[appliedPodOptionsAnnotation]
+	if !ok {
+		return
+	}
+	delete(template.Annotations, appliedPodOptionsAnnotation)
+	applied := appliedPodOptions{}
+	if err := json.Unmarshal([]byte(data), &applied); err != nil {
+		return
+	}
+
+	template.Labels = removeFromMap(template.Labels, applied.Labels)
+	template.Annotations = removeFromMap(template.Annotations, applied.Annotations)
+	podSpec := &template.Spec
+	podSpec.NodeSelector = removeFromMap(podSpec.NodeSelector, applied.NodeSelector)
+	if len(applied.Tolerations) > 0 {
+		var tolerations []corev1.Toleration
+		for _, toleration := range podSpec.Tolerations {
+			if !containsItem(applied.Tolerations, toleration) {
+				tolerations = append(tolerations, toleration)
+			}
+		}
+		podSpec.Tolerations = tolerations
+	}
+	if applied.PriorityClassName != "" && podSpec.PriorityClassName == applied.PriorityClassName {
+		podSpec.PriorityClassName = ""
+	}
+	if applied.Affinity != nil && equality.Semantic.DeepEqual(podSpec.Affinity, applied.Affinity) {
+		podSpec.Affinity = applied.PreviousAffinity
+	}
+	if applied.Resources != nil {
+		for i := range podSpec.Containers {
+			if i < len(applied.PreviousResources) &&
+				equality.Semantic.DeepEqual(podSpec.Containers[i].Resources, *applied.Resources) {
+				podSpec.Containers[i].Resources = applied.PreviousResources[i]
+			}
+		}
+	}
+}
+
+func addToMap(m map[string]string, key, value string) map[string]string {
+	if m == nil {
+		m = map[string]string{}
+	}
+	m[key] = value
+	return m
+}
+
+// removeFromMap removes the entries of m that are also in removed, and returns
+// nil if none remain
+func removeFromMap(m map[string]string, removed map[string]string) map[string]string {
+	for k, v := range removed {
+		if value, ok := m[k]; ok && value == v {
+			delete(m, k)
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// DestinationMoverPodOptions returns the pod options of the mover of the
+// ReplicationDestination, or nil if its mover has none
+func DestinationMoverPodOptions(rd *volsyncv1alpha1.ReplicationDestination) *volsyncv1alpha1.MoverPodOptions {
+	switch {
+	case rd.Spec.Rsync != nil:
+		return &rd.Spec.Rsync.MoverPodOptions
+	case rd.Spec.RsyncTLS != nil:
+		return &rd.Spec.RsyncTLS.MoverPodOptions
+	case rd.Spec.Rclone != nil:
+		return &rd.Spec.Rclone.MoverPodOptions
+	case rd.Spec.Restic != nil:
+		return &rd.Spec.Restic.MoverPodOptions
+	case rd.Spec.Syncthing != nil:
+		return &rd.Spec.Syncthing.MoverPodOptions
+	}
+	return nil
+}
+
+func hasToleration(tolerations []corev1.Toleration, toleration *corev1.Toleration) bool {
+	for i := range tolerations {
+		if tolerations[i].MatchToleration(toleration) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeAffinity combines the affinity that VolSync chose for the mover with the
+// one supplied by the user, so that the mover has to satisfy both. Merging the
+// same affinity again leaves the result unchanged.
+func mergeAffinity(affinity, extra *corev1.Affinity) *corev1.Affinity {
+	if affinity == nil {
+		return extra.DeepCopy()
+	}
+	merged := affinity.DeepCopy()
+
+	if extra.NodeAffinity != nil {
+		if merged.NodeAffinity == nil {
+			merged.NodeAffinity = &corev1.NodeAffinity{}
+		}
+		merged.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = mergeNodeSelectors(
+			merged.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			extra.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+		merged.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = appendMissing(
+			merged.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			extra.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+	}
+
+	if extra.PodAffinity != nil {
+		if merged.PodAffinity == nil {
+			merged.PodAffinity = &corev1.PodAffinity{}
+		}
+		merged.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = appendMissing(
+			merged.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			extra.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+		merged.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = appendMissing(
+			merged.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			extra.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+	}
+
+	if extra.PodAntiAffinity != nil {
+		if merged.PodAntiAffinity == nil {
+			merged.PodAntiAffinity = &corev1.PodAntiAffinity{}
+		}
+		merged.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = appendMissing(
+			merged.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			extra.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+		merged.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = appendMissing(
+			merged.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			extra.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+	}
+
+	return merged
+}
+
+// mergeNodeSelectors returns a node selector that only selects the nodes that
+// are selected by both. Since the terms of a node selector are ORed, each term
+// of one is combined with each term of the other.
+func mergeNodeSelectors(selector, extra *corev1.NodeSelector) *corev1.NodeSelector {
+	if extra == nil {
+		return selector
+	}
+	if selector == nil {
+		return extra.DeepCopy()
+	}
+	if nodeSelectorImplies(selector, extra) {
+		// Already merged
+		return selector
+	}
+
+	merged := &corev1.NodeSelector{}
+	for _, term := range selector.NodeSelectorTerms {
+		for _, extraTerm := range extra.NodeSelectorTerms {
+			mergedTerm := term.DeepCopy()
+			mergedTerm.MatchExpressions = append(mergedTerm.MatchExpressions, extraTerm.MatchExpressions...)
+			mergedTerm.MatchFields = append(mergedTerm.MatchFields, extraTerm.MatchFields...)
+			merged.NodeSelectorTerms = append(merged.NodeSelectorTerms, *mergedTerm)
+		}
+	}
+	return merged
+}
+
+// nodeSelectorImplies returns whether every term of the selector includes all
+// the requirements of one of the terms of the other selector, in which case
+// any node matching the selector also matches the other one.
+func nodeSelectorImplies(selector, other *corev1.NodeSelector) bool {
+	for _, term := range selector.NodeSelectorTerms {
+		implied := false
+		for _, otherTerm := range other.NodeSelectorTerms {
+			if containsAll(term.MatchExpressions, otherTerm.MatchExpressions) &&
+				containsAll(term.MatchFields, otherTerm.MatchFields) {
+				implied = true
+				break
+			}
+		}
+		if !implied {
+			return false
+		}
+	}
+	return true
+}
+
+// appendMissing appends the extra items that aren't in the list yet
+func appendMissing[T any](items, extra []T) []T {
+	for _, item := range extra {
+		if !containsItem(items, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func containsAll[T any](items, wanted []T) bool {
+	for _, item := range wanted {
+		if !containsItem(items, item) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsItem[T any](items []T, wanted T) bool {
+	for _, item := range items {
+		if reflect.DeepEqual(item, wanted) {
+			return true
+		}
+	}
+	return false
+}
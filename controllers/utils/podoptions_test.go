@@ -0,0 +1,250 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+var _ = Describe("Mover pod options", func() {
+	var template *corev1.PodTemplateSpec
+	var options *volsyncv1alpha1.MoverPodOptions
+
+	BeforeEach(func() {
+		template = &corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"app.kubernetes.io/created-by": "volsync"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "mover"}, {Name: "sidecar"}},
+				NodeSelector: map[string]string{
+					"kubernetes.io/hostname": "node-a",
+				},
+			},
+		}
+		options = &volsyncv1alpha1.MoverPodOptions{
+			MoverResources: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			MoverNodeSelector: map[string]string{
+				"kubernetes.io/hostname": "node-b",
+				"node-type":              "storage",
+			},
+			MoverTolerations: []corev1.Toleration{{
+				Key:      "dedicated",
+				Operator: corev1.TolerationOpExists,
+			}},
+			MoverAffinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{},
+			},
+			MoverPriorityClassName: ptr.To("backup"),
+			MoverPodLabels: map[string]string{
+				"app.kubernetes.io/created-by": "someone-else",
+				"team":                         "storage",
+			},
+			MoverPodAnnotations: map[string]string{"example.com/owner": "storage"},
+		}
+	})
+
+	It("Should leave the template alone without options", func() {
+		original := template.DeepCopy()
+		utils.ApplyMoverPodOptions(template, nil)
+		utils.ApplyMoverPodOptions(template, &volsyncv1alpha1.MoverPodOptions{})
+		Expect(template).To(Equal(original))
+	})
+
+	It("Should apply the options to the pod template", func() {
+		utils.ApplyMoverPodOptions(template, options)
+
+		for _, container := range template.Spec.Containers {
+			Expect(container.Resources).To(Equal(*options.MoverResources))
+		}
+		Expect(template.Spec.Tolerations).To(Equal(options.MoverTolerations))
+		Expect(template.Spec.Affinity).To(Equal(options.MoverAffinity))
+		Expect(template.Spec.Affinity).NotTo(BeIdenticalTo(options.MoverAffinity))
+		Expect(template.Spec.PriorityClassName).To(Equal("backup"))
+		Expect(template.Annotations).To(HaveKeyWithValue("example.com/owner", "storage"))
+		Expect(template.Labels).To(HaveKeyWithValue("team", "storage"))
+	})
+
+	It("Should not override the labels and node selector set by VolSync", func() {
+		utils.ApplyMoverPodOptions(template, options)
+
+		Expect(template.Labels).To(HaveKeyWithValue("app.kubernetes.io/created-by", "volsync"))
+		Expect(template.Spec.NodeSelector).To(Equal(map[string]string{
+			"kubernetes.io/hostname": "node-a",
+			"node-type":              "storage",
+		}))
+	})
+
+	It("Should combine the affinity with the one chosen by VolSync", func() {
+		zoneTerm := corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{
+				Key:      "topology.kubernetes.io/zone",
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{"zone-a"},
+			}},
+		}
+		colocate := corev1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "database"}},
+			TopologyKey:   "kubernetes.io/hostname",
+		}
+		template.Spec.Affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{zoneTerm},
+				},
+			},
+			PodAffinity: &corev1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{colocate},
+			},
+		}
+		storageRequirement := corev1.NodeSelectorRequirement{
+			Key:      "node-type",
+			Operator: corev1.NodeSelectorOpNotIn,
+			Values:   []string{"gpu"},
+		}
+		avoidBackups := corev1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "backup"}},
+			TopologyKey:   "kubernetes.io/hostname",
+		}
+		options.MoverAffinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{storageRequirement},
+					}},
+				},
+			},
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{avoidBackups},
+			},
+		}
+
+		utils.ApplyMoverPodOptions(template, options)
+		affinity := template.Spec.Affinity
+		Expect(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal(
+			[]corev1.NodeSelectorTerm{{
+				MatchExpressions: append(zoneTerm.MatchExpressions, storageRequirement),
+			}},
+		))
+		Expect(affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(
+			Equal([]corev1.PodAffinityTerm{colocate}))
+		Expect(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(
+			Equal([]corev1.PodAffinityTerm{avoidBackups}))
+
+		// Applying the options again doesn't change the template
+		merged := template.DeepCopy()
+		utils.ApplyMoverPodOptions(template, options)
+		Expect(template).To(Equal(merged))
+	})
+
+	It("Should not add the same toleration twice", func() {
+		utils.ApplyMoverPodOptions(template, options)
+		utils.ApplyMoverPodOptions(template, options)
+		Expect(template.Spec.Tolerations).To(HaveLen(1))
+	})
+
+	It("Should remove the options that are no longer set", func() {
+		original := template.DeepCopy()
+		utils.ApplyMoverPodOptions(template, options)
+
+		options = &volsyncv1alpha1.MoverPodOptions{
+			MoverNodeSelector: map[string]string{"node-type": "fast-storage"},
+			MoverPodLabels:    map[string]string{"tier": "backup"},
+		}
+		utils.ApplyMoverPodOptions(template, options)
+		Expect(template.Labels).To(Equal(map[string]string{
+			"app.kubernetes.io/created-by": "volsync",
+			"tier":                         "backup",
+		}))
+		Expect(template.Annotations).NotTo(HaveKey("example.com/owner"))
+		Expect(template.Spec.NodeSelector).To(Equal(map[string]string{
+			"kubernetes.io/hostname": "node-a",
+			"node-type":              "fast-storage",
+		}))
+		Expect(template.Spec.Tolerations).To(BeEmpty())
+		Expect(template.Spec.PriorityClassName).To(BeEmpty())
+		Expect(template.Spec.Affinity).To(BeNil())
+		for _, container := range template.Spec.Containers {
+			Expect(container.Resources).To(Equal(corev1.ResourceRequirements{}))
+		}
+
+		// Only the settings of VolSync remain once all the options are removed
+		utils.ApplyMoverPodOptions(template, &volsyncv1alpha1.MoverPodOptions{})
+		Expect(template.ObjectMeta).To(Equal(original.ObjectMeta))
+		Expect(template.Spec.NodeSelector).To(Equal(original.Spec.NodeSelector))
+		Expect(template.Spec.Tolerations).To(BeEmpty())
+	})
+
+	It("Should restore the affinity and resources of VolSync when the options are removed", func() {
+		volsyncAffinity := &corev1.Affinity{
+			PodAffinity: &corev1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "database"}},
+					TopologyKey:   "kubernetes.io/hostname",
+				}},
+			},
+		}
+		volsyncResources := corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		}
+		template.Spec.Affinity = volsyncAffinity.DeepCopy()
+		template.Spec.Containers[0].Resources = volsyncResources
+		options.MoverAffinity = &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "backup"}},
+					TopologyKey:   "kubernetes.io/hostname",
+				}},
+			},
+		}
+		utils.ApplyMoverPodOptions(template, options)
+		Expect(template.Spec.Affinity.PodAntiAffinity).NotTo(BeNil())
+		Expect(template.Spec.Containers[0].Resources).To(Equal(*options.MoverResources))
+
+		utils.ApplyMoverPodOptions(template, nil)
+		Expect(template.Spec.Affinity).To(Equal(volsyncAffinity))
+		Expect(template.Spec.Containers[0].Resources).To(Equal(volsyncResources))
+		Expect(template.Spec.Containers[1].Resources).To(Equal(corev1.ResourceRequirements{}))
+	})
+
+	It("Should keep the settings of VolSync when the options are removed", func() {
+		options.MoverTolerations = append(options.MoverTolerations, corev1.Toleration{
+			Key:      "node.kubernetes.io/unreachable",
+			Operator: corev1.TolerationOpExists,
+		})
+		template.Spec.Tolerations = []corev1.Toleration{options.MoverTolerations[1]}
+		utils.ApplyMoverPodOptions(template, options)
+		Expect(template.Spec.Tolerations).To(HaveLen(2))
+
+		utils.ApplyMoverPodOptions(template, nil)
+		Expect(template.Spec.Tolerations).To(Equal([]corev1.Toleration{options.MoverTolerations[1]}))
+		Expect(template.Labels).To(Equal(map[string]string{"app.kubernetes.io/created-by": "volsync"}))
+		Expect(template.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/hostname": "node-a"}))
+	})
+})
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+
+// ReferenceGrantListGVK is the Gateway API ReferenceGrant kind that is used to allow references across
+// namespaces. The Gateway API types are accessed as unstructured objects since the CRD is optional.
+var ReferenceGrantListGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1beta1",
+	Kind:    "ReferenceGrantList",
+}
+
+// ReferenceGrantSpec mirrors the spec of a Gateway API ReferenceGrant
+type ReferenceGrantSpec struct {
+	From []ReferenceGrantFrom `json:"from"`
+	To   []ReferenceGrantTo   `json:"to"`
+}
+
+// ReferenceGrantFrom describes the namespace and kind of objects that are trusted to make a reference
+type ReferenceGrantFrom struct {
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+}
+
+// ReferenceGrantTo describes the kind (and optionally the name) of objects that may be referenced
+type ReferenceGrantTo struct {
+	Group string  `json:"group"`
+	Kind  string  `json:"kind"`
+	Name  *string `json:"name,omitempty"`
+}
+
+// Grants returns true if the spec allows objects of kind fromGK in fromNamespace to reference the object
+// of kind toGK named toName (in the namespace of the ReferenceGrant)
+func (spec *ReferenceGrantSpec) Grants(fromGK metav1.GroupKind, fromNamespace string,
+	toGK metav1.GroupKind, toName string) bool {
+	fromOk := false
+	for _, from := range spec.From {
+		if from.Group == fromGK.Group && from.Kind == fromGK.Kind && from.Namespace == fromNamespace {
+			fromOk = true
+			break
+		}
+	}
+	if !fromOk {
+		return false
+	}
+	for _, to := range spec.To {
+		if to.Group == toGK.Group && to.Kind == toGK.Kind && (to.Name == nil || *to.Name == toName) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReferenceGranted returns true if a ReferenceGrant in the namespace of the referenced object allows objects
+// of kind fromGK in fromNamespace to reference it. References within a namespace are always allowed. If the
+// ReferenceGrant CRD is not installed, references across namespaces are not allowed.
+func IsReferenceGranted(ctx context.Context, c client.Client, fromGK metav1.GroupKind, fromNamespace string,
+	toGK metav1.GroupKind, to types.NamespacedName) (bool, error) {
+	if fromNamespace == to.Namespace {
+		return true, nil
+	}
+
+	grantList := &unstructured.UnstructuredList{}
+	grantList.SetGroupVersionKind(ReferenceGrantListGVK)
+	if err := c.List(ctx, grantList, client.InNamespace(to.Namespace)); err != nil {
+		if IsCRDNotPresentError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for i := range grantList.Items {
+		specObj, ok := grantList.Items[i].Object["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		spec := &ReferenceGrantSpec{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(specObj, spec); err != nil {
+			return false, err
+		}
+		if spec.Grants(fromGK, fromNamespace, toGK, to.Name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
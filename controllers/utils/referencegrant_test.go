@@ -0,0 +1,113 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"github.com/backube/volsync/controllers/utils"
+)
+
+var _ = Describe("ReferenceGrants", func() {
+	pvcGK := metav1.GroupKind{Group: "", Kind: "PersistentVolumeClaim"}
+	rdGK := metav1.GroupKind{Group: "volsync.backube", Kind: "ReplicationDestination"}
+
+	Describe("Matching a ReferenceGrant spec", func() {
+		var spec *utils.ReferenceGrantSpec
+
+		BeforeEach(func() {
+			spec = &utils.ReferenceGrantSpec{
+				From: []utils.ReferenceGrantFrom{{Group: "", Kind: "PersistentVolumeClaim", Namespace: "app"}},
+				To:   []utils.ReferenceGrantTo{{Group: "volsync.backube", Kind: "ReplicationDestination"}},
+			}
+		})
+
+		It("grants references from the listed namespace and kind to any object of the listed kind", func() {
+			Expect(spec.Grants(pvcGK, "app", rdGK, "rd1")).To(BeTrue())
+			Expect(spec.Grants(pvcGK, "app", rdGK, "rd2")).To(BeTrue())
+		})
+		It("does not grant references from other namespaces or kinds", func() {
+			Expect(spec.Grants(pvcGK, "other", rdGK, "rd1")).To(BeFalse())
+			Expect(spec.Grants(metav1.GroupKind{Kind: "Pod"}, "app", rdGK, "rd1")).To(BeFalse())
+		})
+		It("does not grant references to other kinds", func() {
+			Expect(spec.Grants(pvcGK, "app", metav1.GroupKind{Group: "volsync.backube", Kind: "ReplicationSource"},
+				"rd1")).To(BeFalse())
+		})
+		It("only grants references to the named object when a name is given", func() {
+			spec.To[0].Name = ptr.To("rd1")
+			Expect(spec.Grants(pvcGK, "app", rdGK, "rd1")).To(BeTrue())
+			Expect(spec.Grants(pvcGK, "app", rdGK, "rd2")).To(BeFalse())
+		})
+	})
+
+	Describe("Looking up ReferenceGrants", func() {
+		var fromNs, toNs *corev1.Namespace
+
+		BeforeEach(func() {
+			fromNs = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "refgrant-from-"}}
+			Expect(k8sClient.Create(ctx, fromNs)).To(Succeed())
+			toNs = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "refgrant-to-"}}
+			Expect(k8sClient.Create(ctx, toNs)).To(Succeed())
+		})
+		AfterEach(func() {
+			Expect(k8sClient.Delete(ctx, fromNs)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, toNs)).To(Succeed())
+		})
+
+		It("always allows references within a namespace", func() {
+			granted, err := utils.IsReferenceGranted(ctx, k8sClient, pvcGK, toNs.Name, rdGK,
+				types.NamespacedName{Name: "rd1", Namespace: toNs.Name})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(granted).To(BeTrue())
+		})
+
+		It("allows references across namespaces only with a matching ReferenceGrant", func() {
+			to := types.NamespacedName{Name: "rd1", Namespace: toNs.Name}
+			granted, err := utils.IsReferenceGranted(ctx, k8sClient, pvcGK, fromNs.Name, rdGK, to)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(granted).To(BeFalse())
+
+			grant := &unstructured.Unstructured{}
+			grant.SetAPIVersion("gateway.networking.k8s.io/v1beta1")
+			grant.SetKind("ReferenceGrant")
+			grant.SetName("allow-pvcs")
+			grant.SetNamespace(toNs.Name)
+			grant.Object["spec"] = map[string]interface{}{
+				"from": []interface{}{map[string]interface{}{
+					"group": "", "kind": "PersistentVolumeClaim", "namespace": fromNs.Name,
+				}},
+				"to": []interface{}{map[string]interface{}{
+					"group": "volsync.backube", "kind": "ReplicationDestination",
+				}},
+			}
+			Expect(k8sClient.Create(ctx, grant)).To(Succeed())
+
+			granted, err = utils.IsReferenceGranted(ctx, k8sClient, pvcGK, fromNs.Name, rdGK, to)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(granted).To(BeTrue())
+		})
+	})
+})
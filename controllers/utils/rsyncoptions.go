@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// RsyncTransferOptionsEnvVars returns the env vars used by the rsync-based
+// mover scripts to tune the transfer. Only the options that have been set are
+// returned, so the scripts keep their defaults for everything else.
+func RsyncTransferOptionsEnvVars(opts volsyncv1alpha1.RsyncTransferOptions) []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+
+	if opts.BandwidthLimit != nil {
+		// rsync (and diskrsync-tcp) take the limit in units of 1024 bytes/s.
+		// Round up so that a small, non-zero limit never becomes "unlimited"
+		kib := (opts.BandwidthLimit.Value() + 1023) / 1024
+		if kib > 0 {
+			envVars = append(envVars, corev1.EnvVar{Name: "BANDWIDTH_LIMIT", Value: strconv.FormatInt(kib, 10)})
+		}
+	}
+	if opts.CompressionLevel != nil {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "COMPRESSION_LEVEL",
+			Value: strconv.Itoa(int(*opts.CompressionLevel)),
+		})
+	}
+	envVars = appendBoolEnvVar(envVars, "WHOLE_FILE", opts.WholeFile)
+	envVars = appendBoolEnvVar(envVars, "CHECKSUM", opts.Checksum)
+	envVars = appendBoolEnvVar(envVars, "PARTIAL", opts.Partial)
+
+	return envVars
+}
+
+func appendBoolEnvVar(envVars []corev1.EnvVar, name string, value *bool) []corev1.EnvVar {
+	if value == nil {
+		return envVars
+	}
+	return append(envVars, corev1.EnvVar{Name: name, Value: strconv.FormatBool(*value)})
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+var _ = Describe("rsync transfer options tests", func() {
+	It("Should not set any env vars when no options are set", func() {
+		Expect(utils.RsyncTransferOptionsEnvVars(volsyncv1alpha1.RsyncTransferOptions{})).To(BeEmpty())
+	})
+
+	It("Should set env vars for all options that are set", func() {
+		bwLimit := resource.MustParse("10Mi")
+		envVars := utils.RsyncTransferOptionsEnvVars(volsyncv1alpha1.RsyncTransferOptions{
+			BandwidthLimit:   &bwLimit,
+			CompressionLevel: ptr.To(int32(0)),
+			WholeFile:        ptr.To(true),
+			Checksum:         ptr.To(false),
+			Partial:          ptr.To(true),
+		})
+		Expect(envVars).To(ConsistOf(
+			corev1.EnvVar{Name: "BANDWIDTH_LIMIT", Value: "10240"},
+			corev1.EnvVar{Name: "COMPRESSION_LEVEL", Value: "0"},
+			corev1.EnvVar{Name: "WHOLE_FILE", Value: "true"},
+			corev1.EnvVar{Name: "CHECKSUM", Value: "false"},
+			corev1.EnvVar{Name: "PARTIAL", Value: "true"},
+		))
+	})
+
+	It("Should round the bandwidth limit up to the nearest KiB/s", func() {
+		bwLimit := resource.MustParse("100")
+		Expect(utils.RsyncTransferOptionsEnvVars(volsyncv1alpha1.RsyncTransferOptions{
+			BandwidthLimit: &bwLimit,
+		})).To(ConsistOf(corev1.EnvVar{Name: "BANDWIDTH_LIMIT", Value: "1"}))
+	})
+
+	It("Should treat a bandwidth limit of 0 as unlimited", func() {
+		bwLimit := resource.MustParse("0")
+		Expect(utils.RsyncTransferOptionsEnvVars(volsyncv1alpha1.RsyncTransferOptions{
+			BandwidthLimit: &bwLimit,
+		})).To(BeEmpty())
+	})
+})
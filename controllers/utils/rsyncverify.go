@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// The rsync --stats lines that are used to determine the result of a
+// verification. Numbers may contain thousands separators.
+var (
+	rsyncVerifyFilesRegex   = regexp.MustCompile(`^\s*Number of regular files transferred:\s*([0-9,]+)`)
+	rsyncVerifyBytesRegex   = regexp.MustCompile(`^\s*Total transferred file size:\s*([0-9,]+)`)
+	rsyncVerifyDeletedRegex = regexp.MustCompile(`^\s*Number of deleted files:\s*([0-9,]+)`)
+)
+
+// diskrsync-tcp also prints the result of the verification of a block volume
+// as JSON on a line starting with this prefix
+const diskrsyncVerifyPrefix = "diskrsync-verify: "
+
+// Number of mismatched ranges of a block volume listed in the status message
+const blockVerifyReportedRanges = 10
+
+// The verification result printed by diskrsync-tcp
+type diskrsyncVerification struct {
+	Match            bool  `json:"match"`
+	TotalBytes       int64 `json:"totalBytes"`
+	MismatchedBytes  int64 `json:"mismatchedBytes"`
+	MismatchedRanges []struct {
+		Offset int64 `json:"offset"`
+		Length int64 `json:"length"`
+	} `json:"mismatchedRanges"`
+	Truncated bool `json:"truncated"`
+}
+
+// RsyncVerifyRequested returns true if the verify trigger has been set to a
+// value that hasn't been verified yet
+func RsyncVerifyRequested(verifyTag string, status *volsyncv1alpha1.RsyncVerificationStatus) bool {
+	if verifyTag == "" {
+		return false
+	}
+	return status == nil || status.Tag != verifyTag
+}
+
+// LogLineFilterRsyncVerify keeps the rsync stats lines needed to determine the
+// result of a verification
+func LogLineFilterRsyncVerify(line string) *string {
+	if rsyncVerifyFilesRegex.MatchString(line) || rsyncVerifyBytesRegex.MatchString(line) ||
+		rsyncVerifyDeletedRegex.MatchString(line) {
+		return &line
+	}
+	return nil
+}
+
+// NewRsyncVerificationStatus builds the result of a verification from the
+// mover logs of the (dry-run) rsync job
+func NewRsyncVerificationStatus(verifyTag string, logs string) *volsyncv1alpha1.RsyncVerificationStatus {
+	status := &volsyncv1alpha1.RsyncVerificationStatus{
+		Tag:            verifyTag,
+		CompletionTime: ptr.To(metav1.Now()),
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value := parseRsyncStat(rsyncVerifyFilesRegex, line); value != nil {
+			status.DifferingFiles = value
+		} else if value := parseRsyncStat(rsyncVerifyBytesRegex, line); value != nil {
+			status.DifferingBytes = value
+		} else if value := parseRsyncStat(rsyncVerifyDeletedRegex, line); value != nil {
+			status.ExtraFiles = value
+		}
+	}
+
+	if status.DifferingFiles == nil {
+		status.Message = "unable to determine the result of the verification from the mover logs"
+		return status
+	}
+
+	// Older versions of rsync don't report the number of deleted files
+	status.InSync = ptr.To(*status.DifferingFiles == 0 && (status.ExtraFiles == nil || *status.ExtraFiles == 0))
+	return status
+}
+
+// LogLineFilterBlockVerify keeps the result of the verification of a block
+// volume
+func LogLineFilterBlockVerify(line string) *string {
+	if strings.HasPrefix(line, diskrsyncVerifyPrefix) {
+		return &line
+	}
+	return nil
+}
+
+// NewBlockVerificationStatus builds the result of the verification of a block
+// volume from the control file published by the mover. The verification fails
+// if the mover didn't publish a result.
+func NewBlockVerificationStatus(verifyTag string, controlFile string) *volsyncv1alpha1.RsyncVerificationStatus {
+	status := &volsyncv1alpha1.RsyncVerificationStatus{
+		Tag:            verifyTag,
+		CompletionTime: ptr.To(metav1.Now()),
+	}
+
+	if controlFile == "" {
+		status.Message = "the mover did not report a result"
+		return status
+	}
+	result := &diskrsyncVerification{}
+	if err := json.Unmarshal([]byte(controlFile), result); err != nil {
+		status.Message = fmt.Sprintf("unable to read the result reported by the mover: %v", err)
+		return status
+	}
+
+	status.InSync = ptr.To(result.Match)
+	status.DifferingBytes = ptr.To(result.MismatchedBytes)
+	if !result.Match {
+		ranges := []string{}
+		for i, r := range result.MismatchedRanges {
+			if i == blockVerifyReportedRanges {
+				break
+			}
+			ranges = append(ranges, fmt.Sprintf("%d-%d", r.Offset, r.Offset+r.Length))
+		}
+		more := ""
+		if result.Truncated || len(result.MismatchedRanges) > blockVerifyReportedRanges {
+			more = ", ..."
+		}
+		status.Message = fmt.Sprintf("mismatched byte ranges: %s%s", strings.Join(ranges, ", "), more)
+	}
+	return status
+}
+
+// NewRsyncVerificationStatusNotPerformed records that a verification was
+// requested but could not be performed
+func NewRsyncVerificationStatusNotPerformed(verifyTag string,
+	message string) *volsyncv1alpha1.RsyncVerificationStatus {
+	return &volsyncv1alpha1.RsyncVerificationStatus{
+		Tag:            verifyTag,
+		CompletionTime: ptr.To(metav1.Now()),
+		Message:        message,
+	}
+}
+
+// PublishRsyncVerificationEvent publishes an Event on the owner describing the
+// result of a verification
+func PublishRsyncVerificationEvent(er events.EventRecorder, owner client.Object,
+	status *volsyncv1alpha1.RsyncVerificationStatus) {
+	switch {
+	case status.InSync == nil:
+		er.Eventf(owner, nil, corev1.EventTypeWarning, volsyncv1alpha1.EvRVerifyNotInSync, volsyncv1alpha1.EvANone,
+			"verification failed: %s", status.Message)
+	case *status.InSync:
+		er.Eventf(owner, nil, corev1.EventTypeNormal, volsyncv1alpha1.EvRVerifyInSync, volsyncv1alpha1.EvANone,
+			"destination matches the source")
+	case status.DifferingFiles == nil:
+		// Block volumes are compared by byte ranges rather than files
+		er.Eventf(owner, nil, corev1.EventTypeWarning, volsyncv1alpha1.EvRVerifyNotInSync, volsyncv1alpha1.EvANone,
+			"destination differs from the source: %d differing bytes, %s", ptr.Deref(status.DifferingBytes, 0),
+			status.Message)
+	default:
+		er.Eventf(owner, nil, corev1.EventTypeWarning, volsyncv1alpha1.EvRVerifyNotInSync, volsyncv1alpha1.EvANone,
+			"destination differs from the source: %d differing files (%d bytes), %d extra files",
+			ptr.Deref(status.DifferingFiles, 0), ptr.Deref(status.DifferingBytes, 0), ptr.Deref(status.ExtraFiles, 0))
+	}
+}
+
+func parseRsyncStat(regex *regexp.Regexp, line string) *int64 {
+	match := regex.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+	value, err := strconv.ParseInt(strings.ReplaceAll(match[1], ",", ""), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
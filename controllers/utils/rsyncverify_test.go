@@ -0,0 +1,144 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+var _ = Describe("rsync verification tests", func() {
+	// Output of rsync --stats from a dry-run with --delete
+	statsOutput := `Verifying data on the destination (no data will be transferred)
+
+Number of files: 1,502 (reg: 1,400, dir: 102)
+Number of created files: 3 (reg: 3)
+Number of deleted files: 2 (reg: 2)
+Number of regular files transferred: 1,203
+Total file size: 5,242,880 bytes
+Total transferred file size: 1,234,567 bytes
+Literal data: 0 bytes
+Matched data: 0 bytes
+File list size: 0
+File list generation time: 0.001 seconds
+File list transfer time: 0.000 seconds
+Total bytes sent: 40,091
+Total bytes received: 1,342
+
+sent 40,091 bytes  received 1,342 bytes  82,866.00 bytes/sec
+total size is 5,242,880  speedup is 126.54 (DRY RUN)
+rsync completed in 2s`
+
+	It("Should only request a verification when the trigger has a new value", func() {
+		Expect(utils.RsyncVerifyRequested("", nil)).To(BeFalse())
+		Expect(utils.RsyncVerifyRequested("v1", nil)).To(BeTrue())
+		Expect(utils.RsyncVerifyRequested("v1", &volsyncv1alpha1.RsyncVerificationStatus{Tag: "v1"})).To(BeFalse())
+		Expect(utils.RsyncVerifyRequested("v2", &volsyncv1alpha1.RsyncVerificationStatus{Tag: "v1"})).To(BeTrue())
+	})
+
+	It("Should keep only the stats needed for the verification", func() {
+		filteredLines, err := utils.FilterLogs(strings.NewReader(statsOutput), utils.LogLineFilterRsyncVerify)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filteredLines).To(Equal(`Number of deleted files: 2 (reg: 2)
+Number of regular files transferred: 1,203
+Total transferred file size: 1,234,567 bytes`))
+	})
+
+	It("Should record the differences found", func() {
+		status := utils.NewRsyncVerificationStatus("v1", statsOutput)
+		Expect(status.Tag).To(Equal("v1"))
+		Expect(status.CompletionTime).NotTo(BeNil())
+		Expect(*status.InSync).To(BeFalse())
+		Expect(*status.DifferingFiles).To(Equal(int64(1203)))
+		Expect(*status.DifferingBytes).To(Equal(int64(1234567)))
+		Expect(*status.ExtraFiles).To(Equal(int64(2)))
+		Expect(status.Message).To(BeEmpty())
+	})
+
+	It("Should be in sync when there are no differences", func() {
+		status := utils.NewRsyncVerificationStatus("v1", `Number of deleted files: 0
+Number of regular files transferred: 0
+Total transferred file size: 0 bytes`)
+		Expect(*status.InSync).To(BeTrue())
+		Expect(*status.DifferingFiles).To(Equal(int64(0)))
+		Expect(*status.ExtraFiles).To(Equal(int64(0)))
+	})
+
+	It("Should report when the result can't be determined", func() {
+		status := utils.NewRsyncVerificationStatus("v1", "")
+		Expect(status.Tag).To(Equal("v1"))
+		Expect(status.InSync).To(BeNil())
+		Expect(status.Message).NotTo(BeEmpty())
+	})
+
+	Context("Block volumes", func() {
+		blockOutput := `2023-06-01T10:00:00.000Z	INFO	Verifying the target	{"range size": 67108864}
+diskrsync-progress: {"time":"2023-06-01T10:00:00Z","totalBytes":1073741824,"bytesHashed":0,` +
+			`"bytesSynchronized":0,"bytesSent":0,"percent":0}
+diskrsync-verify: {"match":false,"totalBytes":1073741824,"rangeSize":67108864,"mismatchedBytes":201326592,` +
+			`"mismatchedRanges":[{"offset":0,"length":67108864},{"offset":268435456,"length":134217728}]}
+2023-06-01T10:00:05.000Z	INFO	Successfully completed sync`
+
+		It("Should keep only the verification result", func() {
+			filteredLines, err := utils.FilterLogs(strings.NewReader(blockOutput), utils.LogLineFilterBlockVerify)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filteredLines).To(HavePrefix("diskrsync-verify: "))
+			Expect(strings.Count(filteredLines, "\n")).To(BeZero())
+		})
+
+		It("Should record the mismatched ranges", func() {
+			status := utils.NewBlockVerificationStatus("v1", `{"match":false,"totalBytes":1073741824,`+
+				`"rangeSize":67108864,"mismatchedBytes":201326592,"mismatchedRanges":`+
+				`[{"offset":0,"length":67108864},{"offset":268435456,"length":134217728}]}`)
+			Expect(status.Tag).To(Equal("v1"))
+			Expect(status.CompletionTime).NotTo(BeNil())
+			Expect(*status.InSync).To(BeFalse())
+			Expect(*status.DifferingBytes).To(Equal(int64(201326592)))
+			Expect(status.DifferingFiles).To(BeNil())
+			Expect(status.Message).To(Equal("mismatched byte ranges: 0-67108864, 268435456-402653184"))
+		})
+
+		It("Should be in sync when all the ranges match", func() {
+			status := utils.NewBlockVerificationStatus("v1",
+				`{"match":true,"totalBytes":1073741824,"rangeSize":67108864,"mismatchedBytes":0}`)
+			Expect(*status.InSync).To(BeTrue())
+			Expect(*status.DifferingBytes).To(BeZero())
+			Expect(status.Message).To(BeEmpty())
+		})
+
+		It("Should fail when the result can't be read", func() {
+			status := utils.NewBlockVerificationStatus("v1", "not json")
+			Expect(status.InSync).To(BeNil())
+			Expect(status.Message).To(HavePrefix("unable to read the result reported by the mover"))
+		})
+
+		It("Should fail when the mover didn't report a result", func() {
+			// The result printed in the logs isn't used
+			status := utils.NewBlockVerificationStatus("v1", "")
+			Expect(status.Tag).To(Equal("v1"))
+			Expect(status.InSync).To(BeNil())
+			Expect(status.DifferingBytes).To(BeNil())
+			Expect(status.Message).To(Equal("the mover did not report a result"))
+		})
+	})
+})
@@ -0,0 +1,258 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package volumehandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+const (
+	// Annotation used to record the capacity a volume needs, after the mover
+	// found it to be full
+	requiredCapacityAnnotation = utils.VolsyncLabelPrefix + "/required-capacity"
+	// How much a full volume grows when the size of the data it must hold isn't
+	// known, or is no larger than the volume
+	fullVolumeGrowthPercent = 50
+	// The margin added to the size of the data a full volume must hold, for the
+	// overhead of the filesystem
+	requiredCapacityMarginPercent = 10
+)
+
+// capacityForExistingPVC returns the capacity to request for pvc, which
+// already exists. Volumes can't shrink and can only be expanded once bound
+// and if their StorageClass allows it, so the current request is kept
+// otherwise. The returned reason is the VolumeExpansion condition reason that
+// applies, or "" if the requested capacity doesn't change.
+func (vh *VolumeHandler) capacityForExistingPVC(ctx context.Context, logger logr.Logger,
+	pvc *corev1.PersistentVolumeClaim) (resource.Quantity, string, error) {
+	requested := vh.requestedCapacity(logger, pvc)
+	current, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok || requested.Cmp(current) == 0 {
+		return requested, "", nil
+	}
+	if requested.Cmp(current) < 0 {
+		logger.Info("the capacity of an existing PVC cannot be reduced", "capacity", current.String(),
+			"requested", requested.String())
+		return current, "", nil
+	}
+	if pvc.Status.Phase != corev1.ClaimBound {
+		// Only bound PVCs may be expanded
+		return current, "", nil
+	}
+
+	allowed, err := vh.isExpansionAllowed(ctx, pvc)
+	if err != nil {
+		return current, "", err
+	}
+	if !allowed {
+		return current, volsyncv1alpha1.VolumeExpansionReasonNotAllowed, nil
+	}
+	logger.Info("expanding PVC", "capacity", current.String(), "requested", requested.String())
+	return requested, volsyncv1alpha1.VolumeExpansionReasonExpanding, nil
+}
+
+// requestedCapacity returns the capacity pvc should have: the capacity of the
+// owner, or the capacity the volume was found to need if that is larger
+func (vh *VolumeHandler) requestedCapacity(logger logr.Logger, pvc *corev1.PersistentVolumeClaim) resource.Quantity {
+	requested := *vh.capacity
+	value, ok := pvc.Annotations[requiredCapacityAnnotation]
+	if !ok {
+		return requested
+	}
+	required, err := resource.ParseQuantity(value)
+	if err != nil {
+		logger.Error(err, "ignoring invalid required capacity", "annotation", requiredCapacityAnnotation)
+		return requested
+	}
+	if required.Cmp(requested) > 0 {
+		return required
+	}
+	return requested
+}
+
+// ExpandFullPVC records that the mover found pvc to be full. required is the
+// size of the data the volume must hold, or nil if it isn't known. If VolSync
+// created the volume, the capacity it needs is recorded on it and it is
+// expanded the same way as when the capacity of the owner is increased.
+func (vh *VolumeHandler) ExpandFullPVC(ctx context.Context, logger logr.Logger,
+	pvc *corev1.PersistentVolumeClaim, required *resource.Quantity) error {
+	pvcName := utils.KindAndName(vh.client.Scheme(), pvc)
+	if pvc.Labels[utils.DataVolumeLabelKey] != "true" || vh.capacity == nil {
+		// The destinationPVC is managed by the user
+		vh.eventRecorder.Eventf(vh.owner, pvc, corev1.EventTypeWarning,
+			volsyncv1alpha1.EvRPVCFull, volsyncv1alpha1.EvANone, "%s is full", pvcName)
+		return nil
+	}
+
+	pending := vh.requestedCapacity(logger, pvc)
+	if current, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok && current.Cmp(pending) > 0 {
+		pending = current
+	}
+	actual, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	if !ok || actual.Cmp(pending) < 0 {
+		// The volume hasn't been expanded to the capacity it was found to need
+		// yet, the mover may have run out of space before it was
+		logger.Info("PVC is full while an expansion is pending", "capacity", actual.String(),
+			"requested", pending.String())
+		return nil
+	}
+
+	capacity := fullVolumeCapacity(actual, required)
+	if pvc.Annotations == nil {
+		pvc.Annotations = map[string]string{}
+	}
+	pvc.Annotations[requiredCapacityAnnotation] = capacity.String()
+	if err := vh.client.Update(ctx, pvc); err != nil {
+		logger.Error(err, "unable to record the required capacity of the PVC")
+		return err
+	}
+	logger.Info("PVC is full", "capacity", actual.String(), "required", capacity.String())
+	vh.eventRecorder.Eventf(vh.owner, pvc, corev1.EventTypeWarning,
+		volsyncv1alpha1.EvRPVCFull, volsyncv1alpha1.EvAExpandPVC, "%s is full, it needs a capacity of %s",
+		pvcName, capacity.String())
+	return nil
+}
+
+// fullVolumeCapacity returns the capacity of a volume of the given capacity
+// once it has been found to be full, rounded up to a whole MiB
+func fullVolumeCapacity(capacity resource.Quantity, required *resource.Quantity) resource.Quantity {
+	const mebibyte = 1024 * 1024
+
+	size := capacity.Value() * (100 + fullVolumeGrowthPercent) / 100
+	if required != nil {
+		withMargin := required.Value() * (100 + requiredCapacityMarginPercent) / 100
+		if withMargin > capacity.Value() {
+			size = withMargin
+		}
+	}
+	size = (size + mebibyte - 1) / mebibyte * mebibyte
+	return *resource.NewQuantity(size, resource.BinarySI)
+}
+
+// isExpansionAllowed returns true if the StorageClass of pvc allows volume
+// expansion
+func (vh *VolumeHandler) isExpansionAllowed(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+	sc := &storagev1.StorageClass{}
+	if err := vh.client.Get(ctx, types.NamespacedName{Name: *pvc.Spec.StorageClassName}, sc); err != nil {
+		return false, err
+	}
+	return sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion, nil
+}
+
+// updateExpansionStatus records the progress of the expansion of pvc in events
+// and the VolumeExpansion condition of the owner. reason is the one returned
+// by capacityForExistingPVC.
+func (vh *VolumeHandler) updateExpansionStatus(pvc *corev1.PersistentVolumeClaim, reason string) {
+	conditions := vh.ownerConditions()
+	pvcName := utils.KindAndName(vh.client.Scheme(), pvc)
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+
+	switch reason {
+	case volsyncv1alpha1.VolumeExpansionReasonExpanding:
+		message := fmt.Sprintf("expanding %s to %s", pvcName, requested.String())
+		vh.eventRecorder.Eventf(vh.owner, pvc, corev1.EventTypeNormal,
+			volsyncv1alpha1.EvRPVCExpanding, volsyncv1alpha1.EvAExpandPVC, "%s", message)
+		setExpansionCondition(conditions, metav1.ConditionTrue, reason, message)
+	case volsyncv1alpha1.VolumeExpansionReasonNotAllowed:
+		// An invalid annotation has already been logged by capacityForExistingPVC
+		capacity := vh.requestedCapacity(logr.Discard(), pvc)
+		message := fmt.Sprintf("unable to expand %s to %s: its StorageClass does not allow volume expansion",
+			pvcName, capacity.String())
+		// This is checked at every reconcile, only warn when it first happens
+		if !hasExpansionCondition(conditions, reason, message) {
+			vh.eventRecorder.Eventf(vh.owner, pvc, corev1.EventTypeWarning,
+				volsyncv1alpha1.EvRPVCNotExpanded, volsyncv1alpha1.EvAExpandPVC, "%s", message)
+		}
+		setExpansionCondition(conditions, metav1.ConditionFalse, reason, message)
+	default:
+		if conditions == nil {
+			return
+		}
+		// The condition is shared by all the PVCs of the owner, so only the
+		// PVC it refers to may clear it
+		cond := apimeta.FindStatusCondition(*conditions, volsyncv1alpha1.ConditionVolumeExpansion)
+		if cond == nil || !strings.Contains(cond.Message, pvcName+" ") {
+			return
+		}
+		if cond.Reason == volsyncv1alpha1.VolumeExpansionReasonExpanding {
+			actual, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+			if !ok || actual.Cmp(requested) < 0 {
+				return // still expanding
+			}
+			vh.eventRecorder.Eventf(vh.owner, pvc, corev1.EventTypeNormal,
+				volsyncv1alpha1.EvRPVCExpanded, volsyncv1alpha1.EvANone,
+				"expanded %s to %s", pvcName, actual.String())
+		}
+		apimeta.RemoveStatusCondition(conditions, volsyncv1alpha1.ConditionVolumeExpansion)
+	}
+}
+
+func setExpansionCondition(conditions *[]metav1.Condition, status metav1.ConditionStatus,
+	reason string, message string) {
+	if conditions == nil {
+		return
+	}
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    volsyncv1alpha1.ConditionVolumeExpansion,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// hasExpansionCondition returns true if the VolumeExpansion condition is
+// already set with reason and message
+func hasExpansionCondition(conditions *[]metav1.Condition, reason string, message string) bool {
+	if conditions == nil {
+		return false
+	}
+	cond := apimeta.FindStatusCondition(*conditions, volsyncv1alpha1.ConditionVolumeExpansion)
+	return cond != nil && cond.Reason == reason && cond.Message == message
+}
+
+// ownerConditions returns the status conditions of the owner, or nil if it
+// has none
+func (vh *VolumeHandler) ownerConditions() *[]metav1.Condition {
+	switch owner := vh.owner.(type) {
+	case *volsyncv1alpha1.ReplicationSource:
+		if owner.Status != nil {
+			return &owner.Status.Conditions
+		}
+	case *volsyncv1alpha1.ReplicationDestination:
+		if owner.Status != nil {
+			return &owner.Status.Conditions
+		}
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package volumehandler
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;delete
+
+const (
+	// The filesystem is never kept frozen for longer than this, even if the
+	// snapshot has not been taken yet
+	freezeTimeout = 30 * time.Second
+	// DefaultKubeletRootDir is the root directory of the kubelet on most nodes
+	DefaultKubeletRootDir = "/var/lib/kubelet"
+	// The freeze helper creates this file once the filesystem is frozen
+	frozenMarkerDir  = "/tmp"
+	frozenMarkerFile = "frozen"
+)
+
+// FreezeContainerImage is the container image of the helper that freezes the
+// filesystem of a volume while it is snapshotted
+var FreezeContainerImage string
+
+// KubeletRootDir is the root directory of the kubelet on the nodes, the
+// volumes of the pods are mounted below it
+var KubeletRootDir = DefaultKubeletRootDir
+
+// ensureFrozenSnapshot ensures a snapshot of src that is taken while the
+// filesystem of src is frozen on the node that mounts it. Returns nil, nil
+// while the freeze or the snapshot is still in progress.
+//
+//nolint:funlen
+func (vh *VolumeHandler) ensureFrozenSnapshot(ctx context.Context, log logr.Logger,
+	src *corev1.PersistentVolumeClaim, name string, isTemporary bool) (*snapv1.VolumeSnapshot, error) {
+	freezePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-freeze",
+			Namespace: vh.owner.GetNamespace(),
+		},
+	}
+	logger := log.WithValues("freezePod", client.ObjectKeyFromObject(freezePod))
+
+	snap := &snapv1.VolumeSnapshot{}
+	err := vh.client.Get(ctx, types.NamespacedName{Name: name, Namespace: vh.owner.GetNamespace()}, snap)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	snapExists := err == nil
+	if snapExists && isSnapshotTaken(snap) {
+		// The point-in-time image has been taken, the filesystem can be unfrozen
+		if err := vh.deleteFreezePod(ctx, logger, freezePod); err != nil {
+			return nil, err
+		}
+		return vh.ensureSnapshot(ctx, log, src, name, isTemporary)
+	}
+
+	err = vh.client.Get(ctx, client.ObjectKeyFromObject(freezePod), freezePod)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	if kerrors.IsNotFound(err) {
+		mountingPod, err := vh.getPodMountingPVC(ctx, logger, src)
+		if err != nil {
+			return nil, err
+		}
+		if mountingPod == nil {
+			// If no pod is using src, the filesystem isn't mounted and there is nothing to freeze
+			logger.V(1).Info("volume is not mounted, no need to freeze it")
+			return vh.ensureSnapshot(ctx, log, src, name, isTemporary)
+		}
+		if snapExists {
+			// The snapshot is not guaranteed to be consistent if the filesystem isn't frozen anymore
+			logger.Info("freeze pod is gone before the snapshot was taken, retrying")
+			return nil, client.IgnoreNotFound(vh.client.Delete(ctx, snap))
+		}
+		return nil, vh.startFreeze(ctx, logger, src, mountingPod, freezePod)
+	}
+
+	if freezePod.Status.Phase == corev1.PodFailed || freezePod.Status.Phase == corev1.PodSucceeded {
+		// Either the filesystem could not be frozen, or the freeze timed out before the snapshot was taken
+		if snapExists {
+			if err := vh.client.Delete(ctx, snap); client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+		}
+		if freezePod.Status.Phase == corev1.PodFailed {
+			// The helper may have been killed before it could unfreeze the filesystem
+			unfrozen, err := vh.ensureUnfrozen(ctx, logger, freezePod, name+"-unfreeze")
+			if !unfrozen || err != nil {
+				return nil, err
+			}
+		}
+		vh.eventRecorder.Eventf(vh.owner, freezePod, corev1.EventTypeWarning,
+			volsyncv1alpha1.EvRFSFreezeFailed, volsyncv1alpha1.EvAFreezeFS,
+			"unable to take a snapshot of %s while the filesystem is frozen, retrying",
+			utils.KindAndName(vh.client.Scheme(), src))
+		return nil, vh.deleteFreezePod(ctx, logger, freezePod)
+	}
+	if !isPodReady(freezePod) {
+		logger.V(1).Info("waiting for the filesystem to be frozen")
+		return nil, nil
+	}
+
+	// The filesystem is frozen, take the snapshot
+	snap, err = vh.ensureSnapshot(ctx, log, src, name, isTemporary)
+	if snap == nil || err != nil || !isSnapshotTaken(snap) {
+		return nil, err
+	}
+	if err := vh.deleteFreezePod(ctx, logger, freezePod); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// startFreeze creates the pod that freezes the filesystem of src on the node
+// of mountingPod, which is using src
+func (vh *VolumeHandler) startFreeze(ctx context.Context, logger logr.Logger,
+	src *corev1.PersistentVolumeClaim, mountingPod, freezePod *corev1.Pod) error {
+	privileged, err := utils.PrivilegedMoversOk(ctx, vh.client, logger, vh.owner.GetNamespace())
+	if err != nil {
+		return err
+	}
+	if !privileged {
+		return fmt.Errorf("copyMethod %s requires the %s annotation on the namespace",
+			volsyncv1alpha1.CopyMethodFreezeSnapshot, volsyncv1alpha1.PrivilegedMoversNamespaceAnnotation)
+	}
+
+	if src.Spec.VolumeMode != nil && *src.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		return fmt.Errorf("copyMethod %s requires a volume with volumeMode %s",
+			volsyncv1alpha1.CopyMethodFreezeSnapshot, corev1.PersistentVolumeFilesystem)
+	}
+	pv := &corev1.PersistentVolume{}
+	if err := vh.client.Get(ctx, types.NamespacedName{Name: src.Spec.VolumeName}, pv); err != nil {
+		return err
+	}
+	if pv.Spec.CSI == nil {
+		return fmt.Errorf("copyMethod %s requires a volume provisioned by a CSI driver",
+			volsyncv1alpha1.CopyMethodFreezeSnapshot)
+	}
+	// This is where the kubelet mounts the CSI volume for the pod
+	mountPath := path.Join(KubeletRootDir, "pods", string(mountingPod.GetUID()), "volumes",
+		"kubernetes.io~csi", pv.GetName(), "mount")
+
+	if err := ctrl.SetControllerReference(vh.owner, freezePod, vh.client.Scheme()); err != nil {
+		logger.Error(err, utils.ErrUnableToSetControllerRef)
+		return err
+	}
+	utils.SetOwnedByVolSync(freezePod)
+	freezePod.Spec = corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name:    "freeze",
+			Image:   FreezeContainerImage,
+			Command: []string{"/bin/bash", "-c", "/mover-freeze/freeze.sh"},
+			Env: []corev1.EnvVar{
+				{Name: "MOUNT_PATH", Value: mountPath},
+				{Name: "FREEZE_TIMEOUT", Value: strconv.Itoa(int(freezeTimeout.Seconds()))},
+				{Name: "FROZEN_MARKER", Value: path.Join(frozenMarkerDir, frozenMarkerFile)},
+			},
+			ReadinessProbe: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					Exec: &corev1.ExecAction{
+						Command: []string{"test", "-f", path.Join(frozenMarkerDir, frozenMarkerFile)},
+					},
+				},
+				PeriodSeconds: 1,
+			},
+			SecurityContext: &corev1.SecurityContext{
+				// fsfreeze needs CAP_SYS_ADMIN in the host's mount namespace
+				Privileged:             ptr.To(true),
+				RunAsUser:              ptr.To[int64](0),
+				ReadOnlyRootFilesystem: ptr.To(true),
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:             "kubelet",
+					MountPath:        KubeletRootDir,
+					MountPropagation: ptr.To(corev1.MountPropagationHostToContainer),
+				},
+				{Name: "tempdir", MountPath: frozenMarkerDir},
+			},
+		}},
+		// Run next to the pod that mounts the volume
+		NodeName:    mountingPod.Spec.NodeName,
+		Tolerations: mountingPod.Spec.Tolerations,
+		// The helper unfreezes when it is terminated, this is a safeguard in case it doesn't
+		ActiveDeadlineSeconds:        ptr.To(int64(2 * freezeTimeout.Seconds())),
+		AutomountServiceAccountToken: ptr.To(false),
+		RestartPolicy:                corev1.RestartPolicyNever,
+		Volumes: []corev1.Volume{
+			{Name: "kubelet", VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: KubeletRootDir,
+					Type: ptr.To(corev1.HostPathDirectory),
+				}},
+			},
+			{Name: "tempdir", VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumMemory,
+				}},
+			},
+		},
+	}
+
+	logger.Info("creating pod to freeze the filesystem", "node", mountingPod.Spec.NodeName)
+	if err := vh.client.Create(ctx, freezePod); err != nil {
+		return err
+	}
+	vh.eventRecorder.Eventf(vh.owner, freezePod, corev1.EventTypeNormal,
+		volsyncv1alpha1.EvRFSFrozen, volsyncv1alpha1.EvAFreezeFS,
+		"freezing the filesystem of %s on node %s to take a snapshot",
+		utils.KindAndName(vh.client.Scheme(), src), mountingPod.Spec.NodeName)
+	return nil
+}
+
+// ensureUnfrozen runs a pod that unfreezes the filesystem that the failed
+// freezePod was freezing. Returns true once the filesystem is unfrozen.
+func (vh *VolumeHandler) ensureUnfrozen(ctx context.Context, logger logr.Logger,
+	freezePod *corev1.Pod, name string) (bool, error) {
+	unfreezePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: freezePod.GetNamespace(),
+		},
+	}
+	logger = logger.WithValues("unfreezePod", client.ObjectKeyFromObject(unfreezePod))
+
+	err := vh.client.Get(ctx, client.ObjectKeyFromObject(unfreezePod), unfreezePod)
+	if client.IgnoreNotFound(err) != nil {
+		return false, err
+	}
+	if kerrors.IsNotFound(err) {
+		if err := ctrl.SetControllerReference(vh.owner, unfreezePod, vh.client.Scheme()); err != nil {
+			logger.Error(err, utils.ErrUnableToSetControllerRef)
+			return false, err
+		}
+		utils.SetOwnedByVolSync(unfreezePod)
+		// Same node, mounts and privileges as the freeze helper
+		unfreezePod.Spec = *freezePod.Spec.DeepCopy()
+		unfreezePod.Spec.Containers[0].Name = "unfreeze"
+		unfreezePod.Spec.Containers[0].Command = []string{"/bin/bash", "-c", "/mover-freeze/unfreeze.sh"}
+		unfreezePod.Spec.Containers[0].ReadinessProbe = nil
+		unfreezePod.Spec.ActiveDeadlineSeconds = ptr.To(int64(freezeTimeout.Seconds()))
+
+		logger.Info("creating pod to unfreeze the filesystem", "node", unfreezePod.Spec.NodeName)
+		return false, vh.client.Create(ctx, unfreezePod)
+	}
+
+	switch unfreezePod.Status.Phase {
+	case corev1.PodSucceeded:
+		err := client.IgnoreNotFound(vh.client.Delete(ctx, unfreezePod,
+			client.PropagationPolicy(metav1.DeletePropagationBackground)))
+		return err == nil, err
+	case corev1.PodFailed:
+		vh.eventRecorder.Eventf(vh.owner, unfreezePod, corev1.EventTypeWarning,
+			volsyncv1alpha1.EvRFSFreezeFailed, volsyncv1alpha1.EvAFreezeFS,
+			"unable to unfreeze the filesystem on node %s, retrying", unfreezePod.Spec.NodeName)
+		// Try again with a new pod
+		err := vh.client.Delete(ctx, unfreezePod, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if client.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+		return false, fmt.Errorf("unable to unfreeze the filesystem on node %s", unfreezePod.Spec.NodeName)
+	default:
+		logger.V(1).Info("waiting for the filesystem to be unfrozen")
+		return false, nil
+	}
+}
+
+func (vh *VolumeHandler) deleteFreezePod(ctx context.Context, logger logr.Logger, freezePod *corev1.Pod) error {
+	// Terminating the helper unfreezes the filesystem
+	err := vh.client.Delete(ctx, freezePod, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err == nil {
+		logger.V(1).Info("deleted freeze pod")
+	}
+	return client.IgnoreNotFound(err)
+}
+
+// getPodMountingPVC returns a running pod that is using the PVC, or nil if
+// there is none. Returns an error if the PVC is mounted on several nodes, as
+// the filesystem can only be frozen on one of them.
+func (vh *VolumeHandler) getPodMountingPVC(ctx context.Context, logger logr.Logger,
+	pvc *corev1.PersistentVolumeClaim) (*corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := vh.client.List(ctx, podList, client.InNamespace(pvc.GetNamespace())); err != nil {
+		return nil, err
+	}
+	var mountingPod *corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.Spec.NodeName == "" || utils.IsOwnedByVolsync(pod) {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != pvc.GetName() {
+				continue
+			}
+			if mountingPod != nil && mountingPod.Spec.NodeName != pod.Spec.NodeName {
+				err := fmt.Errorf("copyMethod %s requires a volume that is mounted on a single node, "+
+					"%s is mounted on nodes %s and %s", volsyncv1alpha1.CopyMethodFreezeSnapshot,
+					utils.KindAndName(vh.client.Scheme(), pvc), mountingPod.Spec.NodeName, pod.Spec.NodeName)
+				logger.Error(err, "unable to freeze the filesystem")
+				vh.eventRecorder.Eventf(vh.owner, pvc, corev1.EventTypeWarning,
+					volsyncv1alpha1.EvRFSFreezeFailed, volsyncv1alpha1.EvAFreezeFS,
+					"unable to freeze the filesystem: %s", err)
+				return nil, err
+			}
+			mountingPod = pod
+		}
+	}
+	return mountingPod, nil
+}
+
+// isSnapshotTaken returns true once the point-in-time image of the snapshot
+// has been cut, even if it isn't ready to use yet
+func isSnapshotTaken(snap *snapv1.VolumeSnapshot) bool {
+	return snap.Status != nil && snap.Status.CreationTime != nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -95,6 +95,14 @@ func FromDestination(d *volsyncv1alpha1.ReplicationDestinationVolumeOptions) VHO
 	}
 }
 
+// SourceSnapshot specifies the existing VolumeSnapshots to replicate instead of
+// a source PVC
+func SourceSnapshot(s *volsyncv1alpha1.ReplicationSourceSnapshotSpec) VHOption {
+	return func(vh *VolumeHandler) {
+		vh.sourceSnapshot = s
+	}
+}
+
 func AccessModes(am []corev1.PersistentVolumeAccessMode) VHOption {
 	return func(vh *VolumeHandler) {
 		vh.accessModes = am
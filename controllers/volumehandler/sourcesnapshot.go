@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package volumehandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+// Annotation of a PVC restored from a source snapshot, recording the UID of the
+// snapshot until the synchronization that replicates it completes
+const sourceSnapshotUIDAnnotation = utils.VolsyncLabelPrefix + "/source-snapshot-uid"
+
+// ErrSourceSnapshotReplicated is returned by EnsurePVCFromSourceSnapshot when
+// the selected snapshot is the one that was replicated last
+var ErrSourceSnapshotReplicated = errors.New("the source snapshot has already been replicated")
+
+// UsesSourceSnapshot returns true if the VolumeHandler replicates existing
+// VolumeSnapshots instead of a source PVC
+func (vh *VolumeHandler) UsesSourceSnapshot() bool {
+	return vh.sourceSnapshot != nil
+}
+
+// EnsurePVCFromSourceSnapshot ensures the presence of a PVC that is restored
+// from the newest VolumeSnapshot selected by the VolumeHandler's
+// sourceSnapshot. The snapshot is only selected when the PVC is created, so
+// the same snapshot is used until the PVC is cleaned up. If replicate is set,
+// the snapshot is recorded in the owner's status by RecordReplicatedSourceSnapshot
+// once the synchronization completes, and ErrSourceSnapshotReplicated is
+// returned instead if the selected snapshot is the one replicated last.
+func (vh *VolumeHandler) EnsurePVCFromSourceSnapshot(ctx context.Context, log logr.Logger,
+	name string, isTemporary bool, replicate bool) (*corev1.PersistentVolumeClaim, error) {
+	pvc, err := vh.getPVCByName(ctx, name)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	if err == nil {
+		log.V(1).Info("pvc from source snapshot already exists", "pvc", client.ObjectKeyFromObject(pvc))
+		return pvc, nil
+	}
+
+	snap, err := vh.selectSourceSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger := log.WithValues("sourceSnapshot", client.ObjectKeyFromObject(snap))
+
+	rs, ok := vh.owner.(*volsyncv1alpha1.ReplicationSource)
+	if !ok {
+		return nil, errors.New("sourceSnapshot is only supported by a ReplicationSource")
+	}
+	if rs.Status == nil {
+		rs.Status = &volsyncv1alpha1.ReplicationSourceStatus{}
+	}
+	if last := rs.Status.LastSourceSnapshot; replicate && last != nil && last.UID == snap.GetUID() {
+		logger.Info("source snapshot has not changed since it was replicated")
+		return nil, ErrSourceSnapshotReplicated
+	}
+
+	// The PVC the snapshot was taken from provides the defaults of the new
+	// PVC, but it may not exist anymore
+	var original *corev1.PersistentVolumeClaim
+	if snap.Spec.Source.PersistentVolumeClaimName != nil {
+		original, err = vh.getPVCByName(ctx, *snap.Spec.Source.PersistentVolumeClaimName)
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		if err != nil {
+			original = nil
+		}
+	}
+
+	vh.volumeMode = corev1.PersistentVolumeFilesystem
+	if original != nil && original.Spec.VolumeMode != nil {
+		vh.volumeMode = *original.Spec.VolumeMode
+	} else if snap.Status.BoundVolumeSnapshotContentName != nil {
+		content := &snapv1.VolumeSnapshotContent{}
+		err := vh.client.Get(ctx, types.NamespacedName{Name: *snap.Status.BoundVolumeSnapshotContentName}, content)
+		if err != nil {
+			return nil, err
+		}
+		if content.Spec.SourceVolumeMode != nil {
+			vh.volumeMode = *content.Spec.SourceVolumeMode
+		}
+	}
+
+	logger.Info("restoring the source volume from an existing snapshot")
+	var annotations map[string]string
+	if replicate {
+		annotations = map[string]string{sourceSnapshotUIDAnnotation: string(snap.GetUID())}
+	}
+	return vh.pvcFromSnapshot(ctx, logger, snap, original, name, isTemporary, annotations)
+}
+
+// RecordReplicatedSourceSnapshot records the source snapshot replicated by the
+// synchronization of rs in its status. It must only be called once the
+// synchronization has completed, before the PVC restored from the snapshot is
+// cleaned up.
+func RecordReplicatedSourceSnapshot(ctx context.Context, c client.Client, rs *volsyncv1alpha1.ReplicationSource) error {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := c.List(ctx, pvcList, client.InNamespace(rs.GetNamespace())); err != nil {
+		return err
+	}
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		uid, ok := pvc.Annotations[sourceSnapshotUIDAnnotation]
+		if !ok || !metav1.IsControlledBy(pvc, rs) || pvc.Spec.DataSource == nil {
+			continue
+		}
+		rs.Status.LastSourceSnapshot = &volsyncv1alpha1.ReplicationSourceSnapshotStatus{
+			Name: pvc.Spec.DataSource.Name,
+			UID:  types.UID(uid),
+		}
+	}
+	return nil
+}
+
+// selectSourceSnapshot returns the VolumeSnapshot selected by the
+// VolumeHandler's sourceSnapshot, or an error if none is ready to use
+func (vh *VolumeHandler) selectSourceSnapshot(ctx context.Context) (*snapv1.VolumeSnapshot, error) {
+	if vh.sourceSnapshot.Name != nil {
+		snap := &snapv1.VolumeSnapshot{}
+		err := vh.client.Get(ctx, types.NamespacedName{
+			Name:      *vh.sourceSnapshot.Name,
+			Namespace: vh.owner.GetNamespace(),
+		}, snap)
+		if err != nil {
+			return nil, err
+		}
+		if !isSnapshotReady(snap) {
+			return nil, fmt.Errorf("source snapshot %s is not ready to use", snap.GetName())
+		}
+		return snap, nil
+	}
+
+	if vh.sourceSnapshot.Selector == nil {
+		return nil, errors.New("sourceSnapshot must specify either a name or a selector")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(vh.sourceSnapshot.Selector)
+	if err != nil {
+		return nil, err
+	}
+	snapList := &snapv1.VolumeSnapshotList{}
+	err = vh.client.List(ctx, snapList, client.InNamespace(vh.owner.GetNamespace()),
+		client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var newest *snapv1.VolumeSnapshot
+	for i := range snapList.Items {
+		snap := &snapList.Items[i]
+		if !isSnapshotReady(snap) {
+			continue
+		}
+		if newest == nil || newest.Status.CreationTime.Before(snap.Status.CreationTime) {
+			newest = snap
+		}
+	}
+	if newest == nil {
+		return nil, fmt.Errorf("no source snapshot matching %q is ready to use", selector.String())
+	}
+	return newest, nil
+}
+
+// isSnapshotReady returns true if the snapshot has been taken and can be used
+// to restore a volume
+func isSnapshotReady(snap *snapv1.VolumeSnapshot) bool {
+	return isSnapshotTaken(snap) && snap.Status.ReadyToUse != nil && *snap.Status.ReadyToUse &&
+		snap.DeletionTimestamp.IsZero()
+}
@@ -89,6 +89,7 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	// Don't start the controllers. We're testing volumehandler directly
+	FreezeContainerImage = "quay.io/backube/volsync:latest"
 
 	go func() {
 		defer GinkgoRecover()
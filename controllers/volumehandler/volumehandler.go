@@ -56,6 +56,7 @@ type VolumeHandler struct {
 	accessModes             []corev1.PersistentVolumeAccessMode
 	volumeMode              corev1.PersistentVolumeMode
 	volumeSnapshotClassName *string
+	sourceSnapshot          *volsyncv1alpha1.ReplicationSourceSnapshotSpec
 }
 
 // EnsurePVCFromSrc ensures the presence of a PVC that is based on the provided
@@ -81,9 +82,16 @@ func (vh *VolumeHandler) EnsurePVCFromSrc(ctx context.Context, log logr.Logger,
 		if snap == nil || err != nil {
 			return nil, err
 		}
-		return vh.pvcFromSnapshot(ctx, log, snap, src, name, isTemporary)
+		return vh.pvcFromSnapshot(ctx, log, snap, src, name, isTemporary, nil)
+	case volsyncv1alpha1.CopyMethodFreezeSnapshot:
+		snap, err := vh.ensureFrozenSnapshot(ctx, log, src, name, isTemporary)
+		if snap == nil || err != nil {
+			return nil, err
+		}
+		return vh.pvcFromSnapshot(ctx, log, snap, src, name, isTemporary, nil)
 	default:
-		return nil, fmt.Errorf("unsupported copyMethod: %v -- must be Direct, None, Clone, or Snapshot", vh.copyMethod)
+		return nil, fmt.Errorf("unsupported copyMethod: %v -- must be Direct, None, Clone, Snapshot, or FreezeSnapshot",
+			vh.copyMethod)
 	}
 }
 
@@ -146,9 +154,22 @@ func (vh *VolumeHandler) getPVCByName(ctx context.Context, pvcName string) (*cor
 	return pvc, err
 }
 
-// nolint: funlen
 func (vh *VolumeHandler) EnsureNewPVC(ctx context.Context, log logr.Logger,
 	name string) (*corev1.PersistentVolumeClaim, error) {
+	return vh.ensureNewPVC(ctx, log, name, false)
+}
+
+// EnsureNewDataPVC is like EnsureNewPVC, for the volume that holds the
+// replicated data of the owner. It is kept when the owner is deleted with a
+// deletion policy that retains its volumes.
+func (vh *VolumeHandler) EnsureNewDataPVC(ctx context.Context, log logr.Logger,
+	name string) (*corev1.PersistentVolumeClaim, error) {
+	return vh.ensureNewPVC(ctx, log, name, true)
+}
+
+// nolint: funlen
+func (vh *VolumeHandler) ensureNewPVC(ctx context.Context, log logr.Logger,
+	name string, isDataVolume bool) (*corev1.PersistentVolumeClaim, error) {
 	logger := log.WithValues("PVC", name)
 
 	// Ensure required configuration parameters have been provided in order to
@@ -171,21 +192,32 @@ func (vh *VolumeHandler) EnsureNewPVC(ctx context.Context, log logr.Logger,
 		},
 	}
 
+	expansionReason := ""
 	op, err := ctrlutil.CreateOrUpdate(ctx, vh.client, pvc, func() error {
 		if err := ctrl.SetControllerReference(vh.owner, pvc, vh.client.Scheme()); err != nil {
 			logger.Error(err, utils.ErrUnableToSetControllerRef)
 			return err
 		}
 		utils.SetOwnedByVolSync(pvc)
+		if isDataVolume {
+			utils.AddLabel(pvc, utils.DataVolumeLabelKey, "true")
+		}
+		capacity := *vh.capacity
 		if pvc.CreationTimestamp.IsZero() { // set immutable fields
 			pvc.Spec.AccessModes = vh.accessModes
 			pvc.Spec.StorageClassName = vh.storageClassName
 			volumeMode := corev1.PersistentVolumeFilesystem
 			pvc.Spec.VolumeMode = &volumeMode
+		} else {
+			var err error
+			capacity, expansionReason, err = vh.capacityForExistingPVC(ctx, logger, pvc)
+			if err != nil {
+				return err
+			}
 		}
 
 		pvc.Spec.Resources.Requests = corev1.ResourceList{
-			corev1.ResourceStorage: *vh.capacity,
+			corev1.ResourceStorage: capacity,
 		}
 		return nil
 	})
@@ -201,6 +233,7 @@ func (vh *VolumeHandler) EnsureNewPVC(ctx context.Context, log logr.Logger,
 			"created %s to receive incoming data",
 			utils.KindAndName(vh.client.Scheme(), pvc))
 	}
+	vh.updateExpansionStatus(pvc, expansionReason)
 	if pvc.Status.Phase != corev1.ClaimBound &&
 		!pvc.CreationTimestamp.IsZero() &&
 		pvc.CreationTimestamp.Add(mover.PVCBindTimeout).Before(time.Now()) {
@@ -459,7 +492,7 @@ func (vh *VolumeHandler) ensureSnapshot(ctx context.Context, log logr.Logger,
 // nolint: funlen
 func (vh *VolumeHandler) pvcFromSnapshot(ctx context.Context, log logr.Logger,
 	snap *snapv1.VolumeSnapshot, original *corev1.PersistentVolumeClaim,
-	name string, isTemporary bool) (*corev1.PersistentVolumeClaim, error) {
+	name string, isTemporary bool, annotations map[string]string) (*corev1.PersistentVolumeClaim, error) {
 	pvc := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -478,6 +511,12 @@ func (vh *VolumeHandler) pvcFromSnapshot(ctx context.Context, log logr.Logger,
 			utils.MarkForCleanup(vh.owner, pvc)
 		}
 		if pvc.CreationTimestamp.IsZero() {
+			for key, value := range annotations {
+				if pvc.Annotations == nil {
+					pvc.Annotations = map[string]string{}
+				}
+				pvc.Annotations[key] = value
+			}
 			if vh.capacity != nil {
 				pvc.Spec.Resources.Requests = corev1.ResourceList{
 					corev1.ResourceStorage: *vh.capacity,
@@ -486,6 +525,10 @@ func (vh *VolumeHandler) pvcFromSnapshot(ctx context.Context, log logr.Logger,
 				pvc.Spec.Resources.Requests = corev1.ResourceList{
 					corev1.ResourceStorage: *snap.Status.RestoreSize,
 				}
+			} else if original == nil {
+				// An existing snapshot may outlive the PVC it was taken from
+				return fmt.Errorf("unable to determine the capacity of a volume restored from %s",
+					utils.KindAndName(vh.client.Scheme(), snap))
 			} else if original.Status.Capacity != nil && original.Status.Capacity.Storage() != nil {
 				// check the original PVC capacity if set
 				pvc.Spec.Resources.Requests = corev1.ResourceList{
@@ -499,13 +542,15 @@ func (vh *VolumeHandler) pvcFromSnapshot(ctx context.Context, log logr.Logger,
 			}
 			if vh.storageClassName != nil {
 				pvc.Spec.StorageClassName = vh.storageClassName
-			} else {
+			} else if original != nil {
 				pvc.Spec.StorageClassName = original.Spec.StorageClassName
 			}
 			if vh.accessModes != nil {
 				pvc.Spec.AccessModes = vh.accessModes
-			} else {
+			} else if original != nil {
 				pvc.Spec.AccessModes = original.Spec.AccessModes
+			} else {
+				pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
 			}
 			pvc.Spec.VolumeMode = &vh.volumeMode
 			pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
@@ -539,6 +584,7 @@ func (vh *VolumeHandler) pvcFromSnapshot(ctx context.Context, log logr.Logger,
 }
 
 func (vh *VolumeHandler) IsCopyMethodDirect() bool {
-	return vh.copyMethod == volsyncv1alpha1.CopyMethodDirect ||
-		vh.copyMethod == volsyncv1alpha1.CopyMethodNone
+	// The copyMethod doesn't apply when replicating existing snapshots
+	return !vh.UsesSourceSnapshot() && (vh.copyMethod == volsyncv1alpha1.CopyMethodDirect ||
+		vh.copyMethod == volsyncv1alpha1.CopyMethodNone)
 }
@@ -19,14 +19,20 @@ package volumehandler
 
 import (
 	"context"
+	"time"
 
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -108,6 +114,164 @@ var _ = Describe("Volumehandler", func() {
 				Expect(*newPVC.Spec.StorageClassName).To(Equal(customSC))
 				Expect(*(newPVC.Spec.Resources.Requests.Storage())).To(Equal((capacity)))
 				Expect(newPVC.Name).To(Equal(pvcName))
+				Expect(newPVC.Labels).NotTo(HaveKey(utils.DataVolumeLabelKey))
+			})
+
+			It("marks the PVC receiving the data as such", func() {
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithOwner(rd),
+					FromDestination(&rd.Spec.Rsync.ReplicationDestinationVolumeOptions),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				newPVC, err := vh.EnsureNewDataPVC(ctx, logger, "thepvc")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newPVC.Labels).To(HaveKeyWithValue(utils.DataVolumeLabelKey, "true"))
+			})
+		})
+
+		When("the capacity of an existing PVC changes", func() {
+			var sc *storagev1.StorageClass
+			var pvc *corev1.PersistentVolumeClaim
+			var allowExpansion bool
+			var recorder *events.FakeRecorder
+			capacity := resource.MustParse("7Gi")
+			BeforeEach(func() {
+				allowExpansion = true
+			})
+			JustBeforeEach(func() {
+				sc = &storagev1.StorageClass{
+					ObjectMeta: metav1.ObjectMeta{
+						GenerateName: "expansion-",
+					},
+					Provisioner:          "fake.csi.driver",
+					AllowVolumeExpansion: &allowExpansion,
+				}
+				Expect(k8sClient.Create(ctx, sc)).To(Succeed())
+				DeferCleanup(k8sClient.Delete, ctx, sc)
+
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithOwner(rd),
+					Capacity(&capacity),
+					StorageClassName(&sc.Name),
+					AccessModes(rd.Spec.Rsync.AccessModes),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				pvc, err = vh.EnsureNewDataPVC(ctx, logger, "thepvc")
+				Expect(err).NotTo(HaveOccurred())
+				pvc.Status.Phase = corev1.ClaimBound
+				pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: capacity}
+				Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+				rd.Status = &volsyncv1alpha1.ReplicationDestinationStatus{}
+				recorder = events.NewFakeRecorder(10)
+			})
+			ensurePVCWithCapacity := func(newCapacity resource.Quantity) *corev1.PersistentVolumeClaim {
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithRecorder(recorder),
+					WithOwner(rd),
+					Capacity(&newCapacity),
+					StorageClassName(&sc.Name),
+					AccessModes(rd.Spec.Rsync.AccessModes),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				newPVC, err := vh.EnsureNewDataPVC(ctx, logger, pvc.Name)
+				Expect(err).NotTo(HaveOccurred())
+				return newPVC
+			}
+			reportFull := func(required *resource.Quantity) {
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithRecorder(recorder),
+					WithOwner(rd),
+					Capacity(&capacity),
+					StorageClassName(&sc.Name),
+					AccessModes(rd.Spec.Rsync.AccessModes),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+				Expect(vh.ExpandFullPVC(ctx, logger, pvc, required)).To(Succeed())
+			}
+
+			It("is expanded when the capacity grows", func() {
+				newCapacity := resource.MustParse("10Gi")
+				newPVC := ensurePVCWithCapacity(newCapacity)
+				Expect(*newPVC.Spec.Resources.Requests.Storage()).To(Equal(newCapacity))
+				cond := apimeta.FindStatusCondition(rd.Status.Conditions, volsyncv1alpha1.ConditionVolumeExpansion)
+				Expect(cond).NotTo(BeNil())
+				Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+				Expect(cond.Reason).To(Equal(volsyncv1alpha1.VolumeExpansionReasonExpanding))
+
+				// The condition stays until the volume has been resized
+				newPVC = ensurePVCWithCapacity(newCapacity)
+				Expect(apimeta.FindStatusCondition(rd.Status.Conditions,
+					volsyncv1alpha1.ConditionVolumeExpansion)).NotTo(BeNil())
+				newPVC.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: newCapacity}
+				Expect(k8sClient.Status().Update(ctx, newPVC)).To(Succeed())
+				ensurePVCWithCapacity(newCapacity)
+				Expect(apimeta.FindStatusCondition(rd.Status.Conditions,
+					volsyncv1alpha1.ConditionVolumeExpansion)).To(BeNil())
+			})
+
+			It("is expanded to hold the data when the mover finds it full", func() {
+				required := resource.MustParse("9Gi")
+				reportFull(&required)
+				Expect(recorder.Events).To(HaveLen(1))
+
+				// 10% more than the data, rounded up to a MiB
+				expected := resource.MustParse("10138Mi")
+				newPVC := ensurePVCWithCapacity(capacity)
+				Expect(*newPVC.Spec.Resources.Requests.Storage()).To(Equal(expected))
+				cond := apimeta.FindStatusCondition(rd.Status.Conditions, volsyncv1alpha1.ConditionVolumeExpansion)
+				Expect(cond).NotTo(BeNil())
+				Expect(cond.Reason).To(Equal(volsyncv1alpha1.VolumeExpansionReasonExpanding))
+
+				// It doesn't grow again until it has been expanded
+				reportFull(&required)
+				Expect(recorder.Events).To(HaveLen(2))
+				Expect(*ensurePVCWithCapacity(capacity).Spec.Resources.Requests.Storage()).To(Equal(expected))
+			})
+
+			It("grows by half when the mover finds it full without knowing the size of the data", func() {
+				reportFull(nil)
+				newPVC := ensurePVCWithCapacity(capacity)
+				Expect(*newPVC.Spec.Resources.Requests.Storage()).To(Equal(resource.MustParse("10752Mi")))
+
+				// A larger capacity of the owner still applies
+				newPVC = ensurePVCWithCapacity(resource.MustParse("12Gi"))
+				Expect(*newPVC.Spec.Resources.Requests.Storage()).To(Equal(resource.MustParse("12Gi")))
+			})
+
+			It("is never shrunk", func() {
+				newPVC := ensurePVCWithCapacity(resource.MustParse("5Gi"))
+				Expect(*newPVC.Spec.Resources.Requests.Storage()).To(Equal(capacity))
+				Expect(apimeta.FindStatusCondition(rd.Status.Conditions,
+					volsyncv1alpha1.ConditionVolumeExpansion)).To(BeNil())
+			})
+
+			When("the StorageClass does not allow volume expansion", func() {
+				BeforeEach(func() {
+					allowExpansion = false
+				})
+				It("keeps its capacity", func() {
+					newPVC := ensurePVCWithCapacity(resource.MustParse("10Gi"))
+					Expect(*newPVC.Spec.Resources.Requests.Storage()).To(Equal(capacity))
+					cond := apimeta.FindStatusCondition(rd.Status.Conditions, volsyncv1alpha1.ConditionVolumeExpansion)
+					Expect(cond).NotTo(BeNil())
+					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(cond.Reason).To(Equal(volsyncv1alpha1.VolumeExpansionReasonNotAllowed))
+					Expect(recorder.Events).To(HaveLen(1))
+
+					// The warning is not repeated at every reconcile
+					ensurePVCWithCapacity(resource.MustParse("10Gi"))
+					Expect(recorder.Events).To(HaveLen(1))
+
+					// Unless the requested capacity changes
+					ensurePVCWithCapacity(resource.MustParse("12Gi"))
+					Expect(recorder.Events).To(HaveLen(2))
+				})
 			})
 		})
 
@@ -557,6 +721,410 @@ var _ = Describe("Volumehandler", func() {
 				})
 			})
 		})
+		When("CopyMethod is FreezeSnapshot", func() {
+			var vh *VolumeHandler
+			var pv *corev1.PersistentVolume
+			const newPvcName = "newpvc"
+
+			BeforeEach(func() {
+				rs.Spec.Rsync.CopyMethod = volsyncv1alpha1.CopyMethodFreezeSnapshot
+
+				pv = &corev1.PersistentVolume{
+					ObjectMeta: metav1.ObjectMeta{
+						GenerateName: "freeze-pv-",
+					},
+					Spec: corev1.PersistentVolumeSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+						Capacity: corev1.ResourceList{
+							corev1.ResourceStorage: pvcRequestedSize,
+						},
+						PersistentVolumeSource: corev1.PersistentVolumeSource{
+							CSI: &corev1.CSIPersistentVolumeSource{
+								Driver:       "fakedriver",
+								VolumeHandle: "my-vol-handle",
+							},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pv)).To(Succeed())
+				src.Spec.VolumeName = pv.GetName()
+			})
+			AfterEach(func() {
+				Expect(k8sClient.Delete(ctx, pv)).To(Succeed())
+			})
+			JustBeforeEach(func() {
+				var err error
+				vh, err = NewVolumeHandler(
+					WithClient(k8sClient),
+					WithOwner(rs),
+					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vh).ToNot(BeNil())
+			})
+
+			When("no pod is using the src PVC", func() {
+				It("takes the snapshot without freezing the filesystem", func() {
+					newPVC, err := vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(newPVC).To(BeNil())
+
+					snap := &snapv1.VolumeSnapshot{}
+					Expect(k8sClient.Get(ctx, types.NamespacedName{Name: newPvcName, Namespace: ns.Name}, snap)).To(Succeed())
+					freezePod := &corev1.Pod{}
+					err = k8sClient.Get(ctx, types.NamespacedName{Name: newPvcName + "-freeze", Namespace: ns.Name}, freezePod)
+					Expect(kerrors.IsNotFound(err)).To(BeTrue())
+				})
+			})
+
+			When("a running pod is using the src PVC", func() {
+				var appPod *corev1.Pod
+				var freezePod *corev1.Pod
+
+				JustBeforeEach(func() {
+					appPod = &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "app",
+							Namespace: ns.Name,
+						},
+						Spec: corev1.PodSpec{
+							NodeName: "node1",
+							Containers: []corev1.Container{{
+								Name:  "app",
+								Image: "app-image",
+							}},
+							Volumes: []corev1.Volume{{
+								Name: "data",
+								VolumeSource: corev1.VolumeSource{
+									PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+										ClaimName: src.GetName(),
+									},
+								},
+							}},
+						},
+					}
+					Expect(k8sClient.Create(ctx, appPod)).To(Succeed())
+					appPod.Status.Phase = corev1.PodRunning
+					Expect(k8sClient.Status().Update(ctx, appPod)).To(Succeed())
+
+					freezePod = &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      newPvcName + "-freeze",
+							Namespace: ns.Name,
+						},
+					}
+				})
+
+				When("another running pod is using the src PVC on a different node", func() {
+					JustBeforeEach(func() {
+						otherPod := appPod.DeepCopy()
+						otherPod.ObjectMeta = metav1.ObjectMeta{
+							Name:      "other-app",
+							Namespace: ns.Name,
+						}
+						otherPod.Spec.NodeName = "node2"
+						otherPod.Status = corev1.PodStatus{}
+						Expect(k8sClient.Create(ctx, otherPod)).To(Succeed())
+						otherPod.Status.Phase = corev1.PodRunning
+						Expect(k8sClient.Status().Update(ctx, otherPod)).To(Succeed())
+					})
+
+					It("does not take the snapshot", func() {
+						newPVC, err := vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+						Expect(err).To(HaveOccurred())
+						Expect(newPVC).To(BeNil())
+
+						err = k8sClient.Get(ctx, client.ObjectKeyFromObject(freezePod), freezePod)
+						Expect(kerrors.IsNotFound(err)).To(BeTrue())
+						snap := &snapv1.VolumeSnapshot{}
+						err = k8sClient.Get(ctx, types.NamespacedName{Name: newPvcName, Namespace: ns.Name}, snap)
+						Expect(kerrors.IsNotFound(err)).To(BeTrue())
+					})
+				})
+
+				When("the namespace does not allow privileged movers", func() {
+					It("does not freeze the filesystem", func() {
+						newPVC, err := vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+						Expect(err).To(HaveOccurred())
+						Expect(newPVC).To(BeNil())
+
+						err = k8sClient.Get(ctx, client.ObjectKeyFromObject(freezePod), freezePod)
+						Expect(kerrors.IsNotFound(err)).To(BeTrue())
+					})
+				})
+
+				When("the namespace allows privileged movers", func() {
+					BeforeEach(func() {
+						Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+						ns.Annotations = map[string]string{
+							volsyncv1alpha1.PrivilegedMoversNamespaceAnnotation: "true",
+						}
+						Expect(k8sClient.Update(ctx, ns)).To(Succeed())
+					})
+
+					JustBeforeEach(func() {
+						// 1st try freezes the filesystem on the node of the pod
+						newPVC, err := vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(newPVC).To(BeNil())
+						Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(freezePod), freezePod)).To(Succeed())
+					})
+
+					It("freezes the filesystem before taking the snapshot", func() {
+						Expect(freezePod.Spec.NodeName).To(Equal("node1"))
+						Expect(*freezePod.Spec.Containers[0].SecurityContext.Privileged).To(BeTrue())
+						Expect(freezePod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+							Name: "MOUNT_PATH",
+							Value: "/var/lib/kubelet/pods/" + string(appPod.GetUID()) +
+								"/volumes/kubernetes.io~csi/" + pv.GetName() + "/mount",
+						}))
+						Expect(freezePod.Spec.ActiveDeadlineSeconds).NotTo(BeNil())
+
+						// No snapshot until the filesystem is frozen
+						newPVC, err := vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(newPVC).To(BeNil())
+						snap := &snapv1.VolumeSnapshot{}
+						err = k8sClient.Get(ctx, types.NamespacedName{Name: newPvcName, Namespace: ns.Name}, snap)
+						Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+						// Once frozen, the snapshot is taken
+						freezePod.Status.Conditions = []corev1.PodCondition{{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						}}
+						freezePod.Status.Phase = corev1.PodRunning
+						Expect(k8sClient.Status().Update(ctx, freezePod)).To(Succeed())
+						newPVC, err = vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(newPVC).To(BeNil())
+						Expect(k8sClient.Get(ctx, types.NamespacedName{Name: newPvcName, Namespace: ns.Name}, snap)).To(Succeed())
+
+						// The filesystem stays frozen until the snapshot has been taken
+						Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(freezePod), freezePod)).To(Succeed())
+						Expect(freezePod.GetDeletionTimestamp()).To(BeNil())
+
+						boundTo := "bar"
+						snap.Status = &snapv1.VolumeSnapshotStatus{
+							BoundVolumeSnapshotContentName: &boundTo,
+							CreationTime:                   &metav1.Time{Time: time.Now()},
+						}
+						Expect(k8sClient.Status().Update(ctx, snap)).To(Succeed())
+
+						newPVC, err = vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(newPVC).NotTo(BeNil())
+						Expect(newPVC.Spec.DataSource.Name).To(Equal(snap.GetName()))
+
+						// Unfrozen by terminating the freeze pod
+						err = k8sClient.Get(ctx, client.ObjectKeyFromObject(freezePod), freezePod)
+						Expect(kerrors.IsNotFound(err) || freezePod.GetDeletionTimestamp() != nil).To(BeTrue())
+					})
+
+					When("the freeze helper fails before the snapshot is taken", func() {
+						It("discards the snapshot, unfreezes the filesystem and retries", func() {
+							freezePod.Status.Conditions = []corev1.PodCondition{{
+								Type:   corev1.PodReady,
+								Status: corev1.ConditionTrue,
+							}}
+							freezePod.Status.Phase = corev1.PodRunning
+							Expect(k8sClient.Status().Update(ctx, freezePod)).To(Succeed())
+							newPVC, err := vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(newPVC).To(BeNil())
+
+							freezePod.Status.Phase = corev1.PodFailed
+							Expect(k8sClient.Status().Update(ctx, freezePod)).To(Succeed())
+							newPVC, err = vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(newPVC).To(BeNil())
+
+							snap := &snapv1.VolumeSnapshot{}
+							err = k8sClient.Get(ctx, types.NamespacedName{Name: newPvcName, Namespace: ns.Name}, snap)
+							Expect(kerrors.IsNotFound(err) || snap.GetDeletionTimestamp() != nil).To(BeTrue())
+
+							// The helper may not have unfrozen the filesystem, another pod does it on the same node
+							unfreezePod := &corev1.Pod{}
+							Expect(k8sClient.Get(ctx, types.NamespacedName{Name: newPvcName + "-unfreeze", Namespace: ns.Name},
+								unfreezePod)).To(Succeed())
+							Expect(unfreezePod.Spec.NodeName).To(Equal("node1"))
+							Expect(unfreezePod.Spec.Containers[0].Command).To(ContainElement("/mover-freeze/unfreeze.sh"))
+							Expect(unfreezePod.Spec.Containers[0].Env).To(Equal(freezePod.Spec.Containers[0].Env))
+							Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(freezePod), freezePod)).To(Succeed())
+							Expect(freezePod.GetDeletionTimestamp()).To(BeNil())
+
+							unfreezePod.Status.Phase = corev1.PodSucceeded
+							Expect(k8sClient.Status().Update(ctx, unfreezePod)).To(Succeed())
+							newPVC, err = vh.EnsurePVCFromSrc(ctx, logger, src, newPvcName, true)
+							Expect(err).ToNot(HaveOccurred())
+							Expect(newPVC).To(BeNil())
+
+							err = k8sClient.Get(ctx, client.ObjectKeyFromObject(unfreezePod), unfreezePod)
+							Expect(kerrors.IsNotFound(err) || unfreezePod.GetDeletionTimestamp() != nil).To(BeTrue())
+							err = k8sClient.Get(ctx, client.ObjectKeyFromObject(freezePod), freezePod)
+							Expect(kerrors.IsNotFound(err) || freezePod.GetDeletionTimestamp() != nil).To(BeTrue())
+						})
+					})
+				})
+			})
+		})
+		When("the source is an existing snapshot", func() {
+			var vh *VolumeHandler
+			var snapSourcePVC string
+			newPvcName := "newpvc"
+			// Creates a snapshot of snapSourcePVC that becomes ready to use at creationTime
+			createSnapshot := func(name string, labels map[string]string, creationTime time.Time,
+				ready bool) *snapv1.VolumeSnapshot {
+				snap := &snapv1.VolumeSnapshot{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: ns.Name,
+						Labels:    labels,
+					},
+					Spec: snapv1.VolumeSnapshotSpec{
+						Source: snapv1.VolumeSnapshotSource{
+							PersistentVolumeClaimName: &snapSourcePVC,
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, snap)).To(Succeed())
+				snap.Status = &snapv1.VolumeSnapshotStatus{
+					CreationTime: &metav1.Time{Time: creationTime},
+					ReadyToUse:   &ready,
+					RestoreSize:  &snapshotRestoreSize,
+				}
+				Expect(k8sClient.Status().Update(ctx, snap)).To(Succeed())
+				return snap
+			}
+			// Deletes pvc like the cleanup after a synchronization
+			deletePVC := func(pvc *corev1.PersistentVolumeClaim) {
+				Expect(k8sClient.Delete(ctx, pvc)).To(Succeed())
+				// No controller removes the pvc-protection finalizer in the test env
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+				pvc.Finalizers = nil
+				Expect(k8sClient.Update(ctx, pvc)).To(Succeed())
+			}
+			BeforeEach(func() {
+				snapSourcePVC = src.Name
+				// The copyMethod doesn't apply to existing snapshots
+				rs.Spec.Rsync.CopyMethod = volsyncv1alpha1.CopyMethodDirect
+			})
+			JustBeforeEach(func() {
+				var err error
+				vh, err = NewVolumeHandler(
+					WithClient(k8sClient),
+					WithOwner(rs),
+					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+					SourceSnapshot(rs.Spec.SourceSnapshot),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vh.UsesSourceSnapshot()).To(BeTrue())
+				Expect(vh.IsCopyMethodDirect()).To(BeFalse())
+			})
+
+			When("snapshots are selected by label", func() {
+				labels := map[string]string{"backup": "daily"}
+				BeforeEach(func() {
+					rs.Spec.SourceSnapshot = &volsyncv1alpha1.ReplicationSourceSnapshotSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: labels},
+					}
+				})
+				It("restores the PVC from the newest ready snapshot", func() {
+					now := time.Now()
+					createSnapshot("older", labels, now.Add(-2*time.Hour), true)
+					createSnapshot("newest", labels, now.Add(-time.Hour), true)
+					createSnapshot("notready", labels, now, false)
+					createSnapshot("unselected", nil, now, true)
+
+					newPVC, err := vh.EnsurePVCFromSourceSnapshot(ctx, logger, newPvcName, true, true)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(newPVC).NotTo(BeNil())
+					Expect(newPVC.Spec.DataSource).NotTo(BeNil())
+					Expect(newPVC.Spec.DataSource.Kind).To(Equal("VolumeSnapshot"))
+					Expect(newPVC.Spec.DataSource.Name).To(Equal("newest"))
+					// The PVC the snapshot was taken from provides the defaults
+					Expect(newPVC.Spec.StorageClassName).To(Equal(src.Spec.StorageClassName))
+					Expect(newPVC.Spec.AccessModes).To(Equal(src.Spec.AccessModes))
+					Expect(*newPVC.Spec.Resources.Requests.Storage()).To(Equal(snapshotRestoreSize))
+					Expect(utils.IsMarkedForCleanup(newPVC)).To(BeTrue())
+
+					// A newer snapshot is only used once the PVC has been cleaned up
+					createSnapshot("newer", labels, now, true)
+					newPVC, err = vh.EnsurePVCFromSourceSnapshot(ctx, logger, newPvcName, true, true)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(newPVC.Spec.DataSource.Name).To(Equal("newest"))
+				})
+				It("does not replicate the same snapshot twice", func() {
+					now := time.Now()
+					newest := createSnapshot("newest", labels, now.Add(-time.Hour), true)
+
+					newPVC, err := vh.EnsurePVCFromSourceSnapshot(ctx, logger, newPvcName, true, true)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(newPVC).NotTo(BeNil())
+					// The snapshot is only recorded once the synchronization completes
+					Expect(rs.Status.LastSourceSnapshot).To(BeNil())
+					Expect(RecordReplicatedSourceSnapshot(ctx, k8sClient, rs)).To(Succeed())
+					Expect(rs.Status.LastSourceSnapshot).To(Equal(&volsyncv1alpha1.ReplicationSourceSnapshotStatus{
+						Name: "newest",
+						UID:  newest.GetUID(),
+					}))
+
+					// The next synchronization, once the PVC has been cleaned up
+					deletePVC(newPVC)
+					newPVC, err = vh.EnsurePVCFromSourceSnapshot(ctx, logger, newPvcName, true, true)
+					Expect(err).To(MatchError(ErrSourceSnapshotReplicated))
+					Expect(newPVC).To(BeNil())
+
+					// Unless asked to, e.g. to verify it
+					newPVC, err = vh.EnsurePVCFromSourceSnapshot(ctx, logger, newPvcName, true, false)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(newPVC.Spec.DataSource.Name).To(Equal("newest"))
+					deletePVC(newPVC)
+
+					// A newer snapshot is replicated, but isn't recorded if the
+					// synchronization doesn't complete
+					newer := createSnapshot("newer", labels, now, true)
+					newPVC, err = vh.EnsurePVCFromSourceSnapshot(ctx, logger, newPvcName, true, true)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(newPVC.Spec.DataSource.Name).To(Equal("newer"))
+					Expect(rs.Status.LastSourceSnapshot.UID).To(Equal(newest.GetUID()))
+					Expect(RecordReplicatedSourceSnapshot(ctx, k8sClient, rs)).To(Succeed())
+					Expect(rs.Status.LastSourceSnapshot.UID).To(Equal(newer.GetUID()))
+				})
+				It("fails if no selected snapshot is ready", func() {
+					createSnapshot("notready", labels, time.Now(), false)
+
+					newPVC, err := vh.EnsurePVCFromSourceSnapshot(ctx, logger, newPvcName, true, true)
+					Expect(err).To(HaveOccurred())
+					Expect(newPVC).To(BeNil())
+				})
+			})
+
+			When("a snapshot is selected by name", func() {
+				BeforeEach(func() {
+					rs.Spec.SourceSnapshot = &volsyncv1alpha1.ReplicationSourceSnapshotSpec{
+						Name: ptr.To("mysnap"),
+					}
+				})
+				It("restores the PVC from it, even if its source PVC is gone", func() {
+					snapSourcePVC = "deleted-pvc"
+					createSnapshot("mysnap", nil, time.Now(), true)
+
+					newPVC, err := vh.EnsurePVCFromSourceSnapshot(ctx, logger, newPvcName, true, true)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(newPVC).NotTo(BeNil())
+					Expect(newPVC.Spec.DataSource.Name).To(Equal("mysnap"))
+					Expect(*newPVC.Spec.Resources.Requests.Storage()).To(Equal(snapshotRestoreSize))
+					Expect(newPVC.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+					Expect(*newPVC.Spec.VolumeMode).To(Equal(corev1.PersistentVolumeFilesystem))
+				})
+				It("fails if the snapshot doesn't exist", func() {
+					newPVC, err := vh.EnsurePVCFromSourceSnapshot(ctx, logger, newPvcName, true, true)
+					Expect(kerrors.IsNotFound(err)).To(BeTrue())
+					Expect(newPVC).To(BeNil())
+				})
+			})
+		})
 	})
 })
 
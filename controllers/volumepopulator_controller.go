@@ -22,11 +22,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
 	volumepopulatorv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -48,6 +51,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/mover"
 	"github.com/backube/volsync/controllers/utils"
 )
 
@@ -57,10 +61,20 @@ const (
 	annotationPopulatedFrom string = "volsync.backube/populated-from"
 	labelPvcPrime           string = utils.VolsyncLabelPrefix + "/populator-pvc-for"
 
+	// Annotations on the PVC to restore a selected backup rather than the ReplicationDestination latestImage
+	annotationRestoreSnapshotID string = utils.VolsyncLabelPrefix + "/restore-snapshot-id"
+	annotationRestoreAsOf       string = utils.VolsyncLabelPrefix + "/restore-as-of"
+	// Set on pvcPrime once the selected backup (or the copied snapshot) has been restored into it
+	annotationRestoreComplete string = utils.VolsyncLabelPrefix + "/restore-complete"
+
 	VolPopPVCToReplicationDestinationIndex string = "volPopPvc.spec.dataSourceRef.Name"
 	VolPopPVCToStorageClassIndex           string = "volPopPvc.spec.storageClassName"
 
 	VolPopCRName string = "volsync-replicationdestination"
+
+	// How often to check again for a ReferenceGrant allowing a PVC to use a ReplicationDestination in another
+	// namespace
+	referenceGrantRetryInterval = 1 * time.Minute
 )
 
 func IndexFieldsForVolumePopulator(ctx context.Context, fieldIndexer client.FieldIndexer) error {
@@ -78,8 +92,10 @@ func IndexFieldsForVolumePopulator(ctx context.Context, fieldIndexer client.Fiel
 				return res
 			}
 
-			// just return the raw field value -- the indexer will take care of dealing with namespaces for us
-			res = append(res, pvc.Spec.DataSourceRef.Name)
+			// The ReplicationDestination may be in another namespace, so index on namespace/name - use
+			// getReplicationDestinationIndexKey() to look up pvcs in all namespaces
+			res = append(res, getReplicationDestinationIndexKey(getDataSourceRefNamespace(pvc),
+				pvc.Spec.DataSourceRef.Name))
 
 			return res
 		})
@@ -173,19 +189,25 @@ func isVolumePopulatorCRDPresent(ctx context.Context, k8sClient client.Client) (
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims/finalizers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=volsync.backube,resources=replicationdestinations,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshotcontents,verbs=get;list;watch
 //+kubebuilder:rbac:groups=populator.storage.k8s.io,resources=volumepopulators,verbs=get;list;watch;create;update;patch
 
 // VolumePopulatorReconciler reconciles PVCs that use a dataSourceRef that refers to a
 // ReplicationDestination object.
 // The VolumePopulatorReconciler will create a PVC from the latest snapshot image in
-// a ReplicationDestination.
+// a ReplicationDestination, or restore a selected backup from the ReplicationDestination's
+// repository if requested via annotations on the PVC.
 type VolumePopulatorReconciler struct {
 	client.Client
 	Log           logr.Logger
 	Scheme        *runtime.Scheme
 	EventRecorder record.EventRecorder
+	// CopyContainerImage is used to copy the data of a snapshot that cannot be provisioned directly
+	CopyContainerImage string
 }
 
 type vpResult struct {
@@ -246,6 +268,18 @@ func (r *VolumePopulatorReconciler) reconcilePVC(ctx context.Context, logger log
 			return primeResult.result()
 		}
 
+		// If a specific backup was selected, restore it into pvcPrime before rebinding
+		restoreResult := r.reconcileRestore(ctx, logger, pvc, pvcPrime)
+		if restoreResult != nil {
+			return restoreResult.result()
+		}
+
+		// If the snapshot could not be provisioned directly, copy its data into pvcPrime before rebinding
+		copyResult := r.reconcileCopy(ctx, logger, pvc, pvcPrime)
+		if copyResult != nil {
+			return copyResult.result()
+		}
+
 		// Make sure any snapshots we've tried to use have owner reference of pvcPrime (for future cleanup)
 		err = r.ensureOwnerReferenceOnSnapshots(ctx, pvc, pvcPrime)
 		if err != nil {
@@ -327,6 +361,11 @@ func (r *VolumePopulatorReconciler) reconcilePVCPrime(ctx context.Context, logge
 	waitForFirstConsumer bool, nodeName string) (*corev1.PersistentVolumeClaim, *vpResult) {
 	if pvcPrime == nil {
 		// pvcPrime doesn't exist yet
+		// A ReplicationDestination in another namespace may only be used if a ReferenceGrant allows it
+		if grantResult := r.checkDataSourceRefGranted(ctx, logger, pvc); grantResult != nil {
+			return nil, grantResult
+		}
+
 		// Check for existence of ReplicationDestination here - if PVC' was already there, then it may
 		// be ok if replicationdestination is missing - so only error out here if RD doesn't exist
 		rd, err := r.getReplicationDestinationFromDataSourceRef(ctx, logger, pvc)
@@ -343,6 +382,42 @@ func (r *VolumePopulatorReconciler) reconcilePVCPrime(ctx context.Context, logge
 
 		logger = logger.WithValues("replication destination name", rd.GetName(), "namespace", rd.GetNamespace())
 
+		restoreOptions, err := getPopulatorRestoreOptions(pvc)
+		if err != nil {
+			logger.Error(err, "Unable to populate volume")
+			r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCPopulatorError,
+				"Unable to populate volume: %s", err)
+			// Do not return error here - no use retrying until the annotations are fixed
+			return nil, &vpResult{ctrl.Result{}, nil}
+		}
+		if restoreOptions != nil {
+			var restoreErr error
+			if pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+				restoreErr = fmt.Errorf("a selected backup can only be restored into a Filesystem volume")
+			} else if rd.GetNamespace() != pvc.GetNamespace() {
+				// The mover needs the repository configuration from the namespace it runs in
+				restoreErr = fmt.Errorf("a selected backup can only be restored from a " +
+					"replicationdestination in the same namespace")
+			}
+			if restoreErr != nil {
+				logger.Error(restoreErr, "Unable to populate volume")
+				r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCPopulatorError,
+					"Unable to populate volume: %s", restoreErr)
+				// Do not return error here - no use retrying
+				return nil, &vpResult{ctrl.Result{}, nil}
+			}
+			// The selected backup will be restored into an empty pvcPrime - keep the selection on pvcPrime so it
+			// stays consistent while the restore runs
+			annotations := map[string]string{}
+			for _, key := range []string{annotationRestoreSnapshotID, annotationRestoreAsOf} {
+				if value, ok := pvc.Annotations[key]; ok {
+					annotations[key] = value
+				}
+			}
+			return r.createPVCPrime(ctx, logger, pvc, nil, annotations,
+				"to restore "+describeRestoreSelection(pvc), waitForFirstConsumer, nodeName)
+		}
+
 		if rd.Status == nil || rd.Status.LatestImage == nil {
 			logger.Info("ReplicationDestination has no latestImage, cannot populate volume yet")
 			r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCReplicationDestNoLatestImage,
@@ -363,57 +438,160 @@ func (r *VolumePopulatorReconciler) reconcilePVCPrime(ctx context.Context, logge
 			return nil, &vpResult{ctrl.Result{}, nil}
 		}
 
-		_, err = r.validateSnapshotAndLabel(ctx, logger, latestImage.Name, rd.GetNamespace(), pvc)
+		snapshot, err := r.validateSnapshotAndLabel(ctx, logger, latestImage.Name, rd.GetNamespace(), pvc)
 		if err != nil {
 			return nil, &vpResult{ctrl.Result{}, err}
 		}
 
-		pvcPrime = &corev1.PersistentVolumeClaim{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      getPVCPrimeName(pvc),
-				Namespace: pvc.GetNamespace(),
-			},
-			Spec: corev1.PersistentVolumeClaimSpec{
-				AccessModes:      pvc.Spec.AccessModes,
-				Resources:        pvc.Spec.Resources,
-				StorageClassName: pvc.Spec.StorageClassName,
-				VolumeMode:       pvc.Spec.VolumeMode,
-				DataSourceRef: &corev1.TypedObjectReference{
-					APIGroup: latestImage.APIGroup,
-					Kind:     latestImage.Kind,
-					Name:     latestImage.Name,
-					//Namespace: &rd.GetNamespace(), // Future, if we support cross-namespace
-				},
-			},
+		// The snapshot can only be provisioned with its own CSI driver, otherwise the data is copied into an
+		// empty pvcPrime from a temporary volume
+		copyStorageClassName, copyResult := r.getCopySourceStorageClassName(ctx, logger, pvc, snapshot)
+		if copyResult != nil {
+			return nil, copyResult
 		}
-		if waitForFirstConsumer {
-			pvcPrime.Annotations = map[string]string{
-				annotationSelectedNode: nodeName,
+		if copyStorageClassName != "" {
+			annotations := map[string]string{
+				annotationCopyFromSnapshot:     latestImage.Name,
+				annotationCopyFromStorageClass: copyStorageClassName,
 			}
+			return r.createPVCPrime(ctx, logger, pvc, nil, annotations,
+				"to copy snapshot "+latestImage.Name+" into", waitForFirstConsumer, nodeName)
 		}
-		// Make pvcPrime owned by pvc - will be cleaned up via gc if pvc is deleted
-		if err := ctrl.SetControllerReference(pvc, pvcPrime, r.Client.Scheme()); err != nil {
-			logger.Error(err, utils.ErrUnableToSetControllerRef)
-			return nil, &vpResult{ctrl.Result{}, err}
-		}
-		utils.AddLabel(pvcPrime, labelPvcPrime, pvc.GetName()) // Use this filter in predicates in the &Owns() watcher
-		utils.SetOwnedByVolSync(pvcPrime)                      // Set created-by volsync label
 
-		logger.Info("Creating temp populator pvc from snapshot", "volpop pvc name", pvcPrime.GetName())
-		err = r.Client.Create(ctx, pvcPrime)
-		if err != nil {
-			r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCCreationError,
-				"Failed to create populator PVC: %s", err)
-			return nil, &vpResult{ctrl.Result{}, err}
+		return r.createPVCPrime(ctx, logger, pvc, latestImage, nil,
+			"from snapshot "+latestImage.Name, waitForFirstConsumer, nodeName)
+	}
+
+	return pvcPrime, nil
+}
+
+// Creates pvcPrime from the snapshot image, or as an empty volume (to restore or copy the data into) if
+// image is nil. The annotations describe how an empty pvcPrime is populated.
+func (r *VolumePopulatorReconciler) createPVCPrime(ctx context.Context, logger logr.Logger,
+	pvc *corev1.PersistentVolumeClaim, image *corev1.TypedLocalObjectReference,
+	annotations map[string]string, description string,
+	waitForFirstConsumer bool, nodeName string) (*corev1.PersistentVolumeClaim, *vpResult) {
+	pvcPrime := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getPVCPrimeName(pvc),
+			Namespace: pvc.GetNamespace(),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      pvc.Spec.AccessModes,
+			Resources:        pvc.Spec.Resources,
+			StorageClassName: pvc.Spec.StorageClassName,
+			VolumeMode:       pvc.Spec.VolumeMode,
+		},
+	}
+	if image != nil {
+		pvcPrime.Spec.DataSourceRef = &corev1.TypedObjectReference{
+			APIGroup: image.APIGroup,
+			Kind:     image.Kind,
+			Name:     image.Name,
 		}
+		// The snapshot is in the namespace of the ReplicationDestination
+		if sourceNamespace := getDataSourceRefNamespace(pvc); sourceNamespace != pvc.GetNamespace() {
+			pvcPrime.Spec.DataSourceRef.Namespace = &sourceNamespace
+		}
+	}
+	for key, value := range annotations {
+		metav1.SetMetaDataAnnotation(&pvcPrime.ObjectMeta, key, value)
+	}
+	if waitForFirstConsumer {
+		metav1.SetMetaDataAnnotation(&pvcPrime.ObjectMeta, annotationSelectedNode, nodeName)
+	}
+	// Make pvcPrime owned by pvc - will be cleaned up via gc if pvc is deleted
+	if err := ctrl.SetControllerReference(pvc, pvcPrime, r.Client.Scheme()); err != nil {
+		logger.Error(err, utils.ErrUnableToSetControllerRef)
+		return nil, &vpResult{ctrl.Result{}, err}
+	}
+	utils.AddLabel(pvcPrime, labelPvcPrime, pvc.GetName()) // Use this filter in predicates in the &Owns() watcher
+	utils.SetOwnedByVolSync(pvcPrime)                      // Set created-by volsync label
 
-		r.EventRecorder.Eventf(pvc, corev1.EventTypeNormal, volsyncv1alpha1.EvRVolPopPVCCreationSuccess,
-			"Populator pvc created from snapshot %s", latestImage.Name)
+	logger.Info("Creating temp populator pvc", "volpop pvc name", pvcPrime.GetName())
+	err := r.Client.Create(ctx, pvcPrime)
+	if err != nil {
+		r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCCreationError,
+			"Failed to create populator PVC: %s", err)
+		return nil, &vpResult{ctrl.Result{}, err}
 	}
 
+	r.EventRecorder.Eventf(pvc, corev1.EventTypeNormal, volsyncv1alpha1.EvRVolPopPVCCreationSuccess,
+		"Populator pvc created %s", description)
+
 	return pvcPrime, nil
 }
 
+// Restores the backup selected on pvcPrime (if any) using the mover of the ReplicationDestination.
+// Returns nil once pvcPrime is ready to be rebound.
+func (r *VolumePopulatorReconciler) reconcileRestore(ctx context.Context, logger logr.Logger,
+	pvc, pvcPrime *corev1.PersistentVolumeClaim) *vpResult {
+	restoreOptions, err := getPopulatorRestoreOptions(pvcPrime)
+	if err != nil || restoreOptions == nil {
+		// pvcPrime was populated from a snapshot, the selection was validated before pvcPrime was created
+		return nil
+	}
+	if metav1.HasAnnotation(pvcPrime.ObjectMeta, annotationRestoreComplete) {
+		return nil
+	}
+
+	rd, err := r.getReplicationDestinationFromDataSourceRef(ctx, logger, pvc)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return &vpResult{ctrl.Result{}, err}
+		}
+		r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvVolPopPVCReplicationDestMissing,
+			"Unable to populate volume: %s", err)
+		// Do not return error - will rely on watches to reconcile once the rd is created
+		return &vpResult{ctrl.Result{}, nil}
+	}
+	logger = logger.WithValues("replication destination name", rd.GetName(), "namespace", rd.GetNamespace())
+
+	privilegedMoverOk, err := utils.PrivilegedMoversOk(ctx, r.Client, logger, rd.GetNamespace())
+	if err != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+
+	dataMover, err := mover.GetPopulatorMoverFromCatalog(r.Client, logger,
+		record.NewEventRecorderAdapter(r.EventRecorder), rd, pvcPrime, pvcPrime.GetName(),
+		*restoreOptions, privilegedMoverOk)
+	if err != nil {
+		logger.Error(err, "Unable to find a mover to restore the selected backup")
+		r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCPopulatorError,
+			"Unable to populate volume: restoring a selected backup is not supported by the "+
+				"replicationdestination's mover")
+		// Do not return error here - no use retrying
+		return &vpResult{ctrl.Result{}, nil}
+	}
+
+	result, err := dataMover.Synchronize(ctx)
+	if err != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+	if !result.Completed {
+		return &vpResult{result.ReconcileResult(), nil}
+	}
+
+	// Record the completion before removing the mover so the restore is not run again
+	metav1.SetMetaDataAnnotation(&pvcPrime.ObjectMeta, annotationRestoreComplete, "true")
+	if err := r.Client.Update(ctx, pvcPrime); err != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+	r.EventRecorder.Eventf(pvc, corev1.EventTypeNormal, volsyncv1alpha1.EvRVolPopPVCRestoreComplete,
+		"Restored %s into populator pvc", describeRestoreSelection(pvcPrime))
+
+	// Any leftovers are also garbage collected along with pvcPrime
+	result, err = dataMover.Cleanup(ctx)
+	if err != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+	if !result.Completed {
+		return &vpResult{result.ReconcileResult(), nil}
+	}
+
+	return nil
+}
+
 func (r *VolumePopulatorReconciler) rebindPVClaim(ctx context.Context, logger logr.Logger,
 	pvc, pvcPrime *corev1.PersistentVolumeClaim) *vpResult {
 	// Get PV from pvcPrime
@@ -442,12 +620,19 @@ func (r *VolumePopulatorReconciler) rebindPVClaim(ctx context.Context, logger lo
 		claimRef.Name != pvc.Name ||
 		claimRef.Namespace != pvc.Namespace ||
 		claimRef.UID != pvc.UID {
+		// A restored volume is populated from the ReplicationDestination itself rather than a snapshot
+		populatedFrom := pvc.Spec.DataSourceRef.Name
+		if pvcPrime.Spec.DataSourceRef != nil {
+			populatedFrom = pvcPrime.Spec.DataSourceRef.Name
+		} else if snapshotName, ok := pvcPrime.Annotations[annotationCopyFromSnapshot]; ok {
+			populatedFrom = snapshotName
+		}
 		// Make new PV with strategic patch values to perform the PV rebind
 		patchPv := &corev1.PersistentVolume{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: pv.Name,
 				Annotations: map[string]string{
-					annotationPopulatedFrom: pvc.Namespace + "/" + pvcPrime.Spec.DataSourceRef.Name,
+					annotationPopulatedFrom: getDataSourceRefNamespace(pvc) + "/" + populatedFrom,
 				},
 			},
 			Spec: corev1.PersistentVolumeSpec{
@@ -492,6 +677,10 @@ func (r *VolumePopulatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
 				return mapFuncStorageClassToVolumePopulatorPVC(ctx, mgr.GetClient(), o)
 			}), builder.WithPredicates(storageClassPredicate())).
+		Watches(&batchv1.Job{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
+				return mapFuncJobToVolumePopulatorPVC(ctx, mgr.GetClient(), o)
+			})).
 		Complete(r)
 }
 
@@ -590,12 +779,13 @@ func mapFuncReplicationDestinationToVolumePopulatorPVC(ctx context.Context, k8sC
 		return []reconcile.Request{}
 	}
 
-	// Find PVCs that use this ReplicationDestination in their dataSourceRef (using index)
+	// Find PVCs (in any namespace) that use this ReplicationDestination in their dataSourceRef (using index)
 	pvcList := &corev1.PersistentVolumeClaimList{}
 	err := k8sClient.List(ctx, pvcList,
 		client.MatchingFields{
-			VolPopPVCToReplicationDestinationIndex: replicationDestination.GetName()}, // custom index
-		client.InNamespace(replicationDestination.GetNamespace()))
+			VolPopPVCToReplicationDestinationIndex: getReplicationDestinationIndexKey(
+				replicationDestination.GetNamespace(), replicationDestination.GetName())}, // custom index
+	)
 	if err != nil {
 		logger.Error(err, "Error looking up pvcs (using index) matching replication destination",
 			"rd name", replicationDestination.GetName(), "namespace", replicationDestination.GetNamespace(),
@@ -633,6 +823,33 @@ func mapFuncStorageClassToVolumePopulatorPVC(ctx context.Context, k8sClient clie
 	return filterRequestsOnlyUnboundPVCs(pvcList)
 }
 
+// Jobs restoring a selected backup or copying a snapshot are owned by pvcPrime, reconcile the PVC that pvcPrime is populating
+func mapFuncJobToVolumePopulatorPVC(ctx context.Context, k8sClient client.Client,
+	o client.Object) []reconcile.Request {
+	ownerRef := metav1.GetControllerOf(o)
+	if ownerRef == nil || ownerRef.Kind != "PersistentVolumeClaim" ||
+		!strings.HasPrefix(ownerRef.Name, populatorPvcPrefix) {
+		return []reconcile.Request{}
+	}
+
+	pvcPrime := &corev1.PersistentVolumeClaim{}
+	err := k8sClient.Get(ctx, types.NamespacedName{Name: ownerRef.Name, Namespace: o.GetNamespace()}, pvcPrime)
+	if err != nil {
+		return []reconcile.Request{}
+	}
+	pvcName, ok := pvcPrime.GetLabels()[labelPvcPrime]
+	if !ok {
+		return []reconcile.Request{}
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Name:      pvcName,
+			Namespace: pvcPrime.GetNamespace(),
+		},
+	}}
+}
+
 func filterRequestsOnlyUnboundPVCs(pvcList *corev1.PersistentVolumeClaimList) []reconcile.Request {
 	reqs := []reconcile.Request{}
 
@@ -660,11 +877,7 @@ func (r VolumePopulatorReconciler) getReplicationDestinationFromDataSourceRef(ct
 	pvc *corev1.PersistentVolumeClaim) (*volsyncv1alpha1.ReplicationDestination, error) {
 	// dataSourceRef should be pointing to a ReplicationDestination (see predicates)
 	rdName := pvc.Spec.DataSourceRef.Name
-	rdNamespace := pvc.GetNamespace()
-	// Future, if we allow cross-namespace:
-	// if pvc.Spec.DataSourceRef.Namespace != nil && *pvc.Spec.DataSourceRef.Namespace != "" {
-	//		rdNamespace = *pvc.Spec.DataSourceRef.Namespace
-	//	}
+	rdNamespace := getDataSourceRefNamespace(pvc)
 	replicationDestinationForVolPop := &volsyncv1alpha1.ReplicationDestination{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      rdName,
@@ -684,9 +897,38 @@ func (r VolumePopulatorReconciler) getReplicationDestinationFromDataSourceRef(ct
 	return replicationDestinationForVolPop, nil
 }
 
+// Makes sure a ReplicationDestination in another namespace is allowed to be used by the pvc
+func (r *VolumePopulatorReconciler) checkDataSourceRefGranted(ctx context.Context, logger logr.Logger,
+	pvc *corev1.PersistentVolumeClaim) *vpResult {
+	rdNamespace := getDataSourceRefNamespace(pvc)
+	if rdNamespace == pvc.GetNamespace() {
+		return nil
+	}
+
+	granted, err := utils.IsReferenceGranted(ctx, r.Client,
+		metav1.GroupKind{Group: corev1.GroupName, Kind: "PersistentVolumeClaim"}, pvc.GetNamespace(),
+		metav1.GroupKind{Group: volsyncv1alpha1.GroupVersion.Group, Kind: "ReplicationDestination"},
+		types.NamespacedName{Name: pvc.Spec.DataSourceRef.Name, Namespace: rdNamespace})
+	if err != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+	if !granted {
+		grantErr := fmt.Errorf("no ReferenceGrant in namespace %s allows the use of replicationdestination %s",
+			rdNamespace, pvc.Spec.DataSourceRef.Name)
+		logger.Error(grantErr, "Unable to populate volume")
+		r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCPopulatorError,
+			"Unable to populate volume: %s", grantErr)
+		// ReferenceGrants are not watched (the CRD is optional), check again later
+		return &vpResult{ctrl.Result{RequeueAfter: referenceGrantRetryInterval}, nil}
+	}
+	return nil
+}
+
 // Cleanup
 //   - if any snapshots have our vol pop label for our PVC, remove the label
 //   - if do-not-delete label is on the snapshot, remove our ownerref so we will not cause GC of the snap to happen
+//   - if the snapshot is in another namespace (no ownerref), delete it if it is no longer used by its owner
+//     or by other volume populator pvcs
 //   - if pvcPrime is not nil, we will assume it exists and needs to be cleaned up
 func (r *VolumePopulatorReconciler) cleanup(ctx context.Context, logger logr.Logger,
 	pvc, pvcPrime *corev1.PersistentVolumeClaim) error {
@@ -704,6 +946,18 @@ func (r *VolumePopulatorReconciler) cleanup(ctx context.Context, logger logr.Log
 			updated = utils.RemoveOwnerReference(&snap, pvcPrime) || updated
 		}
 
+		if snap.GetNamespace() != pvc.GetNamespace() && releasedByOwner(&snap) &&
+			!utils.SnapInUseByVolumePopulatorPVC(&snap) {
+			// This is what GC would do for a snapshot owned by pvcPrime, once no other pvc is
+			// being populated from it
+			logger.Info("Cleanup - deleting snapshot released by its owner", "snapshot name", snap.GetName(),
+				"namespace", snap.GetNamespace())
+			if err := r.Client.Delete(ctx, &snap); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+
 		if updated {
 			if err := r.Client.Update(ctx, &snap); err != nil {
 				logger.Error(err, "Failed to update labels on snapshot")
@@ -772,7 +1026,9 @@ func (r *VolumePopulatorReconciler) ensureOwnerReferenceOnSnapshots(ctx context.
 		// Make sure the snapshot is owned by pvcPrime to prevent others (pvcs w/ volume populator using the same
 		// snapshot or replicationdestination) from removing it
 		// Ownership is just there for cleanup later on - if marked do-not-delete then no need for ownership
-		if !utils.IsMarkedDoNotDelete(&snapshot) {
+		// Owner references cannot refer to another namespace, a snapshot in the namespace of the
+		// ReplicationDestination is deleted during cleanup instead
+		if !utils.IsMarkedDoNotDelete(&snapshot) && snapshot.GetNamespace() == pvcPrime.GetNamespace() {
 			err = r.ensureOwnerReferenceOnSnapshot(ctx, &snapshot, pvcPrime)
 			if err != nil {
 				return err
@@ -796,7 +1052,7 @@ func (r *VolumePopulatorReconciler) listSnapshotsUsedByVolPopForPVC(ctx context.
 		client.MatchingLabelsSelector{
 			Selector: ls,
 		},
-		client.InNamespace(pvc.GetNamespace()),
+		client.InNamespace(getDataSourceRefNamespace(pvc)),
 	}
 	snapList := &snapv1.VolumeSnapshotList{}
 	err = r.Client.List(ctx, snapList, listOptions...)
@@ -856,6 +1112,59 @@ func GetVolumePopulatorPVCPrime(ctx context.Context, c client.Client,
 	return pvcPrime, nil
 }
 
+var resticSnapshotIDRegex = regexp.MustCompile(`^[0-9a-f]{8,64}$`)
+
+// Returns the backup selected via annotations on obj (the PVC, or pvcPrime once created), or nil if the
+// volume should be populated from the ReplicationDestination latestImage. The snapshot ID takes precedence
+// over the restore-as-of time if both are set.
+func getPopulatorRestoreOptions(obj metav1.Object) (*mover.PopulatorOptions, error) {
+	annotations := obj.GetAnnotations()
+	snapshotID, hasSnapshotID := annotations[annotationRestoreSnapshotID]
+	restoreAsOf, hasRestoreAsOf := annotations[annotationRestoreAsOf]
+
+	if hasSnapshotID {
+		if !resticSnapshotIDRegex.MatchString(snapshotID) {
+			return nil, fmt.Errorf("annotation %s is not a valid snapshot ID: %q", annotationRestoreSnapshotID,
+				snapshotID)
+		}
+		return &mover.PopulatorOptions{SnapshotID: &snapshotID}, nil
+	}
+	if hasRestoreAsOf {
+		if _, err := time.Parse(time.RFC3339, restoreAsOf); err != nil {
+			return nil, fmt.Errorf("annotation %s is not a valid RFC-3339 time: %w", annotationRestoreAsOf, err)
+		}
+		return &mover.PopulatorOptions{RestoreAsOf: &restoreAsOf}, nil
+	}
+	return nil, nil
+}
+
+func describeRestoreSelection(obj metav1.Object) string {
+	annotations := obj.GetAnnotations()
+	if snapshotID, ok := annotations[annotationRestoreSnapshotID]; ok {
+		return "snapshot " + snapshotID
+	}
+	return "latest backup as of " + annotations[annotationRestoreAsOf]
+}
+
+// Returns true if the snapshot was marked for cleanup and its owner has since removed its ownership
+// because the snapshot was still in use
+func releasedByOwner(snapshot *snapv1.VolumeSnapshot) bool {
+	return utils.IsMarkedForCleanup(snapshot) && !utils.IsMarkedDoNotDelete(snapshot) &&
+		len(snapshot.GetOwnerReferences()) == 0
+}
+
+// Returns the namespace of the ReplicationDestination referred to by the dataSourceRef of the pvc
+func getDataSourceRefNamespace(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.DataSourceRef.Namespace != nil && *pvc.Spec.DataSourceRef.Namespace != "" {
+		return *pvc.Spec.DataSourceRef.Namespace
+	}
+	return pvc.GetNamespace()
+}
+
+func getReplicationDestinationIndexKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
 func getSnapshotInUseLabelKey(pvc *corev1.PersistentVolumeClaim) string {
 	return fmt.Sprintf("%s%s", utils.SnapInUseByVolumePopulatorLabelPrefix, pvc.UID)
 }
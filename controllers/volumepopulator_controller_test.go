@@ -27,18 +27,22 @@ import (
 	volumepopulatorv1beta1 "github.com/kubernetes-csi/volume-data-source-validator/client/apis/volumepopulator/v1beta1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
 	"github.com/backube/volsync/controllers/utils"
@@ -106,6 +110,60 @@ var _ = Describe("VolumePopulator - helper funcs", func() {
 			})
 		})
 	})
+
+	Describe("getPopulatorRestoreOptions", func() {
+		var pvc *corev1.PersistentVolumeClaim
+		BeforeEach(func() {
+			pvc = &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "vp-pvc",
+					Namespace: "vp-pvc-ns",
+				},
+			}
+		})
+		Context("When a PVC has no restore annotations", func() {
+			It("Should not select a backup", func() {
+				options, err := getPopulatorRestoreOptions(pvc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(options).To(BeNil())
+			})
+		})
+		Context("When a PVC selects a snapshot ID", func() {
+			It("Should select the snapshot, even if a restore-as-of time is also set", func() {
+				pvc.Annotations = map[string]string{
+					annotationRestoreSnapshotID: "4bba301e",
+					annotationRestoreAsOf:       "2023-05-01T10:00:00Z",
+				}
+				options, err := getPopulatorRestoreOptions(pvc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(options).NotTo(BeNil())
+				Expect(options.SnapshotID).NotTo(BeNil())
+				Expect(*options.SnapshotID).To(Equal("4bba301e"))
+				Expect(options.RestoreAsOf).To(BeNil())
+			})
+			It("Should reject an invalid snapshot ID", func() {
+				pvc.Annotations = map[string]string{annotationRestoreSnapshotID: "latest; rm -rf /"}
+				_, err := getPopulatorRestoreOptions(pvc)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("When a PVC selects a restore-as-of time", func() {
+			It("Should select the time", func() {
+				pvc.Annotations = map[string]string{annotationRestoreAsOf: "2023-05-01T10:00:00-04:00"}
+				options, err := getPopulatorRestoreOptions(pvc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(options).NotTo(BeNil())
+				Expect(options.SnapshotID).To(BeNil())
+				Expect(options.RestoreAsOf).NotTo(BeNil())
+				Expect(*options.RestoreAsOf).To(Equal("2023-05-01T10:00:00-04:00"))
+			})
+			It("Should reject a time that is not RFC-3339", func() {
+				pvc.Annotations = map[string]string{annotationRestoreAsOf: "yesterday"}
+				_, err := getPopulatorRestoreOptions(pvc)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
 })
 
 var _ = Describe("VolumePopulator - Predicates", func() {
@@ -920,6 +978,699 @@ var _ = Describe("VolumePopulator", func() {
 					})
 				})
 			})
+
+			Context("When the pvc refers to a replicationdestination in another namespace", func() {
+				var rdNamespace *corev1.Namespace
+				var snap *snapv1.VolumeSnapshot
+
+				BeforeEach(func() {
+					createTestStorageClassWithCacheReload(ctx, storageClassName, false)
+
+					rdNamespace = &corev1.Namespace{
+						ObjectMeta: metav1.ObjectMeta{
+							GenerateName: "volsync-volpop-dr-",
+						},
+					}
+					createWithCacheReload(ctx, k8sClient, rdNamespace)
+
+					rd.Namespace = rdNamespace.GetName()
+					pvc.Spec.DataSourceRef.Namespace = &rdNamespace.Name
+				})
+				AfterEach(func() {
+					sc := &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: storageClassName,
+						},
+					}
+					deleteWithCacheReload(ctx, k8sClient, sc)
+					Expect(k8sClient.Delete(ctx, rdNamespace)).To(Succeed())
+				})
+
+				JustBeforeEach(func() {
+					fakePvcForSnapName := "testing-fake-pvc1"
+					snap = &snapv1.VolumeSnapshot{
+						ObjectMeta: metav1.ObjectMeta{
+							GenerateName: "test-snap-vp-testing-",
+							Namespace:    rdNamespace.GetName(),
+						},
+						Spec: snapv1.VolumeSnapshotSpec{
+							Source: snapv1.VolumeSnapshotSource{
+								PersistentVolumeClaimName: &fakePvcForSnapName,
+							},
+						},
+					}
+					createWithCacheReload(ctx, k8sClient, snap)
+
+					Eventually(func() error {
+						Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rd), rd)).To(Succeed())
+						rd.Status = &volsyncv1alpha1.ReplicationDestinationStatus{
+							LatestImage: &corev1.TypedLocalObjectReference{
+								APIGroup: &snapv1.SchemeGroupVersion.Group,
+								Kind:     "VolumeSnapshot",
+								Name:     snap.GetName(),
+							},
+						}
+						return k8sClient.Status().Update(ctx, rd)
+					}, maxWait, interval).Should(Succeed())
+				})
+
+				Context("When no ReferenceGrant allows it", func() {
+					It("Should not create pvcPrime", func() {
+						Consistently(func() *corev1.PersistentVolumeClaim {
+							pvcPrime, err := GetVolumePopulatorPVCPrime(ctx, k8sClient, pvc)
+							Expect(err).NotTo(HaveOccurred())
+							return pvcPrime
+						}, duration4s, interval).Should(BeNil())
+					})
+				})
+
+				Context("When a ReferenceGrant allows it", func() {
+					BeforeEach(func() {
+						grant := &unstructured.Unstructured{}
+						grant.SetAPIVersion("gateway.networking.k8s.io/v1beta1")
+						grant.SetKind("ReferenceGrant")
+						grant.SetName("allow-volpop")
+						grant.SetNamespace(rdNamespace.GetName())
+						grant.Object["spec"] = map[string]interface{}{
+							"from": []interface{}{map[string]interface{}{
+								"group": "", "kind": "PersistentVolumeClaim", "namespace": namespace.GetName(),
+							}},
+							"to": []interface{}{
+								map[string]interface{}{"group": "volsync.backube", "kind": "ReplicationDestination"},
+								map[string]interface{}{"group": "snapshot.storage.k8s.io", "kind": "VolumeSnapshot"},
+							},
+						}
+						Expect(k8sClient.Create(ctx, grant)).To(Succeed())
+					})
+
+					It("Should create pvcPrime from the snapshot in the other namespace", func() {
+						var pvcPrime *corev1.PersistentVolumeClaim
+						Eventually(func() *corev1.PersistentVolumeClaim {
+							var err error
+							pvcPrime, err = GetVolumePopulatorPVCPrime(ctx, k8sClient, pvc)
+							Expect(err).NotTo(HaveOccurred())
+							return pvcPrime
+						}, maxWait, interval).ShouldNot(BeNil())
+
+						Expect(pvcPrime.GetNamespace()).To(Equal(pvc.GetNamespace()))
+						Expect(pvcPrime.Spec.DataSourceRef).NotTo(BeNil())
+						Expect(pvcPrime.Spec.DataSourceRef.Name).To(Equal(snap.GetName()))
+						Expect(pvcPrime.Spec.DataSourceRef.Namespace).NotTo(BeNil())
+						Expect(*pvcPrime.Spec.DataSourceRef.Namespace).To(Equal(rdNamespace.GetName()))
+
+						// The snapshot is labeled as in use, but cannot be owned by pvcPrime
+						Eventually(func() map[string]string {
+							Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snap), snap)).To(Succeed())
+							return snap.GetLabels()
+						}, maxWait, interval).Should(HaveKeyWithValue(getSnapshotInUseLabelKey(pvc), pvc.GetName()))
+						Consistently(func() []metav1.OwnerReference {
+							Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snap), snap)).To(Succeed())
+							return snap.GetOwnerReferences()
+						}, duration4s, interval).Should(BeEmpty())
+					})
+
+					It("The rd should map to the pvc in the other namespace", func() {
+						Eventually(func() []reconcile.Request {
+							return mapFuncReplicationDestinationToVolumePopulatorPVC(ctx, k8sClient, rd)
+						}, maxWait, interval).Should(ConsistOf(reconcile.Request{
+							NamespacedName: client.ObjectKeyFromObject(pvc),
+						}))
+					})
+				})
+			})
+
+			Context("When the storageClass exists and the pvc selects a restic snapshot to restore", func() {
+				var pvcPrime *corev1.PersistentVolumeClaim
+				var job *batchv1.Job
+
+				BeforeEach(func() {
+					createTestStorageClassWithCacheReload(ctx, storageClassName, false)
+
+					repo := &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "restic-repo",
+							Namespace: namespace.Name,
+						},
+						StringData: map[string]string{
+							"RESTIC_REPOSITORY": "s3:http://minio/bucket",
+							"RESTIC_PASSWORD":   "password",
+						},
+					}
+					createWithCacheReload(ctx, k8sClient, repo)
+
+					rd.Spec = volsyncv1alpha1.ReplicationDestinationSpec{
+						// Paused so that the rd's own mover job does not run
+						Paused: true,
+						Restic: &volsyncv1alpha1.ReplicationDestinationResticSpec{
+							Repository: repo.GetName(),
+							ReplicationDestinationVolumeOptions: volsyncv1alpha1.ReplicationDestinationVolumeOptions{
+								CopyMethod:  volsyncv1alpha1.CopyMethodSnapshot,
+								AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+								Capacity:    &pvcCap,
+							},
+							MoverPodOptions: volsyncv1alpha1.MoverPodOptions{
+								MoverPodLabels: map[string]string{"team": "storage"},
+							},
+						},
+					}
+					pvc.Annotations = map[string]string{annotationRestoreSnapshotID: "4bba301e"}
+				})
+				AfterEach(func() {
+					sc := &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: storageClassName,
+						},
+					}
+					deleteWithCacheReload(ctx, k8sClient, sc)
+				})
+
+				JustBeforeEach(func() {
+					// pvcPrime is created without waiting for the rd to have a latestImage
+					Eventually(func() *corev1.PersistentVolumeClaim {
+						var err error
+						pvcPrime, err = GetVolumePopulatorPVCPrime(ctx, k8sClient, pvc)
+						Expect(err).NotTo(HaveOccurred())
+						return pvcPrime
+					}, maxWait, interval).ShouldNot(BeNil())
+
+					job = &batchv1.Job{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "volsync-dst-" + pvcPrime.GetName(),
+							Namespace: namespace.Name,
+						},
+					}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, client.ObjectKeyFromObject(job), job)
+					}, maxWait, interval).Should(Succeed())
+				})
+
+				It("Should restore the snapshot into an empty pvcPrime", func() {
+					Expect(pvcPrime.Spec.DataSourceRef).To(BeNil())
+					Expect(pvcPrime.Spec.Resources).To(Equal(pvc.Spec.Resources))
+					Expect(pvcPrime.GetAnnotations()).To(HaveKeyWithValue(annotationRestoreSnapshotID, "4bba301e"))
+
+					// The restore job is owned by pvcPrime and writes into it
+					Expect(metav1.IsControlledBy(job, pvcPrime)).To(BeTrue())
+					Expect(job.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{"restore"}))
+					Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+						corev1.EnvVar{Name: "RESTORE_SNAPSHOT_ID", Value: "4bba301e"}))
+					Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(HaveField(
+						"VolumeSource.PersistentVolumeClaim.ClaimName", pvcPrime.GetName())))
+					Expect(*job.Spec.Parallelism).To(Equal(int32(1)))
+					// The pod options of the rd's mover apply to the restore
+					Expect(job.Spec.Template.Labels).To(HaveKeyWithValue("team", "storage"))
+
+					// The pvc should not be rebound before the restore completes
+					Consistently(func() bool {
+						Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcPrime), pvcPrime)).To(Succeed())
+						return metav1.HasAnnotation(pvcPrime.ObjectMeta, annotationRestoreComplete)
+					}, duration4s, interval).Should(BeFalse())
+				})
+
+				Context("When the restore job completes", func() {
+					JustBeforeEach(func() {
+						job.Status.Succeeded = 1
+						job.Status.StartTime = &metav1.Time{
+							Time: time.Now(),
+						}
+						Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+					})
+
+					It("Should rebind the PV of pvcPrime to the pvc", func() {
+						Eventually(func() bool {
+							Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcPrime), pvcPrime)).To(Succeed())
+							return metav1.HasAnnotation(pvcPrime.ObjectMeta, annotationRestoreComplete)
+						}, maxWait, interval).Should(BeTrue())
+
+						volMode := corev1.PersistentVolumeFilesystem
+						pv := &corev1.PersistentVolume{
+							ObjectMeta: metav1.ObjectMeta{
+								GenerateName: "pv-for-volpop-",
+							},
+							Spec: corev1.PersistentVolumeSpec{
+								AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+								Capacity: corev1.ResourceList{
+									corev1.ResourceStorage: pvcCap,
+								},
+								VolumeMode:                    &volMode,
+								StorageClassName:              storageClassName,
+								PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+								PersistentVolumeSource: corev1.PersistentVolumeSource{
+									CSI: &corev1.CSIPersistentVolumeSource{
+										Driver:       "fakedriver",
+										VolumeHandle: "my-vol-handle",
+									},
+								},
+							},
+						}
+						createWithCacheReload(ctx, k8sClient, pv)
+
+						Eventually(func() error {
+							Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcPrime), pvcPrime)).To(Succeed())
+							pvcPrime.Spec.VolumeName = pv.GetName()
+							return k8sClient.Update(ctx, pvcPrime)
+						}, duration10s, interval).Should(Succeed())
+
+						Eventually(func() bool {
+							Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pv), pv)).To(Succeed())
+							return pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.Name == pvc.GetName()
+						}, duration10s, interval).Should(BeTrue())
+						Expect(pv.GetAnnotations()).To(HaveKeyWithValue(annotationPopulatedFrom,
+							pvc.GetNamespace()+"/"+rd.GetName()))
+
+						// The restore job is not started again
+						Consistently(func() bool {
+							err := k8sClient.Get(ctx, client.ObjectKeyFromObject(job), job)
+							return kerrors.IsNotFound(err) || !job.GetDeletionTimestamp().IsZero()
+						}, duration4s, interval).Should(BeTrue())
+					})
+				})
+			})
+
+			Context("When the storageClass exists and the snapshot was taken with a different CSI driver", func() {
+				var pvcPrime *corev1.PersistentVolumeClaim
+				var snap *snapv1.VolumeSnapshot
+				var snapContent *snapv1.VolumeSnapshotContent
+				var sourceStorageClass *storagev1.StorageClass
+				snapSize := resource.MustParse("1Gi")
+
+				BeforeEach(func() {
+					createTestStorageClassWithCacheReload(ctx, storageClassName, false)
+				})
+				AfterEach(func() {
+					sc := &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: storageClassName,
+						},
+					}
+					deleteWithCacheReload(ctx, k8sClient, sc)
+					if sourceStorageClass != nil {
+						deleteWithCacheReload(ctx, k8sClient, sourceStorageClass)
+					}
+					deleteWithCacheReload(ctx, k8sClient, snapContent)
+				})
+
+				JustBeforeEach(func() {
+					fakePvcForSnapName := "testing-fake-pvc1"
+					snap = &snapv1.VolumeSnapshot{
+						ObjectMeta: metav1.ObjectMeta{
+							GenerateName: "test-snap-vp-testing-",
+							Namespace:    namespace.GetName(),
+						},
+						Spec: snapv1.VolumeSnapshotSpec{
+							Source: snapv1.VolumeSnapshotSource{
+								PersistentVolumeClaimName: &fakePvcForSnapName,
+							},
+						},
+					}
+					createWithCacheReload(ctx, k8sClient, snap)
+
+					snapHandle := "my-snap-handle"
+					snapContent = &snapv1.VolumeSnapshotContent{
+						ObjectMeta: metav1.ObjectMeta{
+							GenerateName: "test-snapcontent-vp-testing-",
+						},
+						Spec: snapv1.VolumeSnapshotContentSpec{
+							DeletionPolicy: snapv1.VolumeSnapshotContentDelete,
+							Driver:         "other-driver",
+							Source: snapv1.VolumeSnapshotContentSource{
+								SnapshotHandle: &snapHandle,
+							},
+							VolumeSnapshotRef: corev1.ObjectReference{
+								Name:      snap.GetName(),
+								Namespace: snap.GetNamespace(),
+							},
+						},
+					}
+					createWithCacheReload(ctx, k8sClient, snapContent)
+
+					// Simulate the snapshot controller binding the snapshot to its content
+					Eventually(func() error {
+						Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snap), snap)).To(Succeed())
+						snapContentName := snapContent.GetName()
+						snap.Status = &snapv1.VolumeSnapshotStatus{
+							BoundVolumeSnapshotContentName: &snapContentName,
+							RestoreSize:                    &snapSize,
+						}
+						return k8sClient.Status().Update(ctx, snap)
+					}, maxWait, interval).Should(Succeed())
+
+					Eventually(func() error {
+						Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rd), rd)).To(Succeed())
+						rd.Status = &volsyncv1alpha1.ReplicationDestinationStatus{
+							LatestImage: &corev1.TypedLocalObjectReference{
+								APIGroup: &snapv1.SchemeGroupVersion.Group,
+								Kind:     "VolumeSnapshot",
+								Name:     snap.GetName(),
+							},
+						}
+						return k8sClient.Status().Update(ctx, rd)
+					}, maxWait, interval).Should(Succeed())
+				})
+
+				Context("When there is no storageClass for the driver of the snapshot", func() {
+					It("Should not create pvcPrime", func() {
+						Consistently(func() *corev1.PersistentVolumeClaim {
+							pvcPrime, err := GetVolumePopulatorPVCPrime(ctx, k8sClient, pvc)
+							Expect(err).NotTo(HaveOccurred())
+							return pvcPrime
+						}, duration4s, interval).Should(BeNil())
+					})
+				})
+
+				Context("When there is a storageClass for the driver of the snapshot", func() {
+					var copyPVC *corev1.PersistentVolumeClaim
+					var job *batchv1.Job
+
+					BeforeEach(func() {
+						sourceStorageClass = &storagev1.StorageClass{
+							ObjectMeta: metav1.ObjectMeta{
+								GenerateName: "vp-test-copy-storageclass-",
+							},
+							Provisioner: "other-driver",
+						}
+						createWithCacheReload(ctx, k8sClient, sourceStorageClass)
+					})
+
+					JustBeforeEach(func() {
+						Eventually(func() *corev1.PersistentVolumeClaim {
+							var err error
+							pvcPrime, err = GetVolumePopulatorPVCPrime(ctx, k8sClient, pvc)
+							Expect(err).NotTo(HaveOccurred())
+							return pvcPrime
+						}, maxWait, interval).ShouldNot(BeNil())
+
+						copyPVC = &corev1.PersistentVolumeClaim{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "vs-copy-" + string(pvc.GetUID()),
+								Namespace: namespace.Name,
+							},
+						}
+						Eventually(func() error {
+							return k8sClient.Get(ctx, client.ObjectKeyFromObject(copyPVC), copyPVC)
+						}, maxWait, interval).Should(Succeed())
+
+						job = &batchv1.Job{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "vs-copy-" + string(pvc.GetUID()),
+								Namespace: namespace.Name,
+							},
+						}
+						Eventually(func() error {
+							return k8sClient.Get(ctx, client.ObjectKeyFromObject(job), job)
+						}, maxWait, interval).Should(Succeed())
+					})
+
+					It("Should copy the snapshot into an empty pvcPrime", func() {
+						Expect(pvcPrime.Spec.DataSourceRef).To(BeNil())
+						Expect(pvcPrime.Spec.StorageClassName).To(Equal(pvc.Spec.StorageClassName))
+						Expect(pvcPrime.GetAnnotations()).To(HaveKeyWithValue(annotationCopyFromSnapshot, snap.GetName()))
+
+						// The snapshot is provisioned with a storageclass of its own driver
+						Expect(metav1.IsControlledBy(copyPVC, pvcPrime)).To(BeTrue())
+						Expect(*copyPVC.Spec.StorageClassName).To(Equal(sourceStorageClass.GetName()))
+						Expect(copyPVC.Spec.DataSourceRef).NotTo(BeNil())
+						Expect(copyPVC.Spec.DataSourceRef.Kind).To(Equal("VolumeSnapshot"))
+						Expect(copyPVC.Spec.DataSourceRef.Name).To(Equal(snap.GetName()))
+						Expect(copyPVC.Spec.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceStorage, snapSize))
+
+						// The copy job reads from the temporary pvc and writes into pvcPrime
+						Expect(metav1.IsControlledBy(job, pvcPrime)).To(BeTrue())
+						Expect(job.Spec.Template.Spec.Containers[0].Command[0]).To(Equal("rsync"))
+						Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(HaveField(
+							"VolumeSource.PersistentVolumeClaim.ClaimName", copyPVC.GetName())))
+						Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(HaveField(
+							"VolumeSource.PersistentVolumeClaim.ClaimName", pvcPrime.GetName())))
+
+						// The rd's mover has no pod options
+						Expect(job.Spec.Template.Annotations).NotTo(HaveKey("volsync.backube/applied-pod-options"))
+
+						// The pvc should not be rebound before the copy completes
+						Consistently(func() bool {
+							Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcPrime), pvcPrime)).To(Succeed())
+							return metav1.HasAnnotation(pvcPrime.ObjectMeta, annotationRestoreComplete)
+						}, duration4s, interval).Should(BeFalse())
+					})
+
+					Context("When the rd's mover has pod options", func() {
+						BeforeEach(func() {
+							rd.Spec = volsyncv1alpha1.ReplicationDestinationSpec{
+								// Paused so that the rd's own mover job does not run
+								Paused: true,
+								RsyncTLS: &volsyncv1alpha1.ReplicationDestinationRsyncTLSSpec{
+									MoverPodOptions: volsyncv1alpha1.MoverPodOptions{
+										MoverPodLabels:   map[string]string{"team": "storage"},
+										MoverTolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+									},
+								},
+							}
+						})
+
+						It("Should apply them to the copy job", func() {
+							Expect(job.Spec.Template.Labels).To(HaveKeyWithValue("team", "storage"))
+							Expect(job.Spec.Template.Spec.Tolerations).To(ContainElement(
+								corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists}))
+						})
+					})
+
+					Context("When the copy job completes", func() {
+						JustBeforeEach(func() {
+							job.Status.Succeeded = 1
+							job.Status.StartTime = &metav1.Time{
+								Time: time.Now(),
+							}
+							Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+						})
+
+						It("Should remove the temporary pvc and rebind the PV of pvcPrime to the pvc", func() {
+							Eventually(func() bool {
+								Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcPrime), pvcPrime)).To(Succeed())
+								return metav1.HasAnnotation(pvcPrime.ObjectMeta, annotationRestoreComplete)
+							}, maxWait, interval).Should(BeTrue())
+
+							Eventually(func() bool {
+								err := k8sClient.Get(ctx, client.ObjectKeyFromObject(copyPVC), copyPVC)
+								return kerrors.IsNotFound(err) || !copyPVC.GetDeletionTimestamp().IsZero()
+							}, maxWait, interval).Should(BeTrue())
+
+							volMode := corev1.PersistentVolumeFilesystem
+							pv := &corev1.PersistentVolume{
+								ObjectMeta: metav1.ObjectMeta{
+									GenerateName: "pv-for-volpop-",
+								},
+								Spec: corev1.PersistentVolumeSpec{
+									AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+									Capacity: corev1.ResourceList{
+										corev1.ResourceStorage: pvcCap,
+									},
+									VolumeMode:                    &volMode,
+									StorageClassName:              storageClassName,
+									PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+									PersistentVolumeSource: corev1.PersistentVolumeSource{
+										CSI: &corev1.CSIPersistentVolumeSource{
+											Driver:       "my-provisioner",
+											VolumeHandle: "my-vol-handle",
+										},
+									},
+								},
+							}
+							createWithCacheReload(ctx, k8sClient, pv)
+
+							Eventually(func() error {
+								Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcPrime), pvcPrime)).To(Succeed())
+								pvcPrime.Spec.VolumeName = pv.GetName()
+								return k8sClient.Update(ctx, pvcPrime)
+							}, duration10s, interval).Should(Succeed())
+
+							Eventually(func() bool {
+								Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pv), pv)).To(Succeed())
+								return pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.Name == pvc.GetName()
+							}, duration10s, interval).Should(BeTrue())
+							Expect(pv.GetAnnotations()).To(HaveKeyWithValue(annotationPopulatedFrom,
+								pvc.GetNamespace()+"/"+snap.GetName()))
+						})
+					})
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("VolumePopulator - cleanup of a snapshot in another namespace", func() {
+	var rdNamespace *corev1.Namespace
+	var snap *snapv1.VolumeSnapshot
+	var pvc1, pvc2 *corev1.PersistentVolumeClaim
+	var r *VolumePopulatorReconciler
+
+	newPVC := func(name string) *corev1.PersistentVolumeClaim {
+		apiGroup := volsyncv1alpha1.GroupVersion.Group
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "restored-app",
+				UID:       types.UID(utilrand.String(12)),
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				DataSourceRef: &corev1.TypedObjectReference{
+					APIGroup:  &apiGroup,
+					Kind:      "ReplicationDestination",
+					Name:      "rd",
+					Namespace: &rdNamespace.Name,
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		rdNamespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "volsync-volpop-cleanup-",
+			},
+		}
+		createWithCacheReload(ctx, k8sClient, rdNamespace)
+		DeferCleanup(k8sClient.Delete, ctx, rdNamespace)
+
+		pvc1 = newPVC("pvc-1")
+		pvc2 = newPVC("pvc-2")
+
+		// The snapshot was released by the rd while both pvcs were being populated from it
+		fakePvcForSnapName := "testing-fake-pvc1"
+		snap = &snapv1.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-snap-vp-testing-",
+				Namespace:    rdNamespace.GetName(),
+				Labels: map[string]string{
+					getSnapshotInUseLabelKey(pvc1): pvc1.GetName(),
+					getSnapshotInUseLabelKey(pvc2): pvc2.GetName(),
+				},
+			},
+			Spec: snapv1.VolumeSnapshotSpec{
+				Source: snapv1.VolumeSnapshotSource{
+					PersistentVolumeClaimName: &fakePvcForSnapName,
+				},
+			},
+		}
+		utils.MarkForCleanup(rdNamespace, snap)
+		createWithCacheReload(ctx, k8sClient, snap)
+
+		r = &VolumePopulatorReconciler{Client: k8sClient}
+	})
+
+	It("Should only delete the snapshot once no pvc is populated from it", func() {
+		logger := ctrl.Log.WithName("test")
+
+		Expect(r.cleanup(ctx, logger, pvc1, nil)).To(Succeed())
+		Eventually(func() map[string]string {
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(snap), snap)).To(Succeed())
+			return snap.GetLabels()
+		}, maxWait, interval).ShouldNot(HaveKey(getSnapshotInUseLabelKey(pvc1)))
+		Expect(snap.GetLabels()).To(HaveKey(getSnapshotInUseLabelKey(pvc2)))
+		Expect(snap.GetDeletionTimestamp().IsZero()).To(BeTrue())
+
+		Expect(r.cleanup(ctx, logger, pvc2, nil)).To(Succeed())
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(snap), snap)
+			return kerrors.IsNotFound(err) || !snap.GetDeletionTimestamp().IsZero()
+		}, maxWait, interval).Should(BeTrue())
+	})
+})
+
+var _ = Describe("VolumePopulator - copy source of a pvc without a storageClassName", func() {
+	var snap *snapv1.VolumeSnapshot
+	var snapContent *snapv1.VolumeSnapshotContent
+	var defaultStorageClass, sourceStorageClass *storagev1.StorageClass
+	var pvc *corev1.PersistentVolumeClaim
+	var r *VolumePopulatorReconciler
+
+	BeforeEach(func() {
+		snapHandle := "my-snap-handle"
+		snapContent = &snapv1.VolumeSnapshotContent{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "test-snapcontent-vp-testing-",
+			},
+			Spec: snapv1.VolumeSnapshotContentSpec{
+				DeletionPolicy: snapv1.VolumeSnapshotContentDelete,
+				Driver:         "other-driver",
+				Source: snapv1.VolumeSnapshotContentSource{
+					SnapshotHandle: &snapHandle,
+				},
+				VolumeSnapshotRef: corev1.ObjectReference{
+					Name:      "snap",
+					Namespace: "restored-app",
+				},
+			},
+		}
+		createWithCacheReload(ctx, k8sClient, snapContent)
+		DeferCleanup(deleteWithCacheReload, ctx, k8sClient, snapContent)
+
+		snapContentName := snapContent.GetName()
+		snap = &snapv1.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "snap",
+				Namespace: "restored-app",
+			},
+			Status: &snapv1.VolumeSnapshotStatus{
+				BoundVolumeSnapshotContentName: &snapContentName,
+			},
+		}
+
+		sourceStorageClass = &storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "vp-test-copy-storageclass-",
+			},
+			Provisioner: "other-driver",
+		}
+		createWithCacheReload(ctx, k8sClient, sourceStorageClass)
+		DeferCleanup(deleteWithCacheReload, ctx, k8sClient, sourceStorageClass)
+
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pvc",
+				Namespace: "restored-app",
+			},
+		}
+
+		r = &VolumePopulatorReconciler{Client: k8sClient}
+	})
+
+	JustBeforeEach(func() {
+		createWithCacheReload(ctx, k8sClient, defaultStorageClass)
+		DeferCleanup(deleteWithCacheReload, ctx, k8sClient, defaultStorageClass)
+	})
+
+	Context("When the default storageClass has a different provisioner than the snapshot", func() {
+		BeforeEach(func() {
+			defaultStorageClass = &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "vp-test-default-storageclass-",
+					Annotations:  map[string]string{annotationIsDefaultClass: "true"},
+				},
+				Provisioner: "my-provisioner",
+			}
+		})
+
+		It("Should copy the snapshot with a storageClass of its driver", func() {
+			name, result := r.getCopySourceStorageClassName(ctx, ctrl.Log.WithName("test"), pvc, snap)
+			Expect(result).To(BeNil())
+			Expect(name).To(Equal(sourceStorageClass.GetName()))
+		})
+	})
+
+	Context("When the default storageClass has the provisioner of the snapshot", func() {
+		BeforeEach(func() {
+			defaultStorageClass = &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "vp-test-default-storageclass-",
+					Annotations:  map[string]string{annotationIsDefaultClass: "true"},
+				},
+				Provisioner: "other-driver",
+			}
+		})
+
+		It("Should provision the pvc from the snapshot directly", func() {
+			name, result := r.getCopySourceStorageClassName(ctx, ctrl.Log.WithName("test"), pvc, snap)
+			Expect(result).To(BeNil())
+			Expect(name).To(BeEmpty())
 		})
 	})
 })
@@ -0,0 +1,399 @@
+/*
+Copyright 2023 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+const (
+	// Annotations on pvcPrime when the snapshot is copied into it rather than provisioned directly
+	annotationCopyFromSnapshot     string = utils.VolsyncLabelPrefix + "/copy-from-snapshot"
+	annotationCopyFromStorageClass string = utils.VolsyncLabelPrefix + "/copy-from-storageclass"
+
+	populatorCopyPrefix        string = "vs-copy"
+	annotationIsDefaultClass   string = "storageclass.kubernetes.io/is-default-class"
+	populatorCopyBackoffLimit  int32  = 8
+	populatorCopySourcePath    string = "/source"
+	populatorCopyTargetPath    string = "/target"
+	populatorCopySourceDevice  string = "/dev/source"
+	populatorCopyTargetDevice  string = "/dev/target"
+	populatorCopySourceVolume  string = "source"
+	populatorCopyTargetVolume  string = "target"
+	populatorCopyContainerName string = "copy"
+	populatorCopyNodeNameField string = "metadata.name"
+)
+
+// Determines whether the snapshot needs to be copied into the PVC because it cannot be provisioned directly
+// with the storage class of the PVC. Returns the name of a storage class that can provision the snapshot in
+// that case, or an empty string if the snapshot can be used as the dataSourceRef of pvcPrime.
+func (r *VolumePopulatorReconciler) getCopySourceStorageClassName(ctx context.Context, logger logr.Logger,
+	pvc *corev1.PersistentVolumeClaim, snapshot *snapv1.VolumeSnapshot) (string, *vpResult) {
+	if snapshot.Status == nil || snapshot.Status.BoundVolumeSnapshotContentName == nil {
+		// Not able to tell which driver created the snapshot yet - provisioning will wait for it to be ready
+		return "", nil
+	}
+
+	content := &snapv1.VolumeSnapshotContent{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: *snapshot.Status.BoundVolumeSnapshotContentName}, content)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", &vpResult{ctrl.Result{}, err}
+	}
+
+	// The data can only be copied between volumes of the same mode
+	sourceVolumeMode := corev1.PersistentVolumeFilesystem
+	if content.Spec.SourceVolumeMode != nil {
+		sourceVolumeMode = *content.Spec.SourceVolumeMode
+	}
+	targetVolumeMode := corev1.PersistentVolumeFilesystem
+	if pvc.Spec.VolumeMode != nil {
+		targetVolumeMode = *pvc.Spec.VolumeMode
+	}
+	if sourceVolumeMode != targetVolumeMode {
+		copyErr := fmt.Errorf("snapshot %s is of a %s volume and cannot populate a %s volume",
+			snapshot.GetName(), sourceVolumeMode, targetVolumeMode)
+		logger.Error(copyErr, "Unable to populate volume")
+		r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCPopulatorError,
+			"Unable to populate volume: %s", copyErr)
+		// Do not return error here - no use retrying
+		return "", &vpResult{ctrl.Result{}, nil}
+	}
+
+	storageClasses := &storagev1.StorageClassList{}
+	if err := r.Client.List(ctx, storageClasses); err != nil {
+		return "", &vpResult{ctrl.Result{}, err}
+	}
+
+	var targetStorageClassName string
+	if pvc.Spec.StorageClassName != nil {
+		targetStorageClassName = *pvc.Spec.StorageClassName
+	} else if defaultStorageClass := getDefaultStorageClass(storageClasses.Items); defaultStorageClass != nil {
+		// The pvc will be provisioned with the cluster's default storage class
+		targetStorageClassName = defaultStorageClass.GetName()
+	} else {
+		// No storage class will provision the pvc, it can't be populated either way
+		return "", nil
+	}
+
+	var copyStorageClass *storagev1.StorageClass
+	for i := range storageClasses.Items {
+		sc := &storageClasses.Items[i]
+		if sc.GetName() == targetStorageClassName {
+			if sc.Provisioner == content.Spec.Driver {
+				// The snapshot can be provisioned directly
+				return "", nil
+			}
+			continue
+		}
+		if sc.Provisioner != content.Spec.Driver {
+			continue
+		}
+		// Prefer the default storage class of the driver, otherwise pick the first one by name
+		if copyStorageClass == nil || isDefaultStorageClass(sc) ||
+			(!isDefaultStorageClass(copyStorageClass) && sc.GetName() < copyStorageClass.GetName()) {
+			copyStorageClass = sc
+		}
+	}
+	if copyStorageClass == nil {
+		copyErr := fmt.Errorf("no storageclass found for the CSI driver %s of snapshot %s",
+			content.Spec.Driver, snapshot.GetName())
+		logger.Error(copyErr, "Unable to populate volume")
+		r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCPopulatorError,
+			"Unable to populate volume: %s", copyErr)
+		// Do not return error here - no use retrying
+		return "", &vpResult{ctrl.Result{}, nil}
+	}
+
+	logger.Info("Snapshot was taken with a different CSI driver, its data will be copied into the volume",
+		"driver", content.Spec.Driver, "storageclass", copyStorageClass.GetName())
+	return copyStorageClass.GetName(), nil
+}
+
+// Copies the snapshot selected on pvcPrime (if any) into it via a temporary volume provisioned from the
+// snapshot. Returns nil once pvcPrime is ready to be rebound.
+//
+//nolint:funlen
+func (r *VolumePopulatorReconciler) reconcileCopy(ctx context.Context, logger logr.Logger,
+	pvc, pvcPrime *corev1.PersistentVolumeClaim) *vpResult {
+	snapshotName, ok := pvcPrime.Annotations[annotationCopyFromSnapshot]
+	if !ok || metav1.HasAnnotation(pvcPrime.ObjectMeta, annotationRestoreComplete) {
+		return nil
+	}
+	logger = logger.WithValues("snapshot name", snapshotName)
+
+	privilegedMoverOk, err := utils.PrivilegedMoversOk(ctx, r.Client, logger, pvcPrime.GetNamespace())
+	if err != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+	sa, err := utils.NewSAHandler(r.Client, pvcPrime, false, privilegedMoverOk, nil).Reconcile(ctx, logger)
+	if sa == nil || err != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+
+	copyPVC, err := r.ensureCopySourcePVC(ctx, logger, pvc, pvcPrime, snapshotName)
+	if err != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+
+	// The copy job runs with the pod options of the replicationdestination's mover
+	var podOptions *volsyncv1alpha1.MoverPodOptions
+	rd, err := r.getReplicationDestinationFromDataSourceRef(ctx, logger, pvc)
+	if client.IgnoreNotFound(err) != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+	if err == nil {
+		podOptions = utils.DestinationMoverPodOptions(rd)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getCopyName(pvc),
+			Namespace: pvcPrime.GetNamespace(),
+		},
+	}
+	logger = logger.WithValues("job", client.ObjectKeyFromObject(job))
+
+	_, err = utils.CreateOrUpdateDeleteOnImmutableErr(ctx, r.Client, job, logger, func() error {
+		if err := ctrl.SetControllerReference(pvcPrime, job, r.Client.Scheme()); err != nil {
+			logger.Error(err, utils.ErrUnableToSetControllerRef)
+			return err
+		}
+		utils.SetOwnedByVolSync(job)
+		job.Spec.Template.ObjectMeta.Name = job.Name
+		utils.SetOwnedByVolSync(&job.Spec.Template)
+		job.Spec.BackoffLimit = ptr.To(populatorCopyBackoffLimit)
+
+		container := corev1.Container{
+			Name:  populatorCopyContainerName,
+			Image: r.CopyContainerImage,
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: ptr.To(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+				},
+				Privileged:             ptr.To(false),
+				ReadOnlyRootFilesystem: ptr.To(true),
+			},
+		}
+		if pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+			container.Command = []string{"dd", "if=" + populatorCopySourceDevice, "of=" + populatorCopyTargetDevice,
+				"bs=4M", "conv=fsync"}
+			container.VolumeDevices = []corev1.VolumeDevice{
+				{Name: populatorCopySourceVolume, DevicePath: populatorCopySourceDevice},
+				{Name: populatorCopyTargetVolume, DevicePath: populatorCopyTargetDevice},
+			}
+		} else {
+			// Ownership and permissions can only be preserved by a privileged mover
+			rsyncOpts := "-rltShHx"
+			if privilegedMoverOk {
+				rsyncOpts = "-aAhHSx"
+			}
+			container.Command = []string{"rsync", rsyncOpts, "--numeric-ids",
+				populatorCopySourcePath + "/", populatorCopyTargetPath + "/"}
+			container.VolumeMounts = []corev1.VolumeMount{
+				{Name: populatorCopySourceVolume, MountPath: populatorCopySourcePath, ReadOnly: true},
+				{Name: populatorCopyTargetVolume, MountPath: populatorCopyTargetPath},
+			}
+		}
+		logger.Info("mover permissions", "privileged-mover", privilegedMoverOk)
+		if privilegedMoverOk {
+			container.SecurityContext.Capabilities.Add = []corev1.Capability{
+				"DAC_OVERRIDE", // Read/write all files
+				"CHOWN",        // chown files
+				"FOWNER",       // Set permission bits & times
+			}
+			container.SecurityContext.RunAsUser = ptr.To[int64](0)
+		}
+
+		job.Spec.Template.Spec.Containers = []corev1.Container{container}
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		job.Spec.Template.Spec.ServiceAccountName = sa.GetName()
+		job.Spec.Template.Spec.Volumes = []corev1.Volume{
+			{Name: populatorCopySourceVolume, VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: copyPVC.GetName(),
+					ReadOnly:  true,
+				}},
+			},
+			{Name: populatorCopyTargetVolume, VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: pvcPrime.GetName(),
+				}},
+			},
+		}
+		// pvcPrime needs to be provisioned on the node selected for the PVC
+		job.Spec.Template.Spec.Affinity = nil
+		if nodeName, ok := pvcPrime.Annotations[annotationSelectedNode]; ok {
+			job.Spec.Template.Spec.Affinity = &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchFields: []corev1.NodeSelectorRequirement{{
+								Key:      populatorCopyNodeNameField,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{nodeName},
+							}},
+						}},
+					},
+				},
+			}
+		}
+		utils.ApplyMoverPodOptions(&job.Spec.Template, podOptions)
+		return nil
+	})
+	// If Job had failed, delete it so it can be recreated
+	if job.Spec.BackoffLimit != nil && job.Status.Failed >= *job.Spec.BackoffLimit {
+		logger.Info("deleting job -- backoff limit reached")
+		r.EventRecorder.Eventf(pvc, corev1.EventTypeWarning, volsyncv1alpha1.EvRVolPopPVCPopulatorError,
+			"Copying snapshot %s into populator pvc failed, retrying", snapshotName)
+		err = r.Client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		return &vpResult{ctrl.Result{}, err}
+	}
+	if err != nil {
+		logger.Error(err, "reconcile failed")
+		return &vpResult{ctrl.Result{}, err}
+	}
+	// Stop here if the job hasn't completed yet - the job watch will reconcile again
+	if job.Status.Succeeded == 0 {
+		return &vpResult{ctrl.Result{}, nil}
+	}
+	logger.Info("job completed")
+
+	// Record the completion before removing the job so the copy is not run again
+	metav1.SetMetaDataAnnotation(&pvcPrime.ObjectMeta, annotationRestoreComplete, "true")
+	if err := r.Client.Update(ctx, pvcPrime); err != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+	r.EventRecorder.Eventf(pvc, corev1.EventTypeNormal, volsyncv1alpha1.EvRVolPopPVCCopyComplete,
+		"Copied snapshot %s into populator pvc", snapshotName)
+
+	// Free up the temporary volume right away, everything else is garbage collected along with pvcPrime
+	if err := r.Client.Delete(ctx, job,
+		client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+	if err := r.Client.Delete(ctx, copyPVC); client.IgnoreNotFound(err) != nil {
+		return &vpResult{ctrl.Result{}, err}
+	}
+
+	return nil
+}
+
+// Ensures the temporary PVC that provisions the snapshot with a storage class of its own CSI driver
+func (r *VolumePopulatorReconciler) ensureCopySourcePVC(ctx context.Context, logger logr.Logger,
+	pvc, pvcPrime *corev1.PersistentVolumeClaim, snapshotName string) (*corev1.PersistentVolumeClaim, error) {
+	copyPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getCopyName(pvc),
+			Namespace: pvcPrime.GetNamespace(),
+		},
+	}
+	err := r.Client.Get(ctx, client.ObjectKeyFromObject(copyPVC), copyPVC)
+	if err == nil {
+		return copyPVC, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	// The snapshot is in the namespace of the ReplicationDestination
+	sourceNamespace := getDataSourceRefNamespace(pvc)
+	snapshot := &snapv1.VolumeSnapshot{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: snapshotName, Namespace: sourceNamespace}, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	// The temporary volume needs to be at least as large as the volume the snapshot was taken of
+	resources := pvc.Spec.Resources
+	if snapshot.Status != nil && snapshot.Status.RestoreSize != nil {
+		resources = corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: *snapshot.Status.RestoreSize},
+		}
+	}
+
+	copyPVC.Spec = corev1.PersistentVolumeClaimSpec{
+		AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		Resources:        resources,
+		StorageClassName: ptr.To(pvcPrime.Annotations[annotationCopyFromStorageClass]),
+		VolumeMode:       pvc.Spec.VolumeMode,
+		DataSourceRef: &corev1.TypedObjectReference{
+			APIGroup: &snapv1.SchemeGroupVersion.Group,
+			Kind:     "VolumeSnapshot",
+			Name:     snapshotName,
+		},
+	}
+	if sourceNamespace != pvc.GetNamespace() {
+		copyPVC.Spec.DataSourceRef.Namespace = &sourceNamespace
+	}
+	// Owned by pvcPrime - will be cleaned up via gc along with it
+	if err := ctrl.SetControllerReference(pvcPrime, copyPVC, r.Client.Scheme()); err != nil {
+		logger.Error(err, utils.ErrUnableToSetControllerRef)
+		return nil, err
+	}
+	utils.SetOwnedByVolSync(copyPVC)
+
+	logger.Info("Creating temp pvc to copy the snapshot from", "pvc name", copyPVC.GetName())
+	if err := r.Client.Create(ctx, copyPVC); err != nil {
+		return nil, err
+	}
+	return copyPVC, nil
+}
+
+func getCopyName(pvc *corev1.PersistentVolumeClaim) string {
+	return populatorCopyPrefix + "-" + string(pvc.UID)
+}
+
+// Returns the default storage class of the cluster, or nil if there is none. Like Kubernetes, the newest one
+// is used if several storage classes are marked as the default.
+func getDefaultStorageClass(storageClasses []storagev1.StorageClass) *storagev1.StorageClass {
+	var defaultStorageClass *storagev1.StorageClass
+	for i := range storageClasses {
+		sc := &storageClasses[i]
+		if !isDefaultStorageClass(sc) {
+			continue
+		}
+		if defaultStorageClass == nil || defaultStorageClass.CreationTimestamp.Before(&sc.CreationTimestamp) {
+			defaultStorageClass = sc
+		}
+	}
+	return defaultStorageClass
+}
+
+func isDefaultStorageClass(sc *storagev1.StorageClass) bool {
+	return sc.Annotations[annotationIsDefaultClass] == "true"
+}
@@ -17,6 +17,8 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
 	"fmt"
@@ -24,9 +26,12 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
 
 	"github.com/dop251/diskrsync"
 	"github.com/dop251/spgz"
@@ -37,9 +42,21 @@ import (
 
 var volsyncVersion = "0.0.0"
 
+// Upper bound of the delay between attempts to connect to the remote
+const maxDialBackoff = 30 * time.Second
+
 type options struct {
-	noCompress bool
-	verbose    bool
+	noCompress       bool
+	verbose          bool
+	bwLimit          int // KiB/s, 0 means unlimited
+	checkpointFile   string
+	transferID       string
+	retryTimeout     time.Duration
+	progressInterval time.Duration
+	verify           bool
+	verifyOnly       bool
+	streams          int
+	tls              tlsOptions
 }
 
 func usage() {
@@ -53,6 +70,8 @@ func main() {
 		sourceMode    = flag.Bool("source", false, "Source mode")
 		targetMode    = flag.Bool("target", false, "Target mode")
 		targetAddress = flag.String("target-address", "", "address of the server, source only")
+		sourceAddress = flag.String("source-address", "", "address of a listening source, target only")
+		listen        = flag.Bool("listen", false, "wait for the target to connect, source only")
 		controlFile   = flag.String("control-file", "", "name and path to file to write when finished")
 		port          = flag.Int("port", 8000, "port to listen on or connect to")
 	)
@@ -60,6 +79,29 @@ func main() {
 
 	flag.BoolVar(&opts.noCompress, "no-compress", false, "Store target as a raw file")
 	flag.BoolVar(&opts.verbose, "verbose", true, "Print statistics, progress, and some debug info")
+	flag.IntVar(&opts.bwLimit, "bwlimit", 0, "Limit the rate of data sent to the remote, in KiB/s (0 means unlimited)")
+	flag.StringVar(&opts.checkpointFile, "checkpoint-file", "",
+		"File used to record the progress of the transfer so it can be resumed, target only")
+	flag.StringVar(&opts.transferID, "transfer-id", "",
+		"Identifies the data being sent so the target can resume a previous attempt, source only")
+	flag.DurationVar(&opts.retryTimeout, "retry-timeout", 5*time.Minute,
+		"How long to keep trying to connect to the remote before giving up")
+	flag.DurationVar(&opts.progressInterval, "progress-interval", 10*time.Second,
+		"How often to print a progress report")
+	flag.BoolVar(&opts.verify, "verify", false,
+		"Compare the hashes of the source and target once the transfer is complete, source only")
+	flag.BoolVar(&opts.verifyOnly, "verify-only", false,
+		"Compare the hashes of the source and target without transferring any data, source only")
+	flag.IntVar(&opts.streams, "streams", 1,
+		"Number of segments to hash and transfer concurrently, each over its own connection. "+
+			"Must be the same on both sides")
+	flag.StringVar(&opts.tls.pskFile, "tls-psk-file", "",
+		"Secure the connection with TLS, authenticating both sides with the pre-shared key in this file")
+	flag.StringVar(&opts.tls.certFile, "tls-cert-file", "", "Certificate used to secure the connection with TLS")
+	flag.StringVar(&opts.tls.keyFile, "tls-key-file", "", "Private key of the TLS certificate")
+	flag.StringVar(&opts.tls.caFile, "tls-ca-file", "", "CA used to verify the certificate of the remote")
+	flag.StringVar(&opts.tls.serverName, "tls-server-name", "",
+		"Name to verify in the certificate of the remote, defaults to the remote address")
 
 	zapopts := zap.Options{
 		Development: true,
@@ -76,13 +118,25 @@ func main() {
 
 	logger.Info(fmt.Sprintf("diskrsync-tls (for VolSync) Version: %s", volsyncVersion))
 
+	// The result of the verification, if one is performed
+	var verification *verificationResult
+
 	if *sourceMode && !*targetMode {
-		if targetAddress == nil || *targetAddress == "" {
-			fmt.Fprintf(os.Stderr, "target-address must be specified with source flag\n")
+		if !*listen && (targetAddress == nil || *targetAddress == "") {
+			fmt.Fprintf(os.Stderr, "target-address or listen must be specified with source flag\n")
 			usage()
 			os.Exit(1)
 		}
-		if err := connectToTarget(os.Args[1], *targetAddress, *port, &opts, logger); err != nil {
+		if *controlFile != "" {
+			defer func() {
+				logger.Info("Writing control file", "file", *controlFile)
+				if err := createControlFile(*controlFile, verification); err != nil {
+					logger.Error(err, "Unable to create control file")
+				}
+			}()
+		}
+		var err error
+		if verification, err = connectToTarget(os.Args[1], *targetAddress, *port, &opts, logger); err != nil {
 			logger.Error(err, "Unable to connect to target", "source file", os.Args[1], "target address", *targetAddress)
 			os.Exit(1)
 		}
@@ -94,11 +148,12 @@ func main() {
 		}
 		defer func() {
 			logger.Info("Writing control file", "file", *controlFile)
-			if err := createControlFile(*controlFile); err != nil {
+			if err := createControlFile(*controlFile, verification); err != nil {
 				logger.Error(err, "Unable to create control file")
 			}
 		}()
-		if err := startServer(os.Args[1], *port, &opts, logger); err != nil {
+		var err error
+		if verification, err = startServer(os.Args[1], *sourceAddress, *port, &opts, logger); err != nil {
 			logger.Error(err, "Unable to start server to write to file", "target file", os.Args[1])
 			os.Exit(1)
 		}
@@ -107,21 +162,35 @@ func main() {
 		usage()
 		os.Exit(1)
 	}
+	if verification != nil {
+		if err := verification.print(os.Stdout); err != nil {
+			logger.Error(err, "Unable to print the verification result")
+		}
+	}
 	logger.Info("Successfully completed sync")
 }
 
-func createControlFile(fileName string) error {
+// createControlFile signals that the transfer has finished. The result of the
+// verification, if one was performed, is written to the file.
+func createControlFile(fileName string, verification *verificationResult) error {
 	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
 		return err
 	}
-	_, err := os.Create(fileName)
-	return err
+	var data []byte
+	if verification != nil {
+		var err error
+		if data, err = verification.marshal(maxControlFileSize); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(fileName, data, 0600)
 }
 
-func connectToTarget(sourceFile, targetAddress string, port int, opts *options, logger logr.Logger) error {
+func connectToTarget(sourceFile, targetAddress string, port int, opts *options,
+	logger logr.Logger) (*verificationResult, error) {
 	f, err := os.Open(sourceFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	logger.Info("Opened filed", "file", sourceFile)
 	defer f.Close()
@@ -131,7 +200,7 @@ func connectToTarget(sourceFile, targetAddress string, port int, opts *options,
 	sf, err := spgz.NewFromFile(f, os.O_RDONLY)
 	if err != nil {
 		if !errors.Is(err, spgz.ErrInvalidFormat) {
-			return err
+			return nil, err
 		}
 		logger.Info("Not an spgz file")
 		src = f
@@ -142,54 +211,233 @@ func connectToTarget(sourceFile, targetAddress string, port int, opts *options,
 
 	size, err := src.Seek(0, io.SeekEnd)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	_, err = src.Seek(0, io.SeekStart)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	conn, err := net.Dial("tcp", fmt.Sprintf("%s:%d", targetAddress, port))
+	ra, ok := src.(io.ReaderAt)
+	if !ok {
+		return nil, fmt.Errorf("source does not support random access")
+	}
+	transferID, err := transferIDHash(opts.transferID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	logger.Info("source", "size", size)
-	calcProgress := &progress{
-		progressType: "calc progress",
-		logger:       logger,
+	s := &resumableSource{
+		reader:          ra,
+		size:            size,
+		segmentSize:     defaultSegmentSize,
+		verifyRangeSize: defaultVerifyRangeSize,
+		streams:         opts.streams,
+		transferID:      transferID,
+		opts:            opts,
+		logger:          logger,
+		progress:        newTransferProgress(size),
 	}
-	syncProgress := &progress{
-		progressType: "sync progress",
-		logger:       logger,
+
+	c, err := newConnector(targetAddress, port, opts, logger)
+	if err != nil {
+		return nil, err
 	}
-	err = diskrsync.Source(src, size, conn, conn, true, opts.verbose, calcProgress, syncProgress)
-	cerr := conn.Close()
-	if err == nil {
-		err = cerr
+	defer c.close()
+	defer s.progress.run(opts.progressInterval)()
+	if err := runStreams(c, logger, s.streamsToRun()); err != nil {
+		return nil, err
+	}
+	return s.verification, nil
+}
+
+// connector dials the remote address if one is provided, otherwise it waits
+// for the remote side to connect to us on the given port. The listener is kept
+// open so the remote can reconnect if the connection is lost. If TLS is
+// configured, the connection is secured before it is returned. The open
+// connections are tracked so that they can all be closed if the transfer is
+// aborted.
+type connector struct {
+	remoteAddress string
+	port          int
+	retryTimeout  time.Duration
+	tlsConfig     *tls.Config
+	listener      net.Listener
+	logger        logr.Logger
+
+	mu      sync.Mutex
+	conns   map[net.Conn]struct{}
+	aborted bool
+}
+
+var errAborted = errors.New("transfer aborted")
+
+func newConnector(remoteAddress string, port int, opts *options, logger logr.Logger) (*connector, error) {
+	// The side that listens is the TLS server
+	tlsConfig, err := newTLSConfig(&opts.tls, remoteAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &connector{
+		remoteAddress: remoteAddress,
+		port:          port,
+		retryTimeout:  opts.retryTimeout,
+		tlsConfig:     tlsConfig,
+		logger:        logger,
+	}, nil
+}
+
+func (c *connector) connect() (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if c.remoteAddress != "" {
+		conn, err = c.dial()
+	} else {
+		conn, err = c.accept()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.aborted {
+		conn.Close()
+		return nil, errAborted
+	}
+	if c.conns == nil {
+		c.conns = map[net.Conn]struct{}{}
+	}
+	c.conns[conn] = struct{}{}
+	return conn, nil
+}
+
+// release closes a connection returned by connect
+func (c *connector) release(conn net.Conn) error {
+	c.mu.Lock()
+	delete(c.conns, conn)
+	c.mu.Unlock()
+	return conn.Close()
+}
+
+// abort closes all the connections and stops accepting new ones
+func (c *connector) abort() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.aborted = true
+	for conn := range c.conns {
+		conn.Close()
+	}
+	c.conns = nil
+	if c.listener != nil {
+		c.listener.Close()
+	}
+}
+
+func (c *connector) isAborted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.aborted
+}
+
+// accept waits for the remote to connect
+func (c *connector) accept() (net.Conn, error) {
+	if err := c.listen(); err != nil {
+		return nil, err
+	}
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if c.tlsConfig == nil {
+			return conn, nil
+		}
+		tlsConn := tls.Server(conn, c.tlsConfig)
+		if err := handshake(tlsConn); err != nil {
+			// Don't let a bad client prevent the real one from connecting
+			c.logger.Error(err, "TLS handshake failed", "remote", conn.RemoteAddr().String())
+			conn.Close()
+			continue
+		}
+		return tlsConn, nil
+	}
+}
+
+func (c *connector) listen() error {
+	if c.listener != nil {
+		return nil
+	}
+	c.logger.Info("Listening for tcp connection", "port", fmt.Sprintf(":%d", c.port), "tls", c.tlsConfig != nil)
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", c.port))
+	if err != nil {
+		return err
+	}
+	c.listener = listener
+	return nil
+}
+
+// dial connects to the remote, retrying with an exponential backoff until the
+// retry timeout expires
+func (c *connector) dial() (net.Conn, error) {
+	address := net.JoinHostPort(c.remoteAddress, strconv.Itoa(c.port))
+	deadline := time.Now().Add(c.retryTimeout)
+	backoff := time.Second
+	for !c.isAborted() {
+		c.logger.Info("Connecting to remote", "address", c.remoteAddress, "port", c.port, "tls", c.tlsConfig != nil)
+		conn, err := net.Dial("tcp", address)
+		if err == nil && c.tlsConfig != nil {
+			tlsConn := tls.Client(conn, c.tlsConfig)
+			if err = handshake(tlsConn); err != nil {
+				conn.Close()
+			} else {
+				conn = tlsConn
+			}
+		}
+		if err == nil {
+			return conn, nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, err
+		}
+		c.logger.Error(err, "Unable to connect to remote, retrying", "delay", backoff.String())
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxDialBackoff {
+			backoff = maxDialBackoff
+		}
+	}
+	return nil, errAborted
+}
+
+func (c *connector) close() {
+	if c.listener != nil {
+		c.listener.Close()
 	}
-	return err
 }
 
 //nolint:funlen
-func startServer(targetFile string, port int, opts *options, logger logr.Logger) error {
+func startServer(targetFile, sourceAddress string, port int, opts *options,
+	logger logr.Logger) (verification *verificationResult, err error) {
 	var w spgz.SparseFile
 	useReadBuffer := false
 
 	f, err := os.OpenFile(targetFile, os.O_RDWR|os.O_CREATE, 0666)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer f.Close()
 
 	logger.Info("Opened file", "file", targetFile)
 	info, err := f.Stat()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	logger.Info("file info", "info", info)
 
-	if info.Mode()&(os.ModeDevice|os.ModeCharDevice) != 0 {
+	isDevice := info.Mode()&(os.ModeDevice|os.ModeCharDevice) != 0
+	if isDevice {
 		logger.Info("device file?")
 		w = spgz.NewSparseFileWithoutHolePunching(f)
 		useReadBuffer = true
@@ -201,10 +449,14 @@ func startServer(targetFile string, port int, opts *options, logger logr.Logger)
 					err = fmt.Errorf(
 						"target does not support compression. Try with -no-compress option (error was '%w')", err)
 				}
-				return err
+				return nil, err
 			}
 		} else {
 			w = &diskrsync.FixingSpgzFileWrapper{SpgzFile: sf}
+			if opts.streams > 1 {
+				// Compressed files can't be written concurrently
+				w = &lockedSparseFile{SparseFile: w}
+			}
 		}
 	}
 
@@ -222,60 +474,77 @@ func startServer(targetFile string, port int, opts *options, logger logr.Logger)
 
 	size, err := w.Seek(0, io.SeekEnd)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	_, err = w.Seek(0, io.SeekStart)
 	logger.Info("Size", "size", size)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	logger.Info("Listening for tcp connection", "port", fmt.Sprintf(":%d", port))
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
-	if err != nil {
-		return err
-	}
-	conn, err := listener.Accept()
-	if err != nil {
-		return err
+	t := &resumableTarget{
+		writer:         w,
+		size:           size,
+		isDevice:       isDevice,
+		useReadBuffer:  useReadBuffer,
+		streams:        opts.streams,
+		checkpointFile: opts.checkpointFile,
+		opts:           opts,
+		logger:         logger,
+		// The size to transfer is only known once the source connects
+		progress: newTransferProgress(0),
 	}
+	t.loadCheckpoint()
 
-	calcProgress := &progress{
-		progressType: "calc progress",
-		logger:       logger,
-	}
-	syncProgress := &progress{
-		progressType: "sync progress",
-		logger:       logger,
-	}
-	err = diskrsync.Target(w, size, conn, conn, useReadBuffer, opts.verbose, calcProgress, syncProgress)
+	c, err := newConnector(sourceAddress, port, opts, logger)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	defer c.close()
+	defer t.progress.run(opts.progressInterval)()
+	if err := t.receive(c); err != nil {
+		return nil, err
+	}
+	return t.verification, nil
 }
 
-type progress struct {
-	total        int64
-	current      int64
-	progressType string
-	lastUpdate   time.Time
-	logger       logr.Logger
+// limitWriter wraps w so that no more than bwLimit KiB/s are written to it. If
+// bwLimit is not positive, w is returned unchanged.
+func limitWriter(w io.Writer, bwLimit int, logger logr.Logger) io.Writer {
+	if bwLimit <= 0 {
+		return w
+	}
+	logger.Info("Limiting bandwidth", "KiB/s", bwLimit)
+	bytesPerSec := bwLimit * 1024
+	return &rateLimitedWriter{
+		w:       w,
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSec), bytesPerSec),
+	}
 }
 
-func (p *progress) Start(size int64) {
-	p.total = size
-	p.current = int64(0)
-	p.lastUpdate = time.Now()
-	p.logger.Info(fmt.Sprintf("%s total size %d", p.progressType, p.total))
+type rateLimitedWriter struct {
+	w       io.Writer
+	limiter *rate.Limiter
 }
 
-func (p *progress) Update(pos int64) {
-	p.current = pos
-	if time.Since(p.lastUpdate).Seconds() > time.Second.Seconds() {
-		p.logger.Info(fmt.Sprintf("%s %.2f%%", p.progressType, (float64(p.current) / float64(p.total) * 100)))
-		p.lastUpdate = time.Now()
+func (rw *rateLimitedWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		// A single wait can't be larger than the limiter's burst
+		chunk := len(p) - written
+		if chunk > rw.limiter.Burst() {
+			chunk = rw.limiter.Burst()
+		}
+		if err := rw.limiter.WaitN(context.Background(), chunk); err != nil {
+			return written, err
+		}
+		n, err := rw.w.Write(p[written : written+chunk])
+		written += n
+		if err != nil {
+			return written, err
+		}
 	}
+	return written, nil
 }
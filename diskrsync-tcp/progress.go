@@ -0,0 +1,251 @@
+/*
+Copyright © 2023 The VolSync authors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dop251/diskrsync"
+)
+
+// Progress reports are printed on a line of their own, starting with this
+// prefix followed by the report as JSON, so that they can be picked out of
+// the mover logs
+const progressPrefix = "diskrsync-progress: "
+
+// progressReport is the structured progress of a transfer
+type progressReport struct {
+	Time              time.Time `json:"time"`
+	TotalBytes        int64     `json:"totalBytes"`
+	BytesHashed       int64     `json:"bytesHashed"`
+	BytesSynchronized int64     `json:"bytesSynchronized"`
+	BytesSent         int64     `json:"bytesSent"`
+	Percent           int32     `json:"percent"`
+	// Estimated time remaining, only present once it can be estimated
+	ETASeconds *int64 `json:"etaSeconds,omitempty"`
+}
+
+// transferProgress tracks the progress of the whole transfer. The segments of
+// the device are hashed and synchronized independently, possibly several at a
+// time, so the progress is the size of the completed segments plus how far
+// each of the segments in flight has got.
+type transferProgress struct {
+	mu                sync.Mutex
+	total             int64
+	completed         int64
+	inFlight          map[int64]*segmentProgress
+	sent              int64
+	resumed           bool
+	startTime         time.Time
+	startSynchronized int64
+	started           bool
+	finished          bool
+	out               io.Writer
+}
+
+// segmentProgress is how much of a segment in flight has been processed
+type segmentProgress struct {
+	hashed       int64
+	synchronized int64
+}
+
+func newTransferProgress(total int64) *transferProgress {
+	return &transferProgress{
+		total:    total,
+		inFlight: map[int64]*segmentProgress{},
+		out:      os.Stdout,
+	}
+}
+
+// setTotal sets the number of bytes to transfer, which the target only learns
+// from the source
+func (p *transferProgress) setTotal(total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total = total
+}
+
+// resume records how many bytes had already been synchronized by a previous
+// attempt when the transfer started. Only the first call has an effect.
+func (p *transferProgress) resume(completed int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.resumed {
+		p.resumed = true
+		p.completed = completed
+	}
+}
+
+// startSegment records that the segment at offset is about to be transferred
+func (p *transferProgress) startSegment(offset int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inFlight[offset] = &segmentProgress{}
+	if !p.started {
+		// When resuming, only what is transferred from now on counts toward
+		// the rate used for the estimate
+		p.started = true
+		p.startTime = time.Now()
+		p.startSynchronized = p.completed
+	}
+}
+
+// completeSegment records that the segment at offset has been synchronized
+func (p *transferProgress) completeSegment(offset, length int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inFlight, offset)
+	p.completed += length
+}
+
+// abortSegment records that the transfer of the segment at offset has failed,
+// it will be transferred again from the start
+func (p *transferProgress) abortSegment(offset int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inFlight, offset)
+}
+
+// finish records that the whole device has been synchronized
+func (p *transferProgress) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.completed = p.total
+	p.inFlight = map[int64]*segmentProgress{}
+	p.finished = true
+}
+
+// calcListener and syncListener receive the progress of diskrsync for the
+// segment starting at offset
+func (p *transferProgress) calcListener(offset int64) diskrsync.ProgressListener {
+	return &segmentListener{update: func(pos int64) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if sp, ok := p.inFlight[offset]; ok {
+			sp.hashed = pos
+		}
+	}}
+}
+
+func (p *transferProgress) syncListener(offset int64) diskrsync.ProgressListener {
+	return &segmentListener{update: func(pos int64) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if sp, ok := p.inFlight[offset]; ok {
+			sp.synchronized = pos
+		}
+	}}
+}
+
+// countSent wraps w so that the bytes written to it are counted as sent
+func (p *transferProgress) countSent(w io.Writer) io.Writer {
+	return &countingWriter{w: w, p: p}
+}
+
+func (p *transferProgress) report() progressReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	hashed, synchronized := p.completed, p.completed
+	for _, sp := range p.inFlight {
+		hashed += sp.hashed
+		synchronized += sp.synchronized
+	}
+	r := progressReport{
+		Time:              time.Now().UTC().Truncate(time.Second),
+		TotalBytes:        p.total,
+		BytesHashed:       hashed,
+		BytesSynchronized: synchronized,
+		BytesSent:         p.sent,
+	}
+	switch {
+	case p.finished:
+		r.Percent = 100
+	case p.total > 0:
+		r.Percent = int32(synchronized * 100 / p.total)
+	}
+	if p.started && synchronized > p.startSynchronized {
+		elapsed := time.Since(p.startTime).Seconds()
+		rate := float64(synchronized-p.startSynchronized) / elapsed
+		eta := int64(float64(p.total-synchronized) / rate)
+		r.ETASeconds = &eta
+	}
+	return r
+}
+
+// print writes the current progress report to the output
+func (p *transferProgress) print() {
+	data, err := json.Marshal(p.report())
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(p.out, "%s%s\n", progressPrefix, data)
+}
+
+// run prints a progress report every interval until the returned function is
+// called, which prints a final report
+func (p *transferProgress) run(interval time.Duration) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.print()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		p.print()
+	}
+}
+
+// segmentListener receives the position reached within a segment
+type segmentListener struct {
+	update func(pos int64)
+}
+
+func (l *segmentListener) Start(_ int64) {}
+
+func (l *segmentListener) Update(pos int64) {
+	l.update(pos)
+}
+
+type countingWriter struct {
+	w io.Writer
+	p *transferProgress
+}
+
+func (cw *countingWriter) Write(b []byte) (int, error) {
+	n, err := cw.w.Write(b)
+	cw.p.mu.Lock()
+	cw.p.sent += int64(n)
+	cw.p.mu.Unlock()
+	return n, err
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2023 The VolSync authors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Progress reports", func() {
+	It("estimates the time remaining from the rate so far", func() {
+		p := newTransferProgress(1000)
+		Expect(p.report().ETASeconds).To(BeNil())
+
+		p.resume(200)
+		p.startSegment(200)
+		p.startTime = time.Now().Add(-10 * time.Second)
+		p.calcListener(200).Update(400)
+		p.syncListener(200).Update(200)
+
+		r := p.report()
+		Expect(r.BytesHashed).To(Equal(int64(600)))
+		Expect(r.BytesSynchronized).To(Equal(int64(400)))
+		Expect(r.Percent).To(Equal(int32(40)))
+		// 200 bytes in 10s, 600 bytes to go
+		Expect(r.ETASeconds).NotTo(BeNil())
+		Expect(*r.ETASeconds).To(BeNumerically("~", 30, 1))
+	})
+
+	It("adds up the progress of the segments in flight", func() {
+		p := newTransferProgress(1000)
+		p.resume(100)
+		p.startSegment(100)
+		p.startSegment(400)
+		p.startTime = time.Now().Add(-10 * time.Second)
+		p.syncListener(100).Update(100)
+		p.syncListener(400).Update(200)
+		Expect(p.report().BytesSynchronized).To(Equal(int64(400)))
+
+		p.completeSegment(100, 300)
+		p.abortSegment(400)
+		r := p.report()
+		Expect(r.BytesSynchronized).To(Equal(int64(400)))
+		Expect(r.Percent).To(Equal(int32(40)))
+		// 300 bytes in 10s, 600 bytes to go
+		Expect(r.ETASeconds).NotTo(BeNil())
+		Expect(*r.ETASeconds).To(BeNumerically("~", 20, 1))
+	})
+
+	It("reports the progress of both sides of a transfer", func() {
+		sourceData := randomData()
+		source := newTestSource(sourceData, "test")
+		targetFile, err := os.Create(filepath.Join(GinkgoT().TempDir(), "target"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(targetFile.Close)
+		target := newTestTarget(targetFile, "")
+
+		sourceErr, targetErr := runTransfer(source, target, 0)
+		Expect(sourceErr).NotTo(HaveOccurred())
+		Expect(targetErr).NotTo(HaveOccurred())
+
+		for _, p := range []*transferProgress{source.progress, target.progress} {
+			out := &bytes.Buffer{}
+			p.out = out
+			p.print()
+
+			line := out.String()
+			Expect(line).To(HavePrefix(progressPrefix))
+			var r progressReport
+			Expect(json.Unmarshal([]byte(strings.TrimPrefix(line, progressPrefix)), &r)).To(Succeed())
+			Expect(r.TotalBytes).To(Equal(int64(len(sourceData))))
+			Expect(r.BytesSynchronized).To(Equal(int64(len(sourceData))))
+			Expect(r.Percent).To(Equal(int32(100)))
+			Expect(r.BytesSent).To(BeNumerically(">", 0))
+		}
+		// The data itself is sent by the source
+		Expect(source.progress.report().BytesSent).To(BeNumerically(">", len(sourceData)))
+	})
+})
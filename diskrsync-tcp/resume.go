@@ -0,0 +1,795 @@
+/*
+Copyright © 2023 The VolSync authors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/dop251/diskrsync"
+	"github.com/dop251/spgz"
+	"github.com/go-logr/logr"
+)
+
+// The device is transferred in segments of this size. Each segment is a
+// complete diskrsync exchange, so a dropped connection only requires the
+// segment that was in flight to be sent again. When several streams are used,
+// each stream transfers a different segment.
+const defaultSegmentSize int64 = 1 << 30 // 1 GiB
+
+var resumeMagic = [8]byte{'V', 'S', 'D', 'R', 'S', 'R', '0', '2'}
+
+// Sent by the target after each segment has been written and checkpointed
+const segmentAck byte = 1
+
+// Sent by the target instead of a segment index once all the segments have
+// been transferred
+const noMoreSegments int64 = -1
+
+// hello is sent by the source at the start of every connection
+type hello struct {
+	Magic           [8]byte
+	TransferID      [sha256.Size]byte
+	Size            int64
+	SegmentSize     int64
+	Flags           uint32
+	VerifyRangeSize int64
+	// Number of concurrent streams and which one this connection is for
+	Streams int32
+	Stream  int32
+}
+
+// checkpoint records the progress of a transfer on the target
+type checkpoint struct {
+	TransferID  string `json:"transferID"`
+	Size        int64  `json:"size"`
+	SegmentSize int64  `json:"segmentSize"`
+	// Number of segments, from the start, that have been completely written
+	Completed int64 `json:"completed"`
+	// Segments after those that have also been completely written, as streams
+	// don't complete their segments in order
+	CompletedAfter []int64 `json:"completedAfter,omitempty"`
+}
+
+// isCompleted returns true if segment i has been completely written
+func (c *checkpoint) isCompleted(i int64) bool {
+	if i < c.Completed {
+		return true
+	}
+	for _, j := range c.CompletedAfter {
+		if j == i {
+			return true
+		}
+	}
+	return false
+}
+
+// markCompleted records that segment i has been completely written
+func (c *checkpoint) markCompleted(i int64) {
+	if c.isCompleted(i) {
+		return
+	}
+	c.CompletedAfter = append(c.CompletedAfter, i)
+	// Fold the segments that now follow on from the start into Completed
+	for {
+		found := false
+		for k, j := range c.CompletedAfter {
+			if j == c.Completed {
+				c.CompletedAfter = append(c.CompletedAfter[:k], c.CompletedAfter[k+1:]...)
+				c.Completed++
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	if len(c.CompletedAfter) == 0 {
+		c.CompletedAfter = nil
+	}
+}
+
+// completedSegments returns the number of segments that have been completely
+// written
+func (c *checkpoint) completedSegments() int64 {
+	return c.Completed + int64(len(c.CompletedAfter))
+}
+
+// completedBytes returns the size of the segments that have been completely
+// written
+func (c *checkpoint) completedBytes() int64 {
+	var total int64
+	count := segmentCount(c.Size, c.SegmentSize)
+	for i := int64(0); i < count; i++ {
+		if c.isCompleted(i) {
+			_, length := segmentBounds(i, c.Size, c.SegmentSize)
+			total += length
+		}
+	}
+	return total
+}
+
+func segmentCount(size, segmentSize int64) int64 {
+	return (size + segmentSize - 1) / segmentSize
+}
+
+// segmentBounds returns the offset and length of segment i
+func segmentBounds(i, size, segmentSize int64) (int64, int64) {
+	offset := i * segmentSize
+	length := segmentSize
+	if offset+length > size {
+		length = size - offset
+	}
+	return offset, length
+}
+
+// transferIDHash turns the transfer ID into the fixed size value that is sent
+// in the hello. If no ID is provided, a random one is used so that the
+// transfer can only be resumed by this process.
+func transferIDHash(transferID string) ([sha256.Size]byte, error) {
+	if transferID == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		transferID = hex.EncodeToString(random)
+	}
+	return sha256.Sum256([]byte(transferID)), nil
+}
+
+// isConnectionError returns true if err was caused by the connection to the
+// remote being lost, in which case the transfer can be resumed
+func isConnectionError(err error) bool {
+	var opErr *net.OpError
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.ErrClosedPipe) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) || errors.As(err, &opErr)
+}
+
+// resumable is one stream of a transfer that can be resumed over a new
+// connection
+type resumable interface {
+	transfer(conn net.Conn) error
+	// Number of segments that have been completely transferred so far
+	completed() int64
+}
+
+// runResumable runs the transfer over connections from c until it succeeds.
+// If the connection is lost, a new one is established and the transfer is
+// resumed. It gives up once no segment has been completed for the connector's
+// retry timeout.
+func runResumable(c *connector, logger logr.Logger, r resumable) error {
+	deadline := time.Now().Add(c.retryTimeout)
+	backoff := time.Second
+	lastCompleted := r.completed()
+	for {
+		conn, err := c.connect()
+		if err != nil {
+			return err
+		}
+		err = r.transfer(conn)
+		cerr := c.release(conn)
+		if err == nil {
+			return cerr
+		}
+		if !isConnectionError(err) {
+			return err
+		}
+
+		if r.completed() > lastCompleted {
+			lastCompleted = r.completed()
+			deadline = time.Now().Add(c.retryTimeout)
+			backoff = time.Second
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return err
+		}
+		logger.Error(err, "Connection lost, reconnecting to resume the transfer", "delay", backoff.String())
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxDialBackoff {
+			backoff = maxDialBackoff
+		}
+	}
+}
+
+// runStreams runs each of the streams over its own connection from c. If a
+// stream fails, the others are stopped.
+func runStreams(c *connector, logger logr.Logger, streams []resumable) error {
+	if c.remoteAddress == "" {
+		// Listen before any of the streams waits for its connection
+		if err := c.listen(); err != nil {
+			return err
+		}
+	}
+	errs := make(chan error, len(streams))
+	for i, r := range streams {
+		go func(i int, r resumable) {
+			errs <- runResumable(c, logger.WithValues("stream", i), r)
+		}(i, r)
+	}
+	var firstErr error
+	for range streams {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+			c.abort()
+		}
+	}
+	return firstErr
+}
+
+type resumableSource struct {
+	reader          io.ReaderAt
+	size            int64
+	segmentSize     int64
+	verifyRangeSize int64
+	streams         int
+	transferID      [sha256.Size]byte
+	acked           atomic.Int64
+	opts            *options
+	logger          logr.Logger
+	progress        *transferProgress
+	verification    *verificationResult
+}
+
+// sourceStream is one of the concurrent streams of a source
+type sourceStream struct {
+	*resumableSource
+	index int
+}
+
+func (s *sourceStream) transfer(conn net.Conn) error {
+	return s.transferStream(conn, s.index)
+}
+
+// streamsToRun returns the streams of the transfer
+func (s *resumableSource) streamsToRun() []resumable {
+	streams := []resumable{}
+	for i := 0; i < s.streamCount(); i++ {
+		streams = append(streams, &sourceStream{resumableSource: s, index: i})
+	}
+	return streams
+}
+
+func (s *resumableSource) streamCount() int {
+	if s.streams < 1 {
+		return 1
+	}
+	return s.streams
+}
+
+// transfer runs the transfer over a single stream
+func (s *resumableSource) transfer(conn net.Conn) error {
+	return s.transferStream(conn, 0)
+}
+
+// transferStream sends the segments that the target asks for. Once they have
+// all been transferred, the first stream has the target verified if requested.
+func (s *resumableSource) transferStream(conn net.Conn, index int) error {
+	writer := s.progress.countSent(limitWriter(conn, s.opts.bwLimit, s.logger))
+
+	h := hello{
+		Magic:           resumeMagic,
+		TransferID:      s.transferID,
+		Size:            s.size,
+		SegmentSize:     s.segmentSize,
+		Flags:           s.flags(),
+		VerifyRangeSize: s.verifyRangeSize,
+		Streams:         int32(s.streamCount()),
+		Stream:          int32(index),
+	}
+	if err := binary.Write(writer, binary.LittleEndian, &h); err != nil {
+		return err
+	}
+	var completedBytes int64
+	if err := binary.Read(conn, binary.LittleEndian, &completedBytes); err != nil {
+		return fmt.Errorf("could not read resume position: %w", err)
+	}
+	if completedBytes > 0 && index == 0 {
+		s.logger.Info("Resuming transfer", "completed bytes", completedBytes, "total bytes", s.size)
+	}
+	s.progress.resume(completedBytes)
+
+	count := segmentCount(s.size, s.segmentSize)
+	for {
+		var i int64
+		if err := binary.Read(conn, binary.LittleEndian, &i); err != nil {
+			return fmt.Errorf("could not read next segment: %w", err)
+		}
+		if i == noMoreSegments {
+			break
+		}
+		if i < 0 || i >= count {
+			return fmt.Errorf("invalid segment requested: %d", i)
+		}
+		if err := s.sendSegment(conn, writer, i); err != nil {
+			return err
+		}
+	}
+
+	if index != 0 {
+		return nil
+	}
+	if s.opts.verifyOnly {
+		return s.verify(conn, writer)
+	}
+	s.progress.finish()
+	if s.opts.verify {
+		return s.verify(conn, writer)
+	}
+	return nil
+}
+
+// sendSegment synchronizes segment i with the target
+func (s *resumableSource) sendSegment(conn net.Conn, writer io.Writer, i int64) error {
+	offset, length := segmentBounds(i, s.size, s.segmentSize)
+	s.progress.startSegment(offset)
+	err := diskrsync.Source(io.NewSectionReader(s.reader, offset, length), length, conn, writer,
+		true, s.opts.verbose, s.progress.calcListener(offset), s.progress.syncListener(offset))
+	if err != nil {
+		s.progress.abortSegment(offset)
+		return err
+	}
+	var ack byte
+	if err := binary.Read(conn, binary.LittleEndian, &ack); err != nil {
+		s.progress.abortSegment(offset)
+		return fmt.Errorf("could not read segment acknowledgement: %w", err)
+	}
+	if ack != segmentAck {
+		s.progress.abortSegment(offset)
+		return fmt.Errorf("unexpected segment acknowledgement: %d", ack)
+	}
+	s.progress.completeSegment(offset, length)
+	s.acked.Add(1)
+	return nil
+}
+
+func (s *resumableSource) completed() int64 {
+	return s.acked.Load()
+}
+
+func (s *resumableSource) flags() uint32 {
+	var flags uint32
+	if s.opts.verify {
+		flags |= flagVerify
+	}
+	if s.opts.verifyOnly {
+		flags |= flagVerifyOnly
+	}
+	return flags
+}
+
+type resumableTarget struct {
+	writer         spgz.SparseFile
+	size           int64
+	isDevice       bool
+	useReadBuffer  bool
+	streams        int
+	checkpointFile string
+	opts           *options
+	logger         logr.Logger
+	progress       *transferProgress
+	verification   *verificationResult
+	// Set when the source only verified the target, so nothing was written
+	verifiedOnly bool
+
+	// The state is shared by the streams. Segments being transferred by a
+	// stream are in flight, the others wait for them to complete in case the
+	// stream fails and they need to be transferred again.
+	mu       sync.Mutex
+	cond     *sync.Cond
+	state    checkpoint
+	inFlight map[int64]bool
+}
+
+// streamsToRun returns the streams of the transfer. The streams of the target
+// are all the same, each connection is identified by its hello as the
+// source's streams may connect in any order.
+func (t *resumableTarget) streamsToRun() []resumable {
+	streams := []resumable{}
+	for i := 0; i < t.streamCount(); i++ {
+		streams = append(streams, t)
+	}
+	return streams
+}
+
+func (t *resumableTarget) streamCount() int {
+	if t.streams < 1 {
+		return 1
+	}
+	return t.streams
+}
+
+// transfer receives segments until they have all been written. Once they
+// have, the first stream has the target verified if requested.
+func (t *resumableTarget) transfer(conn net.Conn) error {
+	writer := t.progress.countSent(limitWriter(conn, t.opts.bwLimit, t.logger))
+
+	var h hello
+	if err := binary.Read(conn, binary.LittleEndian, &h); err != nil {
+		return fmt.Errorf("could not read hello: %w", err)
+	}
+	if h.Magic != resumeMagic {
+		return fmt.Errorf("invalid hello from the source")
+	}
+	if h.Size < 0 || h.SegmentSize <= 0 {
+		return fmt.Errorf("invalid transfer size %d or segment size %d", h.Size, h.SegmentSize)
+	}
+	if int(h.Streams) != t.streamCount() || h.Stream < 0 || h.Stream >= h.Streams {
+		return fmt.Errorf("the source uses %d streams (stream %d) but the target %d", h.Streams, h.Stream,
+			t.streamCount())
+	}
+
+	if h.Flags&flagVerifyOnly != 0 {
+		// The target is left untouched, there is nothing to transfer
+		t.progress.setTotal(h.Size)
+		if err := binary.Write(writer, binary.LittleEndian, [2]int64{0, noMoreSegments}); err != nil {
+			return err
+		}
+		if h.Stream != 0 {
+			return nil
+		}
+		t.verifiedOnly = true
+		return t.verify(conn, writer, &h)
+	}
+
+	completedBytes, err := t.begin(&h)
+	if err != nil {
+		return err
+	}
+	if err := binary.Write(writer, binary.LittleEndian, completedBytes); err != nil {
+		return err
+	}
+
+	for {
+		i := t.nextSegment()
+		if i == noMoreSegments {
+			break
+		}
+		if err := t.receiveSegment(conn, writer, i, &h); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(writer, binary.LittleEndian, noMoreSegments); err != nil {
+		return err
+	}
+
+	if h.Stream != 0 {
+		return nil
+	}
+	t.progress.finish()
+	if h.Flags&flagVerify != 0 {
+		// The checkpoint is kept until the verification is complete so that it
+		// can be performed again over a new connection
+		if err := t.verify(conn, writer, &h); err != nil {
+			return err
+		}
+	}
+	return t.finish()
+}
+
+// receive runs the streams of the target over connections from c until the
+// source has transferred its data. Verifying the target leaves it untouched,
+// so the target keeps waiting for a transfer after a source only verified it.
+// Otherwise, the destination would report a synchronization that didn't
+// happen.
+func (t *resumableTarget) receive(c *connector) error {
+	for {
+		t.verification = nil
+		t.verifiedOnly = false
+		if err := runStreams(c, t.logger, t.streamsToRun()); err != nil {
+			return err
+		}
+		if !t.verifiedOnly {
+			return nil
+		}
+		t.logger.Info("Verification complete, waiting for the source to transfer its data")
+	}
+}
+
+// begin prepares the target for the transfer described by the hello and
+// returns how much of it has already been written
+func (t *resumableTarget) begin(h *hello) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cond == nil {
+		t.cond = sync.NewCond(&t.mu)
+		t.inFlight = map[int64]bool{}
+	}
+
+	transferID := hex.EncodeToString(h.TransferID[:])
+	if t.state.TransferID != transferID || t.state.Size != h.Size || t.state.SegmentSize != h.SegmentSize {
+		if t.state.TransferID != "" {
+			t.logger.Info("Discarding the progress of a previous transfer")
+		}
+		if err := t.prepare(h.Size); err != nil {
+			return 0, err
+		}
+		t.state = checkpoint{TransferID: transferID, Size: h.Size, SegmentSize: h.SegmentSize}
+		if err := t.saveCheckpoint(); err != nil {
+			return 0, err
+		}
+	}
+
+	t.progress.setTotal(h.Size)
+	completedBytes := t.state.completedBytes()
+	t.progress.resume(completedBytes)
+	if completedBytes > 0 && h.Stream == 0 {
+		t.logger.Info("Resuming transfer", "completed segments", t.state.completedSegments(),
+			"segments", segmentCount(h.Size, h.SegmentSize))
+	}
+	return completedBytes, nil
+}
+
+// nextSegment returns the first segment that hasn't been written and isn't
+// in flight, and marks it as in flight. If all the remaining segments are in
+// flight, it waits for them to either complete or be released. Once all the
+// segments have been written, it returns noMoreSegments.
+func (t *resumableTarget) nextSegment() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	count := segmentCount(t.state.Size, t.state.SegmentSize)
+	for {
+		for i := t.state.Completed; i < count; i++ {
+			if !t.state.isCompleted(i) && !t.inFlight[i] {
+				t.inFlight[i] = true
+				return i
+			}
+		}
+		if len(t.inFlight) == 0 {
+			return noMoreSegments
+		}
+		t.cond.Wait()
+	}
+}
+
+// receiveSegment asks the source for segment i and writes it. If it fails, the
+// segment is released so that another stream can transfer it.
+func (t *resumableTarget) receiveSegment(conn net.Conn, writer io.Writer, i int64, h *hello) error {
+	offset, length := segmentBounds(i, h.Size, h.SegmentSize)
+	err := t.writeSegment(conn, writer, i, offset, length)
+
+	t.mu.Lock()
+	delete(t.inFlight, i)
+	t.cond.Broadcast()
+	if err != nil {
+		t.mu.Unlock()
+		t.progress.abortSegment(offset)
+		return err
+	}
+	t.state.markCompleted(i)
+	err = t.saveCheckpoint()
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	t.progress.completeSegment(offset, length)
+	return binary.Write(writer, binary.LittleEndian, segmentAck)
+}
+
+func (t *resumableTarget) writeSegment(conn net.Conn, writer io.Writer, i, offset, length int64) error {
+	if err := binary.Write(writer, binary.LittleEndian, i); err != nil {
+		return err
+	}
+	t.progress.startSegment(offset)
+	err := diskrsync.Target(newSparseSection(t.writer, offset, length), length, conn, writer,
+		t.useReadBuffer, t.opts.verbose, t.progress.calcListener(offset), t.progress.syncListener(offset))
+	if err != nil {
+		return err
+	}
+	// The data must be on disk before the segment is recorded as complete
+	return t.writer.Sync()
+}
+
+func (t *resumableTarget) completed() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state.completedSegments()
+}
+
+// prepare makes sure the target can hold size bytes
+func (t *resumableTarget) prepare(size int64) error {
+	if t.size >= size {
+		return nil
+	}
+	if t.isDevice {
+		return fmt.Errorf("target device (%d bytes) is smaller than the source (%d bytes)", t.size, size)
+	}
+	if err := t.writer.Truncate(size); err != nil {
+		return err
+	}
+	t.size = size
+	return nil
+}
+
+// finish trims the target to the size of the source and removes the
+// checkpoint now that the transfer is complete
+func (t *resumableTarget) finish() error {
+	if t.size > t.state.Size && !t.isDevice {
+		if err := t.writer.Truncate(t.state.Size); err != nil {
+			return err
+		}
+		t.size = t.state.Size
+	}
+	if t.checkpointFile != "" {
+		if err := os.Remove(t.checkpointFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCheckpoint restores the progress of a previous run of the target, if any
+func (t *resumableTarget) loadCheckpoint() {
+	if t.checkpointFile == "" {
+		return
+	}
+	data, err := os.ReadFile(t.checkpointFile)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			t.logger.Error(err, "Unable to read checkpoint file", "file", t.checkpointFile)
+		}
+		return
+	}
+	var state checkpoint
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.logger.Error(err, "Ignoring invalid checkpoint file", "file", t.checkpointFile)
+		return
+	}
+	t.logger.Info("Loaded checkpoint", "completed segments", state.Completed)
+	t.state = state
+}
+
+// saveCheckpoint atomically replaces the checkpoint file with the current state
+func (t *resumableTarget) saveCheckpoint() error {
+	if t.checkpointFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(&t.state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.checkpointFile), 0755); err != nil {
+		return err
+	}
+	tmpFile := t.checkpointFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, t.checkpointFile)
+}
+
+// sparseSection exposes the byte range [offset, offset+size) of a SparseFile
+// as a SparseFile of its own so that it can be synchronized independently
+type sparseSection struct {
+	spgz.SparseFile
+	offset int64
+	size   int64
+	pos    int64
+}
+
+func newSparseSection(f spgz.SparseFile, offset, size int64) *sparseSection {
+	return &sparseSection{SparseFile: f, offset: offset, size: size}
+}
+
+func (s *sparseSection) Read(p []byte) (int, error) {
+	n, err := s.ReadAt(p, s.pos)
+	s.pos += int64(n)
+	return n, err
+}
+
+func (s *sparseSection) ReadAt(p []byte, off int64) (int, error) {
+	if off >= s.size {
+		return 0, io.EOF
+	}
+	if max := s.size - off; int64(len(p)) > max {
+		p = p[:max]
+	}
+	return s.SparseFile.ReadAt(p, s.offset+off)
+}
+
+func (s *sparseSection) Write(p []byte) (int, error) {
+	n, err := s.WriteAt(p, s.pos)
+	s.pos += int64(n)
+	return n, err
+}
+
+func (s *sparseSection) WriteAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > s.size {
+		return 0, fmt.Errorf("write at %d of %d bytes is outside of the section", off, len(p))
+	}
+	return s.SparseFile.WriteAt(p, s.offset+off)
+}
+
+func (s *sparseSection) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.size
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+	s.pos = offset
+	return offset, nil
+}
+
+func (s *sparseSection) PunchHole(offset, size int64) error {
+	if offset+size > s.size {
+		size = s.size - offset
+	}
+	return s.SparseFile.PunchHole(s.offset+offset, size)
+}
+
+// Truncate is a no-op, the size of the section is fixed
+func (s *sparseSection) Truncate(int64) error {
+	return nil
+}
+
+// Close is a no-op, the underlying file is closed by its owner
+func (s *sparseSection) Close() error {
+	return nil
+}
+
+// lockedSparseFile serializes the access to a SparseFile that can't be used by
+// several streams at once. Only the methods used by sparseSection are needed.
+type lockedSparseFile struct {
+	spgz.SparseFile
+	mu sync.Mutex
+}
+
+func (f *lockedSparseFile) ReadAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.SparseFile.ReadAt(p, off)
+}
+
+func (f *lockedSparseFile) WriteAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.SparseFile.WriteAt(p, off)
+}
+
+func (f *lockedSparseFile) PunchHole(offset, size int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.SparseFile.PunchHole(offset, size)
+}
+
+func (f *lockedSparseFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.SparseFile.Sync()
+}
@@ -0,0 +1,207 @@
+/*
+Copyright © 2023 The VolSync authors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/dop251/spgz"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const testSegmentSize = 256 * 1024
+
+// cuttingConn drops the connection once more than limit bytes have been
+// written to it
+type cuttingConn struct {
+	net.Conn
+	peer    net.Conn
+	limit   int
+	written int
+}
+
+func (c *cuttingConn) Write(p []byte) (int, error) {
+	if c.written+len(p) > c.limit {
+		c.Conn.Close()
+		c.peer.Close()
+		return 0, io.ErrClosedPipe
+	}
+	n, err := c.Conn.Write(p)
+	c.written += n
+	return n, err
+}
+
+// runTransfer connects the source and the target with a pipe and runs both
+// sides of the transfer. If limit is positive, the connection is dropped after
+// the source has sent that many bytes.
+func runTransfer(s *resumableSource, t *resumableTarget, limit int) (error, error) {
+	sourceConn, targetConn := net.Pipe()
+	var conn net.Conn = sourceConn
+	if limit > 0 {
+		conn = &cuttingConn{Conn: sourceConn, peer: targetConn, limit: limit}
+	}
+
+	targetErr := make(chan error)
+	go func() {
+		defer GinkgoRecover()
+		err := t.transfer(targetConn)
+		targetConn.Close()
+		targetErr <- err
+	}()
+	err := s.transfer(conn)
+	sourceConn.Close()
+	return err, <-targetErr
+}
+
+func newTestSource(data []byte, transferID string) *resumableSource {
+	logger := logf.Log.WithName("diskrsync-tcp")
+	id, err := transferIDHash(transferID)
+	Expect(err).NotTo(HaveOccurred())
+	size := int64(len(data))
+	return &resumableSource{
+		reader:      bytes.NewReader(data),
+		size:        size,
+		segmentSize: testSegmentSize,
+		transferID:  id,
+		opts:        &options{},
+		logger:      logger,
+		progress:    newTransferProgress(size),
+	}
+}
+
+func newTestTarget(f *os.File, checkpointFile string) *resumableTarget {
+	logger := logf.Log.WithName("diskrsync-tcp")
+	info, err := f.Stat()
+	Expect(err).NotTo(HaveOccurred())
+	return &resumableTarget{
+		writer:         spgz.NewSparseFileWithFallback(f),
+		size:           info.Size(),
+		useReadBuffer:  true,
+		checkpointFile: checkpointFile,
+		opts:           &options{},
+		logger:         logger,
+		progress:       newTransferProgress(info.Size()),
+	}
+}
+
+// randomData returns 4.5 segments of random data
+func randomData() []byte {
+	data := make([]byte, 4*testSegmentSize+testSegmentSize/2)
+	_, err := rand.Read(data)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+var _ = Describe("Resumable transfers", func() {
+	var sourceData []byte
+	var targetFile *os.File
+	var source *resumableSource
+	var target *resumableTarget
+	var checkpointFile string
+
+	BeforeEach(func() {
+		sourceData = randomData()
+		source = newTestSource(sourceData, "test")
+
+		dir := GinkgoT().TempDir()
+		checkpointFile = filepath.Join(dir, "checkpoint")
+		var err error
+		targetFile, err = os.Create(filepath.Join(dir, "target"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(targetFile.Close)
+		target = newTestTarget(targetFile, checkpointFile)
+	})
+
+	targetData := func() []byte {
+		data, err := os.ReadFile(targetFile.Name())
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	It("copies the data", func() {
+		sourceErr, targetErr := runTransfer(source, target, 0)
+		Expect(sourceErr).NotTo(HaveOccurred())
+		Expect(targetErr).NotTo(HaveOccurred())
+		Expect(targetData()).To(Equal(sourceData))
+		Expect(checkpointFile).NotTo(BeAnExistingFile())
+	})
+
+	It("shrinks a larger target to the size of the source", func() {
+		Expect(targetFile.Truncate(int64(len(sourceData)) + testSegmentSize)).To(Succeed())
+		target.size = int64(len(sourceData)) + testSegmentSize
+		sourceErr, targetErr := runTransfer(source, target, 0)
+		Expect(sourceErr).NotTo(HaveOccurred())
+		Expect(targetErr).NotTo(HaveOccurred())
+		Expect(targetData()).To(Equal(sourceData))
+	})
+
+	When("the connection is lost", func() {
+		JustBeforeEach(func() {
+			// Drop the connection part way through the third segment
+			sourceErr, targetErr := runTransfer(source, target, 2*testSegmentSize+testSegmentSize/2)
+			Expect(isConnectionError(sourceErr)).To(BeTrue())
+			Expect(isConnectionError(targetErr)).To(BeTrue())
+			Expect(target.state.Completed).To(Equal(int64(2)))
+			Expect(checkpointFile).To(BeAnExistingFile())
+		})
+
+		It("resumes from the last completed segment", func() {
+			// Only the remaining segments should need to be sent
+			sourceErr, targetErr := runTransfer(source, target, 3*testSegmentSize)
+			Expect(sourceErr).NotTo(HaveOccurred())
+			Expect(targetErr).NotTo(HaveOccurred())
+			Expect(targetData()).To(Equal(sourceData))
+			Expect(checkpointFile).NotTo(BeAnExistingFile())
+		})
+
+		It("resumes after the target has been restarted", func() {
+			restarted := newTestTarget(targetFile, checkpointFile)
+			restarted.loadCheckpoint()
+			Expect(restarted.state.Completed).To(Equal(int64(2)))
+
+			sourceErr, targetErr := runTransfer(source, restarted, 3*testSegmentSize)
+			Expect(sourceErr).NotTo(HaveOccurred())
+			Expect(targetErr).NotTo(HaveOccurred())
+			Expect(targetData()).To(Equal(sourceData))
+		})
+
+		It("starts over for a different transfer", func() {
+			transferID, err := transferIDHash("other")
+			Expect(err).NotTo(HaveOccurred())
+			source.transferID = transferID
+
+			// Drop the connection right after the hello, the progress of
+			// the previous transfer must have been discarded
+			sourceErr, _ := runTransfer(source, target, binary.Size(hello{}))
+			Expect(isConnectionError(sourceErr)).To(BeTrue())
+			Expect(target.state.Completed).To(BeZero())
+
+			sourceErr, targetErr := runTransfer(source, target, 0)
+			Expect(sourceErr).NotTo(HaveOccurred())
+			Expect(targetErr).NotTo(HaveOccurred())
+			Expect(targetData()).To(Equal(sourceData))
+		})
+	})
+})
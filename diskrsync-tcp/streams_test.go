@@ -0,0 +1,200 @@
+/*
+Copyright © 2023 The VolSync authors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// runStreamsTransfer runs all the streams of both sides of a transfer over
+// local connections, with the target listening and the source connecting to it
+func runStreamsTransfer(s *resumableSource, t *resumableTarget) (error, error) {
+	logger := logf.Log.WithName("diskrsync-tcp")
+	targetConnector, err := newConnector("", 0, t.opts, logger)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(targetConnector.listen()).To(Succeed())
+	port := targetConnector.listener.Addr().(*net.TCPAddr).Port
+
+	targetErr := make(chan error)
+	go func() {
+		defer GinkgoRecover()
+		targetErr <- runStreams(targetConnector, logger, t.streamsToRun())
+	}()
+
+	sourceConnector, err := newConnector("127.0.0.1", port, s.opts, logger)
+	Expect(err).NotTo(HaveOccurred())
+	sourceErr := runStreams(sourceConnector, logger, s.streamsToRun())
+	// Stop the target if it's still waiting for a connection
+	targetConnector.abort()
+	return sourceErr, <-targetErr
+}
+
+var _ = Describe("Multiple streams", func() {
+	var sourceData []byte
+	var targetFile *os.File
+	var source *resumableSource
+	var target *resumableTarget
+	var checkpointFile string
+
+	BeforeEach(func() {
+		sourceData = randomData()
+		source = newTestSource(sourceData, "test")
+		source.streams = 3
+
+		dir := GinkgoT().TempDir()
+		checkpointFile = filepath.Join(dir, "checkpoint")
+		var err error
+		targetFile, err = os.Create(filepath.Join(dir, "target"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(targetFile.Close)
+		target = newTestTarget(targetFile, checkpointFile)
+		target.streams = 3
+	})
+
+	It("copies the data", func() {
+		sourceErr, targetErr := runStreamsTransfer(source, target)
+		Expect(sourceErr).NotTo(HaveOccurred())
+		Expect(targetErr).NotTo(HaveOccurred())
+		Expect(os.ReadFile(targetFile.Name())).To(Equal(sourceData))
+		Expect(source.completed()).To(Equal(segmentCount(int64(len(sourceData)), testSegmentSize)))
+		Expect(checkpointFile).NotTo(BeAnExistingFile())
+		Expect(source.progress.report().Percent).To(Equal(int32(100)))
+		Expect(target.progress.report().Percent).To(Equal(int32(100)))
+	})
+
+	It("verifies the target once all the segments have been transferred", func() {
+		source.opts.verify = true
+		source.verifyRangeSize = testSegmentSize / 4
+		sourceErr, targetErr := runStreamsTransfer(source, target)
+		Expect(sourceErr).NotTo(HaveOccurred())
+		Expect(targetErr).NotTo(HaveOccurred())
+		Expect(target.verification).NotTo(BeNil())
+		Expect(target.verification.Match).To(BeTrue())
+		Expect(source.verification).To(Equal(target.verification))
+	})
+
+	It("only transfers the segments that haven't been completed", func() {
+		// Segments 0 and 3 were written by a previous attempt
+		Expect(targetFile.Truncate(int64(len(sourceData)))).To(Succeed())
+		_, err := targetFile.WriteAt(sourceData[:testSegmentSize], 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = targetFile.WriteAt(sourceData[3*testSegmentSize:4*testSegmentSize], 3*testSegmentSize)
+		Expect(err).NotTo(HaveOccurred())
+		id, err := transferIDHash("test")
+		Expect(err).NotTo(HaveOccurred())
+		data, err := json.Marshal(&checkpoint{
+			TransferID:     hex.EncodeToString(id[:]),
+			Size:           int64(len(sourceData)),
+			SegmentSize:    testSegmentSize,
+			Completed:      1,
+			CompletedAfter: []int64{3},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(checkpointFile, data, 0600)).To(Succeed())
+		target.loadCheckpoint()
+		target.size = int64(len(sourceData))
+
+		sourceErr, targetErr := runStreamsTransfer(source, target)
+		Expect(sourceErr).NotTo(HaveOccurred())
+		Expect(targetErr).NotTo(HaveOccurred())
+		Expect(os.ReadFile(targetFile.Name())).To(Equal(sourceData))
+		Expect(source.completed()).To(Equal(int64(3)))
+	})
+
+	It("keeps waiting for the transfer after the target has only been verified", func() {
+		logger := logf.Log.WithName("diskrsync-tcp")
+		targetConnector, err := newConnector("", 0, target.opts, logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targetConnector.listen()).To(Succeed())
+		DeferCleanup(targetConnector.abort)
+		port := targetConnector.listener.Addr().(*net.TCPAddr).Port
+		targetErr := make(chan error)
+		go func() {
+			defer GinkgoRecover()
+			targetErr <- target.receive(targetConnector)
+		}()
+
+		verifier := newTestSource(sourceData, "test")
+		verifier.streams = 3
+		verifier.opts.verifyOnly = true
+		verifier.verifyRangeSize = testSegmentSize / 4
+		sourceConnector, err := newConnector("127.0.0.1", port, verifier.opts, logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runStreams(sourceConnector, logger, verifier.streamsToRun())).To(Succeed())
+		Expect(verifier.verification).NotTo(BeNil())
+		Expect(verifier.verification.Match).To(BeFalse())
+		// The destination must not report a completed synchronization
+		Consistently(targetErr, "1s").ShouldNot(Receive())
+
+		sourceConnector, err = newConnector("127.0.0.1", port, source.opts, logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runStreams(sourceConnector, logger, source.streamsToRun())).To(Succeed())
+		Eventually(targetErr).Should(Receive(BeNil()))
+		Expect(os.ReadFile(targetFile.Name())).To(Equal(sourceData))
+		Expect(target.verification).To(BeNil())
+	})
+
+	It("fails if both sides don't use the same number of streams", func() {
+		target.streams = 2
+		sourceErr, targetErr := runStreamsTransfer(source, target)
+		Expect(sourceErr).To(HaveOccurred())
+		Expect(targetErr).To(MatchError(ContainSubstring("streams")))
+	})
+
+	Describe("the checkpoint", func() {
+		It("keeps track of the segments completed out of order", func() {
+			c := &checkpoint{Size: 5 * testSegmentSize, SegmentSize: testSegmentSize}
+			c.markCompleted(2)
+			c.markCompleted(4)
+			Expect(c.Completed).To(BeZero())
+			Expect(c.CompletedAfter).To(ConsistOf(int64(2), int64(4)))
+			Expect(c.completedSegments()).To(Equal(int64(2)))
+			Expect(c.completedBytes()).To(Equal(int64(2 * testSegmentSize)))
+
+			c.markCompleted(0)
+			c.markCompleted(1)
+			Expect(c.Completed).To(Equal(int64(3)))
+			Expect(c.CompletedAfter).To(ConsistOf(int64(4)))
+			c.markCompleted(3)
+			Expect(c.Completed).To(Equal(int64(5)))
+			Expect(c.CompletedAfter).To(BeNil())
+		})
+	})
+
+	It("hands out each remaining segment to a single stream", func() {
+		id, err := transferIDHash("test")
+		Expect(err).NotTo(HaveOccurred())
+		target.state = checkpoint{TransferID: hex.EncodeToString(id[:]), Size: 5 * testSegmentSize,
+			SegmentSize: testSegmentSize, Completed: 1, CompletedAfter: []int64{3}}
+		h := &hello{TransferID: id, Size: 5 * testSegmentSize, SegmentSize: testSegmentSize}
+		completedBytes, err := target.begin(h)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(completedBytes).To(Equal(int64(2 * testSegmentSize)))
+		Expect(target.nextSegment()).To(Equal(int64(1)))
+		Expect(target.nextSegment()).To(Equal(int64(2)))
+		Expect(target.nextSegment()).To(Equal(int64(4)))
+		Expect(target.inFlight).To(HaveLen(3))
+	})
+})
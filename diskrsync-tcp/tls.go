@@ -0,0 +1,206 @@
+/*
+Copyright © 2023 The VolSync authors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// How long the TLS handshake may take before the connection is abandoned
+const handshakeTimeout = 30 * time.Second
+
+// tlsOptions configure how the connection to the remote is secured. Either a
+// pre-shared key or a certificate, key and CA can be provided.
+type tlsOptions struct {
+	pskFile    string
+	certFile   string
+	keyFile    string
+	caFile     string
+	serverName string
+}
+
+// newTLSConfig returns the TLS configuration for the connection to the remote,
+// or nil if TLS is not enabled. The side that has a remote address to dial is
+// the TLS client, the side that listens is the TLS server.
+func newTLSConfig(o *tlsOptions, remoteAddress string) (*tls.Config, error) {
+	isClient := remoteAddress != ""
+	usesCert := o.certFile != "" || o.keyFile != "" || o.caFile != ""
+	switch {
+	case o.pskFile != "" && usesCert:
+		return nil, fmt.Errorf("a pre-shared key and a certificate can't both be used for TLS")
+	case o.pskFile != "":
+		return newPSKTLSConfig(o.pskFile, isClient)
+	case usesCert:
+		serverName := o.serverName
+		if serverName == "" {
+			serverName = remoteAddress
+		}
+		return newCertTLSConfig(o.certFile, o.keyFile, o.caFile, serverName, isClient)
+	default:
+		return nil, nil
+	}
+}
+
+// newPSKTLSConfig authenticates both sides with a key that is derived from
+// the pre-shared key. Go's TLS doesn't support external pre-shared keys, so
+// both sides derive the same Ed25519 key from it, present a certificate for
+// that key and only accept a remote that presents a certificate for that same
+// key. The session keys are negotiated with (EC)DHE as usual.
+func newPSKTLSConfig(pskFile string, isClient bool) (*tls.Config, error) {
+	psk, err := readPSK(pskFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, publicKey, err := pskCertificate(psk)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{cert},
+		// The usual chain verification is replaced by checking the key of the
+		// remote's certificate
+		InsecureSkipVerify: true, //nolint:gosec
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("remote did not present a certificate")
+			}
+			remoteCert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			remoteKey, ok := remoteCert.PublicKey.(ed25519.PublicKey)
+			if !ok || !publicKey.Equal(remoteKey) {
+				return fmt.Errorf("remote is not using the same pre-shared key")
+			}
+			return nil
+		},
+	}
+	if !isClient {
+		config.ClientAuth = tls.RequireAnyClientCert
+	}
+	return config, nil
+}
+
+// readPSK reads a pre-shared key in the stunnel PSKsecrets format
+// (IDENTITY:KEY). The first entry of the file is used.
+func readPSK(pskFile string) ([]byte, error) {
+	f, err := os.Open(pskFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if _, key, found := strings.Cut(string(line), ":"); !found || len(key) < 16 {
+			return nil, fmt.Errorf("invalid pre-shared key in %s, expected IDENTITY:KEY with at least 16 bytes of key",
+				pskFile)
+		}
+		return line, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("no pre-shared key found in %s", pskFile)
+}
+
+// pskCertificate returns a self-signed certificate for the Ed25519 key derived
+// from psk
+func pskCertificate(psk []byte) (tls.Certificate, ed25519.PublicKey, error) {
+	mac := hmac.New(sha256.New, psk)
+	mac.Write([]byte("volsync diskrsync-tcp tls key"))
+	privateKey := ed25519.NewKeyFromSeed(mac.Sum(nil))
+	publicKey, _ := privateKey.Public().(ed25519.PublicKey)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "volsync-diskrsync-tcp"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(100 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, privateKey)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: privateKey}, publicKey, nil
+}
+
+// newCertTLSConfig authenticates both sides with certificates signed by the CA
+func newCertTLSConfig(certFile, keyFile, caFile, serverName string, isClient bool) (*tls.Config, error) {
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return nil, fmt.Errorf("a certificate, key and CA must all be provided for TLS")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{cert},
+	}
+	if isClient {
+		config.RootCAs = caPool
+		config.ServerName = serverName
+	} else {
+		config.ClientCAs = caPool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// handshake completes the TLS handshake so that failures are reported when
+// connecting instead of during the transfer
+func handshake(conn *tls.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
+}
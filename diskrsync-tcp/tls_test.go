@@ -0,0 +1,179 @@
+/*
+Copyright © 2023 The VolSync authors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// writeTestCerts creates a CA and a certificate for localhost signed by it,
+// returning the paths of the CA, certificate and key
+func writeTestCerts(dir string) (string, string, string) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	Expect(err).NotTo(HaveOccurred())
+	caCert, err := x509.ParseCertificate(caDER)
+	Expect(err).NotTo(HaveOccurred())
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+
+	caFile := filepath.Join(dir, "ca.crt")
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	Expect(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0600)).To(Succeed())
+	Expect(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).To(Succeed())
+	Expect(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)).To(Succeed())
+	return caFile, certFile, keyFile
+}
+
+var _ = Describe("TLS connections", func() {
+	var dir string
+	var sourceData []byte
+	var targetFile *os.File
+	var sourceOpts, targetOpts *options
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		sourceData = randomData()
+		var err error
+		targetFile, err = os.Create(filepath.Join(dir, "target"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(targetFile.Close)
+		sourceOpts = &options{}
+		targetOpts = &options{}
+	})
+
+	// transfer runs the target listening on a local port and the source
+	// connecting to it
+	transfer := func() (error, error) {
+		logger := logf.Log.WithName("diskrsync-tcp")
+		targetConnector, err := newConnector("", 0, targetOpts, logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targetConnector.listen()).To(Succeed())
+		port := targetConnector.listener.Addr().(*net.TCPAddr).Port
+
+		targetErr := make(chan error)
+		go func() {
+			defer GinkgoRecover()
+			targetErr <- runResumable(targetConnector, logger, newTestTarget(targetFile, ""))
+		}()
+
+		sourceConnector, err := newConnector("127.0.0.1", port, sourceOpts, logger)
+		Expect(err).NotTo(HaveOccurred())
+		sourceErr := runResumable(sourceConnector, logger, newTestSource(sourceData, "test"))
+		// Stop the target if it's still waiting for a connection
+		targetConnector.close()
+		return sourceErr, <-targetErr
+	}
+
+	When("a pre-shared key is used", func() {
+		writePSK := func(name, psk string) string {
+			pskFile := filepath.Join(dir, name)
+			Expect(os.WriteFile(pskFile, []byte(psk+"\n"), 0600)).To(Succeed())
+			return pskFile
+		}
+
+		BeforeEach(func() {
+			pskFile := writePSK("psk.txt", "volsync:0123456789abcdef0123456789abcdef")
+			sourceOpts.tls.pskFile = pskFile
+			targetOpts.tls.pskFile = pskFile
+		})
+
+		It("copies the data", func() {
+			sourceErr, targetErr := transfer()
+			Expect(sourceErr).NotTo(HaveOccurred())
+			Expect(targetErr).NotTo(HaveOccurred())
+			Expect(os.ReadFile(targetFile.Name())).To(Equal(sourceData))
+		})
+
+		It("refuses a remote with a different key", func() {
+			sourceOpts.tls.pskFile = writePSK("other.txt", "volsync:fedcba9876543210fedcba9876543210")
+			sourceErr, _ := transfer()
+			Expect(sourceErr).To(HaveOccurred())
+		})
+
+		It("refuses a remote that doesn't use TLS", func() {
+			sourceOpts.tls.pskFile = ""
+			sourceErr, _ := transfer()
+			Expect(sourceErr).To(HaveOccurred())
+		})
+
+		It("rejects an invalid key file", func() {
+			_, err := newTLSConfig(&tlsOptions{pskFile: writePSK("short.txt", "volsync:short")}, "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("certificates are used", func() {
+		BeforeEach(func() {
+			caFile, certFile, keyFile := writeTestCerts(dir)
+			sourceOpts.tls = tlsOptions{certFile: certFile, keyFile: keyFile, caFile: caFile}
+			targetOpts.tls = tlsOptions{certFile: certFile, keyFile: keyFile, caFile: caFile}
+		})
+
+		It("copies the data", func() {
+			sourceErr, targetErr := transfer()
+			Expect(sourceErr).NotTo(HaveOccurred())
+			Expect(targetErr).NotTo(HaveOccurred())
+			Expect(os.ReadFile(targetFile.Name())).To(Equal(sourceData))
+		})
+
+		It("refuses a server whose certificate doesn't match the name", func() {
+			sourceOpts.tls.serverName = "other.example.com"
+			sourceErr, _ := transfer()
+			Expect(sourceErr).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,218 @@
+/*
+Copyright © 2023 The VolSync authors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/go-logr/logr"
+)
+
+// The devices are verified by comparing the hash of each range of this size
+const defaultVerifyRangeSize int64 = 64 << 20 // 64 MiB
+
+// Bit flags sent in the hello to select what the target should do
+const (
+	// Verify the target once the transfer is complete
+	flagVerify uint32 = 1 << iota
+	// Only verify the target, don't transfer anything
+	flagVerifyOnly
+)
+
+// The result of a verification is printed on a line of its own, starting with
+// this prefix followed by the result as JSON, so that it can be picked out of
+// the mover logs
+const verifyPrefix = "diskrsync-verify: "
+
+// At most this many mismatched ranges are listed in the result
+const maxReportedRanges = 100
+
+// Upper bound of the size of the result sent by the target
+const maxVerificationResultSize = 1 << 20
+
+// The mover publishes the control file of the source as the termination
+// message of its container, which Kubernetes limits to 4 KiB
+const maxControlFileSize = 4096
+
+type byteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+// verificationResult is the outcome of comparing the target with the source
+type verificationResult struct {
+	// True if every range of the target matches the source
+	Match      bool  `json:"match"`
+	TotalBytes int64 `json:"totalBytes"`
+	RangeSize  int64 `json:"rangeSize"`
+	// Total size of the ranges that don't match
+	MismatchedBytes int64 `json:"mismatchedBytes"`
+	// Adjacent ranges that don't match are merged, only the first
+	// maxReportedRanges are listed
+	MismatchedRanges []byteRange `json:"mismatchedRanges,omitempty"`
+	// True if there were more mismatched ranges than are listed
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// addMismatch records that the range [offset, offset+length) doesn't match
+func (r *verificationResult) addMismatch(offset, length int64) {
+	r.Match = false
+	r.MismatchedBytes += length
+	if n := len(r.MismatchedRanges); n > 0 {
+		last := &r.MismatchedRanges[n-1]
+		if last.Offset+last.Length == offset {
+			last.Length += length
+			return
+		}
+	}
+	if len(r.MismatchedRanges) == maxReportedRanges {
+		r.Truncated = true
+		return
+	}
+	r.MismatchedRanges = append(r.MismatchedRanges, byteRange{Offset: offset, Length: length})
+}
+
+// marshal returns the result as JSON of at most maxSize bytes. The last
+// mismatched ranges are left out if the result would be larger.
+func (r *verificationResult) marshal(maxSize int) ([]byte, error) {
+	result := *r
+	for {
+		data, err := json.Marshal(&result)
+		if err != nil || len(data) <= maxSize || len(result.MismatchedRanges) == 0 {
+			return data, err
+		}
+		result.MismatchedRanges = result.MismatchedRanges[:len(result.MismatchedRanges)-1]
+		result.Truncated = true
+	}
+}
+
+// print writes the result to out so that it can be found in the logs
+func (r *verificationResult) print(out io.Writer) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s%s\n", verifyPrefix, data)
+	return err
+}
+
+// hashRange returns the hash of the range [offset, offset+length) of r. A
+// range that can't be read completely (beyond the end of the target) gets the
+// hash of the data that could be read, which won't match the source.
+func hashRange(r io.ReaderAt, offset, length int64, buf []byte) ([sha256.Size]byte, error) {
+	h := sha256.New()
+	if _, err := io.CopyBuffer(h, io.NewSectionReader(r, offset, length), buf); err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// verify sends the hash of every range of the source to the target and waits
+// for the result of the comparison
+func (s *resumableSource) verify(conn net.Conn, writer io.Writer) error {
+	s.logger.Info("Verifying the target", "range size", s.verifyRangeSize)
+	bw := bufio.NewWriter(writer)
+	buf := make([]byte, 1<<20)
+	for offset := int64(0); offset < s.size; offset += s.verifyRangeSize {
+		_, length := segmentBounds(offset/s.verifyRangeSize, s.size, s.verifyRangeSize)
+		sum, err := hashRange(s.reader, offset, length, buf)
+		if err != nil {
+			return err
+		}
+		if _, err := bw.Write(sum[:]); err != nil {
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	var size uint32
+	if err := binary.Read(conn, binary.LittleEndian, &size); err != nil {
+		return fmt.Errorf("could not read verification result: %w", err)
+	}
+	if size > maxVerificationResultSize {
+		return fmt.Errorf("verification result is too large: %d bytes", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(conn, data); err != nil {
+		return fmt.Errorf("could not read verification result: %w", err)
+	}
+	result := &verificationResult{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("invalid verification result: %w", err)
+	}
+	s.verification = result
+	logVerification(s.logger, result)
+	return nil
+}
+
+// verify compares the hash of every range of the target with the one sent by
+// the source and sends back the result
+func (t *resumableTarget) verify(conn net.Conn, writer io.Writer, h *hello) error {
+	if h.VerifyRangeSize <= 0 {
+		return fmt.Errorf("invalid verification range size %d", h.VerifyRangeSize)
+	}
+	t.logger.Info("Verifying the target", "range size", h.VerifyRangeSize)
+	result := &verificationResult{Match: true, TotalBytes: h.Size, RangeSize: h.VerifyRangeSize}
+	buf := make([]byte, 1<<20)
+	for offset := int64(0); offset < h.Size; offset += h.VerifyRangeSize {
+		_, length := segmentBounds(offset/h.VerifyRangeSize, h.Size, h.VerifyRangeSize)
+		sum, err := hashRange(t.writer, offset, length, buf)
+		if err != nil {
+			return err
+		}
+		var sourceSum [sha256.Size]byte
+		if _, err := io.ReadFull(conn, sourceSum[:]); err != nil {
+			return fmt.Errorf("could not read source hash: %w", err)
+		}
+		if sum != sourceSum {
+			result.addMismatch(offset, length)
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if err := binary.Write(writer, binary.LittleEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	t.verification = result
+	logVerification(t.logger, result)
+	return nil
+}
+
+func logVerification(logger logr.Logger, result *verificationResult) {
+	if result.Match {
+		logger.Info("Verification complete, the target matches the source")
+	} else {
+		logger.Info("Verification complete, the target differs from the source",
+			"mismatched bytes", result.MismatchedBytes)
+	}
+}
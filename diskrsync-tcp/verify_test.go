@@ -0,0 +1,181 @@
+/*
+Copyright © 2023 The VolSync authors
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const testVerifyRangeSize = 64 * 1024
+
+var _ = Describe("Verification", func() {
+	var sourceData []byte
+	var targetFile *os.File
+	var source *resumableSource
+	var target *resumableTarget
+	var checkpointFile string
+
+	BeforeEach(func() {
+		sourceData = randomData()
+		source = newTestSource(sourceData, "test")
+		source.verifyRangeSize = testVerifyRangeSize
+
+		dir := GinkgoT().TempDir()
+		checkpointFile = filepath.Join(dir, "checkpoint")
+		var err error
+		targetFile, err = os.Create(filepath.Join(dir, "target"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(targetFile.Close)
+		target = newTestTarget(targetFile, checkpointFile)
+	})
+
+	It("verifies the target after the transfer", func() {
+		source.opts.verify = true
+		sourceErr, targetErr := runTransfer(source, target, 0)
+		Expect(sourceErr).NotTo(HaveOccurred())
+		Expect(targetErr).NotTo(HaveOccurred())
+		Expect(os.ReadFile(targetFile.Name())).To(Equal(sourceData))
+
+		Expect(target.verification).NotTo(BeNil())
+		Expect(target.verification.Match).To(BeTrue())
+		Expect(target.verification.TotalBytes).To(Equal(int64(len(sourceData))))
+		Expect(target.verification.MismatchedRanges).To(BeEmpty())
+		Expect(source.verification).To(Equal(target.verification))
+		Expect(checkpointFile).NotTo(BeAnExistingFile())
+	})
+
+	It("doesn't verify unless requested", func() {
+		sourceErr, targetErr := runTransfer(source, target, 0)
+		Expect(sourceErr).NotTo(HaveOccurred())
+		Expect(targetErr).NotTo(HaveOccurred())
+		Expect(source.verification).To(BeNil())
+		Expect(target.verification).To(BeNil())
+	})
+
+	When("only verifying", func() {
+		var targetData []byte
+
+		BeforeEach(func() {
+			source.opts.verifyOnly = true
+			targetData = bytes.Clone(sourceData)
+		})
+
+		JustBeforeEach(func() {
+			_, err := targetFile.WriteAt(targetData, 0)
+			Expect(err).NotTo(HaveOccurred())
+			target.size = int64(len(targetData))
+			sourceErr, targetErr := runTransfer(source, target, 0)
+			Expect(sourceErr).NotTo(HaveOccurred())
+			Expect(targetErr).NotTo(HaveOccurred())
+			Expect(source.verification).To(Equal(target.verification))
+			// The target must not have been modified
+			Expect(os.ReadFile(targetFile.Name())).To(Equal(targetData))
+			Expect(checkpointFile).NotTo(BeAnExistingFile())
+		})
+
+		It("reports a match", func() {
+			Expect(target.verification.Match).To(BeTrue())
+			Expect(target.verification.MismatchedBytes).To(BeZero())
+		})
+
+		When("the target differs", func() {
+			BeforeEach(func() {
+				// Corrupt the 2nd, 3rd and last ranges
+				targetData[testVerifyRangeSize+10]++
+				targetData[2*testVerifyRangeSize+20]++
+				targetData[len(targetData)-1]++
+			})
+
+			It("reports the mismatched ranges", func() {
+				Expect(target.verification.Match).To(BeFalse())
+				Expect(target.verification.MismatchedRanges).To(Equal([]byteRange{
+					{Offset: testVerifyRangeSize, Length: 2 * testVerifyRangeSize},
+					{Offset: int64(len(targetData)) - testVerifyRangeSize, Length: testVerifyRangeSize},
+				}))
+				Expect(target.verification.MismatchedBytes).To(Equal(int64(3 * testVerifyRangeSize)))
+			})
+		})
+
+		When("the target is smaller than the source", func() {
+			BeforeEach(func() {
+				targetData = targetData[:len(targetData)-testVerifyRangeSize/2]
+			})
+
+			It("reports the missing data as mismatched", func() {
+				Expect(target.verification.Match).To(BeFalse())
+				Expect(target.verification.MismatchedRanges).To(Equal([]byteRange{{
+					Offset: int64(len(sourceData)) - testVerifyRangeSize,
+					Length: testVerifyRangeSize,
+				}}))
+			})
+		})
+	})
+
+	It("limits the number of reported ranges", func() {
+		result := &verificationResult{Match: true}
+		for i := int64(0); i < 2*maxReportedRanges; i++ {
+			// Leave a gap between the ranges so they aren't merged
+			result.addMismatch(i*2*testVerifyRangeSize, testVerifyRangeSize)
+		}
+		Expect(result.MismatchedRanges).To(HaveLen(maxReportedRanges))
+		Expect(result.Truncated).To(BeTrue())
+		Expect(result.MismatchedBytes).To(Equal(int64(2 * maxReportedRanges * testVerifyRangeSize)))
+	})
+
+	It("keeps the control file small enough to be published by the mover", func() {
+		result := &verificationResult{TotalBytes: 1 << 50, RangeSize: testVerifyRangeSize}
+		for i := int64(0); i < maxReportedRanges; i++ {
+			result.addMismatch((1<<40)+i*2*testVerifyRangeSize, testVerifyRangeSize)
+		}
+		Expect(result.Truncated).To(BeFalse())
+
+		controlFile := filepath.Join(GinkgoT().TempDir(), "complete")
+		Expect(createControlFile(controlFile, result)).To(Succeed())
+		data, err := os.ReadFile(controlFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(data)).To(BeNumerically("<=", maxControlFileSize))
+		written := &verificationResult{}
+		Expect(json.Unmarshal(data, written)).To(Succeed())
+		Expect(written.Truncated).To(BeTrue())
+		Expect(len(written.MismatchedRanges)).To(BeNumerically("<", maxReportedRanges))
+		Expect(written.MismatchedRanges).To(Equal(result.MismatchedRanges[:len(written.MismatchedRanges)]))
+		Expect(written.MismatchedBytes).To(Equal(result.MismatchedBytes))
+	})
+
+	It("prints the result and writes it to the control file", func() {
+		result := &verificationResult{Match: true, TotalBytes: 42, RangeSize: testVerifyRangeSize}
+		out := &strings.Builder{}
+		Expect(result.print(out)).To(Succeed())
+		Expect(out.String()).To(HavePrefix(verifyPrefix))
+
+		controlFile := filepath.Join(GinkgoT().TempDir(), "control", "complete")
+		Expect(createControlFile(controlFile, result)).To(Succeed())
+		data, err := os.ReadFile(controlFile)
+		Expect(err).NotTo(HaveOccurred())
+		written := &verificationResult{}
+		Expect(json.Unmarshal(data, written)).To(Succeed())
+		Expect(written).To(Equal(result))
+		Expect(strings.TrimSpace(strings.TrimPrefix(out.String(), verifyPrefix))).To(Equal(string(data)))
+	})
+})
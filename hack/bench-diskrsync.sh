@@ -0,0 +1,86 @@
+#! /bin/bash
+
+set -e -o pipefail
+
+# Benchmarks diskrsync-tcp between two file-backed loop devices on the local
+# machine with different numbers of streams. For each stream count, a full
+# copy to an empty device and a re-sync of identical devices (hashing only)
+# are timed. Must be run as root to set up the loop devices.
+
+# Size of the devices
+BENCH_SIZE="${BENCH_SIZE:-4G}"
+# Stream counts to benchmark
+BENCH_STREAMS="${BENCH_STREAMS:-1 2 4 8}"
+# Directory for the backing files, it must have room for two devices
+BENCH_DIR="${BENCH_DIR:-$(mktemp -d)}"
+# Port used for the transfers
+BENCH_PORT="${BENCH_PORT:-8765}"
+
+SCRIPT_DIR="$(dirname "$(realpath "$0")")"
+
+if [[ $EUID -ne 0 ]]; then
+    echo "Must be run as root to set up the loop devices"
+    exit 1
+fi
+
+SOURCE_FILE="$BENCH_DIR/source.img"
+TARGET_FILE="$BENCH_DIR/target.img"
+BINARY="$BENCH_DIR/diskrsync-tcp"
+SOURCE_DEV=""
+TARGET_DEV=""
+
+# shellcheck disable=SC2317  # It's reachable due to the TRAP
+function cleanup() {
+    [[ -n "$SOURCE_DEV" ]] && losetup -d "$SOURCE_DEV"
+    [[ -n "$TARGET_DEV" ]] && losetup -d "$TARGET_DEV"
+    rm -f "$SOURCE_FILE" "$TARGET_FILE" "$BINARY" "$BENCH_DIR/complete"
+    rmdir "$BENCH_DIR" 2> /dev/null || true
+}
+trap cleanup EXIT
+
+echo "Building diskrsync-tcp..."
+(cd "$SCRIPT_DIR/.." && go build -o "$BINARY" ./diskrsync-tcp/)
+
+echo "Creating $BENCH_SIZE devices in $BENCH_DIR..."
+truncate -s "$BENCH_SIZE" "$SOURCE_FILE"
+SIZE_BYTES="$(stat -c %s "$SOURCE_FILE")"
+# Random data, so that neither compression nor zero detection helps
+head -c "$SIZE_BYTES" /dev/zero |
+    openssl enc -aes-128-ctr -pass pass:volsync -nosalt -pbkdf2 -out "$SOURCE_FILE"
+SOURCE_DEV="$(losetup -f --show "$SOURCE_FILE")"
+
+# Replaces the target with an empty device
+function reset_target() {
+    [[ -n "$TARGET_DEV" ]] && losetup -d "$TARGET_DEV"
+    rm -f "$TARGET_FILE"
+    truncate -s "$BENCH_SIZE" "$TARGET_FILE"
+    TARGET_DEV="$(losetup -f --show "$TARGET_FILE")"
+}
+
+# Transfers the source to the target with the given number of streams and
+# prints how long it took
+function transfer() {
+    local streams="$1"
+    rm -f "$BENCH_DIR/complete"
+    "$BINARY" "$TARGET_DEV" --target --port "$BENCH_PORT" --streams "$streams" \
+        --control-file "$BENCH_DIR/complete" --progress-interval 1h > /dev/null &
+    local target_pid=$!
+    local start
+    start=$(date +%s.%N)
+    "$BINARY" "$SOURCE_DEV" --source --target-address 127.0.0.1 --port "$BENCH_PORT" \
+        --streams "$streams" --progress-interval 1h > /dev/null
+    wait "$target_pid"
+    awk -v start="$start" -v end="$(date +%s.%N)" 'BEGIN { printf "%.1f\n", end - start }'
+}
+
+printf "%-8s %-12s %-12s\n" "streams" "copy (s)" "re-sync (s)"
+for streams in $BENCH_STREAMS; do
+    reset_target
+    copy="$(transfer "$streams")"
+    if ! cmp -s "$SOURCE_DEV" "$TARGET_DEV"; then
+        echo "ERROR: target differs from the source after the copy with $streams streams"
+        exit 1
+    fi
+    resync="$(transfer "$streams")"
+    printf "%-8s %-12s %-12s\n" "$streams" "$copy" "$resync"
+done
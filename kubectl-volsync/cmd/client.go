@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -47,6 +48,9 @@ func newClient(kubeContext string) (client.Client, error) {
 	if err := corev1.AddToScheme(scheme); err != nil {
 		return nil, err
 	}
+	if err := storagev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
 	if err := volsyncv1alpha1.AddToScheme(scheme); err != nil {
 		return nil, err
 	}
@@ -60,16 +60,17 @@ func parseCapacity(flagSet *pflag.FlagSet, flagName string) (*resource.Quantity,
 	return &capacity, nil
 }
 
-// Parse CopyMethod from a flag
+// Parse CopyMethod from a flag. Clone and FreezeSnapshot are only allowed for a source.
 func parseCopyMethod(flagSet *pflag.FlagSet, flagName string,
-	allowClone bool) (*volsyncv1alpha1.CopyMethodType, error) {
+	isSource bool) (*volsyncv1alpha1.CopyMethodType, error) {
 	allowedMethods := []volsyncv1alpha1.CopyMethodType{
 		volsyncv1alpha1.CopyMethodDirect,
 		volsyncv1alpha1.CopyMethodNone,
 		volsyncv1alpha1.CopyMethodSnapshot,
 	}
-	if allowClone {
-		allowedMethods = append(allowedMethods, volsyncv1alpha1.CopyMethodClone)
+	if isSource {
+		allowedMethods = append(allowedMethods, volsyncv1alpha1.CopyMethodClone,
+			volsyncv1alpha1.CopyMethodFreezeSnapshot)
 	}
 
 	cm, err := flagSet.GetString(flagName)
@@ -104,5 +104,17 @@ var _ = Describe("CLI parsing functions", func() {
 			Expect(cm).NotTo(BeNil())
 			Expect(*cm).To(Equal(volsyncv1alpha1.CopyMethodClone))
 		})
+		It("only allows FreezeSnapshot for a source", func() {
+			Expect(fset.Set(flagname, "freezesnapshot")).To(Succeed())
+			// Not allowed
+			cm, err := parseCopyMethod(fset, flagname, false)
+			Expect(err).To(HaveOccurred())
+			Expect(cm).To(BeNil())
+			// Allowed
+			cm, err = parseCopyMethod(fset, flagname, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cm).NotTo(BeNil())
+			Expect(*cm).To(Equal(volsyncv1alpha1.CopyMethodFreezeSnapshot))
+		})
 	})
 })
@@ -23,6 +23,7 @@ import (
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -250,9 +251,16 @@ func (rr *replicationRelationship) Apply(ctx context.Context, srcClient client.C
 
 	var dstPVC *corev1.PersistentVolumeClaim
 	if rr.data.Destination.Destination.CopyMethod == volsyncv1alpha1.CopyMethodSnapshot {
-		// We need to ensure the RD has defaults based on the source volume
-		if rr.data.Destination.Destination.Capacity == nil {
-			capacity := srcPVC.Spec.Resources.Requests[corev1.ResourceStorage]
+		// We need to ensure the RD has defaults based on the source volume.
+		// The capacity follows the source volume as it grows so that the
+		// destination volume gets expanded.
+		capacity := srcPVC.Spec.Resources.Requests[corev1.ResourceStorage]
+		dstCapacity := rr.data.Destination.Destination.Capacity
+		if dstCapacity == nil || dstCapacity.Cmp(capacity) < 0 {
+			if dstCapacity != nil {
+				klog.Infof("source PVC has grown, raising destination capacity from %s to %s",
+					dstCapacity.String(), capacity.String())
+			}
 			rr.data.Destination.Destination.Capacity = &capacity
 		}
 		if len(rr.data.Destination.Destination.AccessModes) == 0 {
@@ -305,9 +313,9 @@ func (rr *replicationRelationship) ensureDestinationPVC(ctx context.Context, c c
 	// exists, (2) create it if it doesn't, (3) have a copy of the object either
 	// way
 	_, err := ctrlutil.CreateOrUpdate(ctx, c, pvc, func() error {
-		// Only modify the PVC if we're creating it
+		// Only expand the PVC if it already exists
 		if !pvc.CreationTimestamp.IsZero() {
-			return nil
+			return expandDestinationPVC(ctx, c, pvc, capacity)
 		}
 
 		klog.Infof("creating destination PVC: %v/%v", pvc.Namespace, pvc.Name)
@@ -329,6 +337,37 @@ func (rr *replicationRelationship) ensureDestinationPVC(ctx context.Context, c c
 	return pvc, nil
 }
 
+// expandDestinationPVC raises the capacity requested by an existing
+// destination PVC to capacity, so it keeps up with a growing source volume.
+// The PVC is only expanded if it is bound and its StorageClass allows volume
+// expansion.
+func expandDestinationPVC(ctx context.Context, c client.Client, pvc *corev1.PersistentVolumeClaim,
+	capacity resource.Quantity) error {
+	current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if capacity.Cmp(current) <= 0 || pvc.Status.Phase != corev1.ClaimBound {
+		return nil
+	}
+
+	allowed := false
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+		sc := &storagev1.StorageClass{}
+		if err := c.Get(ctx, types.NamespacedName{Name: *pvc.Spec.StorageClassName}, sc); err != nil {
+			return err
+		}
+		allowed = sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+	}
+	if !allowed {
+		klog.Warningf("unable to expand destination PVC %v/%v to %s: its StorageClass does not allow volume expansion",
+			pvc.Namespace, pvc.Name, capacity.String())
+		return nil
+	}
+
+	klog.Infof("expanding destination PVC %v/%v from %s to %s", pvc.Namespace, pvc.Name,
+		current.String(), capacity.String())
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = capacity
+	return nil
+}
+
 func (rr *replicationRelationship) applyDestination(ctx context.Context,
 	c client.Client, dstPVC *corev1.PersistentVolumeClaim) (*string, *corev1.Secret, error) {
 	params := rr.data.Destination
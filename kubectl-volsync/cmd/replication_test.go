@@ -26,6 +26,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
@@ -328,6 +329,38 @@ var _ = Describe("Replication relationships", func() {
 				Expect(dstPVC.Spec.AccessModes).To(ConsistOf(existingPVC.Spec.AccessModes))
 				Expect(*dstPVC.Spec.Resources.Requests.Storage()).To(Equal(*existingPVC.Spec.Resources.Requests.Storage()))
 			})
+			When("the source PVC has grown beyond it", func() {
+				BeforeEach(func() {
+					srcPVC.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("50Gi")
+					existingPVC.Status.Phase = corev1.ClaimBound
+					Expect(k8sClient.Status().Update(ctx, existingPVC)).To(Succeed())
+				})
+				It("is expanded if the StorageClass allows it", func() {
+					sc := &storagev1.StorageClass{
+						ObjectMeta:           metav1.ObjectMeta{Name: "thesc"},
+						Provisioner:          "fake.csi.driver",
+						AllowVolumeExpansion: ptr.To(true),
+					}
+					Expect(k8sClient.Create(ctx, sc)).To(Succeed())
+					DeferCleanup(k8sClient.Delete, ctx, sc)
+
+					dstPVC, err := repRel.ensureDestinationPVC(ctx, k8sClient, srcPVC)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(*dstPVC.Spec.Resources.Requests.Storage()).To(Equal(resource.MustParse("50Gi")))
+				})
+				It("keeps its capacity if the StorageClass does not allow expansion", func() {
+					sc := &storagev1.StorageClass{
+						ObjectMeta:  metav1.ObjectMeta{Name: "thesc"},
+						Provisioner: "fake.csi.driver",
+					}
+					Expect(k8sClient.Create(ctx, sc)).To(Succeed())
+					DeferCleanup(k8sClient.Delete, ctx, sc)
+
+					dstPVC, err := repRel.ensureDestinationPVC(ctx, k8sClient, srcPVC)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(*dstPVC.Spec.Resources.Requests.Storage()).To(Equal(resource.MustParse("45Gi")))
+				})
+			})
 		})
 	})
 })
@@ -58,6 +58,7 @@ import (
 	"github.com/backube/volsync/controllers/mover/syncthing"
 	"github.com/backube/volsync/controllers/platform"
 	"github.com/backube/volsync/controllers/utils"
+	"github.com/backube/volsync/controllers/volumehandler"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -129,6 +130,8 @@ func addCommandFlags(probeAddr *string, metricsAddr *string, enableLeaderElectio
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&utils.SCCName, "scc-name",
 		utils.DefaultSCCName, "The name of the volsync security context constraint")
+	flag.StringVar(&volumehandler.KubeletRootDir, "kubelet-root-dir", volumehandler.DefaultKubeletRootDir,
+		"The root directory of the kubelet on the nodes, used to freeze the filesystem of mounted volumes")
 	opts := zap.Options{
 		Development: true,
 		TimeEncoder: zapcore.ISO8601TimeEncoder,
@@ -224,6 +227,9 @@ func main() {
 
 	initPodLogsClient(cfg)
 
+	// The filesystem freeze helper is part of the mover image
+	volumehandler.FreezeContainerImage = rsync.ContainerImage()
+
 	if err = (&controllers.ReplicationSourceReconciler{
 		Client:        mgr.GetClient(),
 		Log:           ctrl.Log.WithName("controllers").WithName("ReplicationSource"),
@@ -249,10 +255,11 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.VolumePopulatorReconciler{
-		Client:        mgr.GetClient(),
-		Log:           ctrl.Log.WithName("controllers").WithName("VolumePopulator"),
-		Scheme:        mgr.GetScheme(),
-		EventRecorder: mgr.GetEventRecorderFor("volsync-controller"),
+		Client:             mgr.GetClient(),
+		Log:                ctrl.Log.WithName("controllers").WithName("VolumePopulator"),
+		Scheme:             mgr.GetScheme(),
+		EventRecorder:      mgr.GetEventRecorderFor("volsync-controller"),
+		CopyContainerImage: rsync.ContainerImage(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VolumePopulator")
 		os.Exit(1)
@@ -0,0 +1,38 @@
+#! /bin/bash
+
+set -e -o pipefail
+
+echo "VolSync freeze container version: ${version:-unknown}"
+
+function error {
+    rc="$1"
+    shift
+    echo "error: $*"
+    exit "$rc"
+}
+
+[[ -n "${MOUNT_PATH}" ]] || error 1 "MOUNT_PATH must be defined"
+[[ -n "${FREEZE_TIMEOUT}" ]] || error 1 "FREEZE_TIMEOUT must be defined"
+[[ -n "${FROZEN_MARKER}" ]] || error 1 "FROZEN_MARKER must be defined"
+[[ -d "${MOUNT_PATH}" ]] || error 1 "volume is not mounted at ${MOUNT_PATH}"
+
+function unfreeze {
+    rm -f "${FROZEN_MARKER}"
+    fsfreeze --unfreeze "${MOUNT_PATH}"
+    echo "Filesystem unfrozen"
+}
+
+# The controller terminates the pod once the snapshot has been taken
+trap 'unfreeze; exit 0' TERM INT
+
+fsfreeze --freeze "${MOUNT_PATH}"
+touch "${FROZEN_MARKER}"
+echo "Filesystem frozen"
+
+# Never keep the filesystem frozen for longer than the timeout. Sleep in the
+# background so that the trap runs as soon as the pod is terminated.
+sleep "${FREEZE_TIMEOUT}" &
+wait $!
+
+unfreeze
+error 2 "timed out after ${FREEZE_TIMEOUT}s waiting for the snapshot"
@@ -0,0 +1,29 @@
+#! /bin/bash
+
+set -e -o pipefail
+
+echo "VolSync unfreeze container version: ${version:-unknown}"
+
+function error {
+    rc="$1"
+    shift
+    echo "error: $*"
+    exit "$rc"
+}
+
+[[ -n "${MOUNT_PATH}" ]] || error 1 "MOUNT_PATH must be defined"
+
+if [[ ! -d "${MOUNT_PATH}" ]]; then
+    echo "Volume is not mounted at ${MOUNT_PATH}, nothing to unfreeze"
+    exit 0
+fi
+
+# The freeze helper failed, it may not have unfrozen the filesystem before
+# it was killed. Unfreezing a filesystem that isn't frozen fails with EINVAL.
+if output=$(fsfreeze --unfreeze "${MOUNT_PATH}" 2>&1); then
+    echo "Filesystem unfrozen"
+elif [[ "${output}" == *"Invalid argument"* ]]; then
+    echo "Filesystem was not frozen"
+else
+    error 1 "${output}"
+fi
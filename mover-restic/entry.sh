@@ -230,10 +230,13 @@ function select_restic_snapshot_to_restore() {
 
 
 #######################################
-# Restores from a selected snapshot if
-# RESTORE_AS_OF is provided, otherwise
-# restores from the latest restic snapshot
+# Restores from the snapshot given by
+# RESTORE_SNAPSHOT_ID, or from a selected
+# snapshot if RESTORE_AS_OF is provided,
+# otherwise restores from the latest
+# restic snapshot
 # Globals:
+#   RESTORE_SNAPSHOT_ID
 #   RESTORE_AS_OF
 #   DATA_DIR
 #   RESTIC_HOST
@@ -242,9 +245,13 @@ function select_restic_snapshot_to_restore() {
 #######################################
 function do_restore {
     echo "=== Starting restore ==="
-    # restore from specific snapshot specified by timestamp, or latest
+    # restore from specific snapshot specified by id or timestamp, or latest
     local snapshot_id
-    snapshot_id=$(select_restic_snapshot_to_restore)
+    if [[ -n ${RESTORE_SNAPSHOT_ID} ]]; then
+        snapshot_id="${RESTORE_SNAPSHOT_ID}"
+    else
+        snapshot_id=$(select_restic_snapshot_to_restore)
+    fi
     if [[ -z ${snapshot_id} ]]; then
         echo "No eligible snapshots found"
     else
@@ -2,10 +2,24 @@
 
 set -e -o pipefail
 
-DESTINATION_PORT="${DESTINATION_PORT:-8000}"
-if [[ -z "$DESTINATION_ADDRESS" ]]; then
-    echo "Remote host address must be provided in DESTINATION_ADDRESS"
-    exit 1
+# The client normally runs on the source and pushes data to the destination.
+# When MOVER_ROLE is "destination", the source is the one listening, and the
+# client pulls the data from it instead.
+MOVER_ROLE="${MOVER_ROLE:-source}"
+if [[ $MOVER_ROLE == "destination" ]]; then
+    REMOTE_ADDRESS="$SOURCE_ADDRESS"
+    REMOTE_PORT="${SOURCE_PORT:-8000}"
+    if [[ -z "$REMOTE_ADDRESS" ]]; then
+        echo "Remote host address must be provided in SOURCE_ADDRESS"
+        exit 1
+    fi
+else
+    REMOTE_ADDRESS="$DESTINATION_ADDRESS"
+    REMOTE_PORT="${DESTINATION_PORT:-8000}"
+    if [[ -z "$REMOTE_ADDRESS" ]]; then
+        echo "Remote host address must be provided in DESTINATION_ADDRESS"
+        exit 1
+    fi
 fi
 
 STUNNEL_CONF=/tmp/stunnel-client.conf
@@ -14,6 +28,20 @@ PSK_FILE=/keys/psk.txt
 STUNNEL_LISTEN_PORT=9000
 SOURCE="/data"
 BLOCK_SOURCE="/dev/block"
+# Progress of a block transfer, so it can be resumed if the connection drops
+# or the Pod is replaced. The destination keeps it on its own volume.
+CHECKPOINT_FILE=/checkpoint/diskrsync.checkpoint
+if [[ ! -w "$(dirname "$CHECKPOINT_FILE")" ]]; then
+    CHECKPOINT_FILE=/tmp/diskrsync.checkpoint
+fi
+CONTROL_FILE=/tmp/control/complete
+# Tells the destination its volume is full, along with the space the data needs
+NOSPACE_FILE=/tmp/control/nospace
+# The source publishes the result of a verification from the control file here,
+# where the controller can read it
+RESULT_FILE=/dev/termination-log
+TOPLEVEL_LIST=/tmp/toplevel
+RSYNC_OUTPUT=/tmp/rsync-output
 
 SCRIPT="$(realpath "$0")"
 SCRIPT_DIR="$(dirname "$SCRIPT")"
@@ -31,12 +59,12 @@ if [[ ! -r $PSK_FILE ]]; then
 fi
 
 if [[ ! -d $SOURCE ]] && ! test -b $BLOCK_SOURCE; then
-    echo "ERROR: source location not found"
+    echo "ERROR: $MOVER_ROLE location not found"
     exit 1
 fi
 
 if ! test -b $BLOCK_SOURCE; then
-    echo "Source PVC volumeMode is filesystem"
+    echo "${MOVER_ROLE^} PVC volumeMode is filesystem"
 
     cat - > "$STUNNEL_CONF" <<STUNNEL_CONF
 ; Global options
@@ -53,39 +81,54 @@ PSKsecrets = $PSK_FILE
 accept = 127.0.0.1:$STUNNEL_LISTEN_PORT
 ; We are the client
 client = yes
-connect = $DESTINATION_ADDRESS:$DESTINATION_PORT
+connect = $REMOTE_ADDRESS:$REMOTE_PORT
 STUNNEL_CONF
 
 ##############################
 ## Print version information
 rsync --version
-else
-    echo "Source PVC volumeMode is block"
+stunnel -version "$STUNNEL_CONF"
 
-    cat - > "$STUNNEL_CONF" <<STUNNEL_CONF
-; Global options
-debug = debug
-foreground = no
-output = /dev/stdout
-pid = $STUNNEL_PID_FILE
-syslog = no
+    ##############################
+    ## Start stunnel to wait for incoming connections
+    stunnel "$STUNNEL_CONF"
+    trap stop_stunnel EXIT
+else
+    # diskrsync-tcp secures the connection itself using the pre-shared key, so
+    # stunnel isn't needed
+    echo "${MOVER_ROLE^} PVC volumeMode is block"
+fi
 
-[diskrsync]
-ciphers = PSK
-PSKsecrets = $PSK_FILE
-; Port to listen for incoming connection from diskrsync
-accept = 127.0.0.1:$STUNNEL_LISTEN_PORT
-; We are the client
-client = yes
-connect = $DESTINATION_ADDRESS:$DESTINATION_PORT
-STUNNEL_CONF
+# Build the rsync options from the requested transfer options
+RSYNC_OPTS=(-aAhHSx)
+if [[ -z "$COMPRESSION_LEVEL" ]]; then
+    RSYNC_OPTS+=(-z)
+elif [[ "$COMPRESSION_LEVEL" -ne 0 ]]; then
+    RSYNC_OPTS+=(-z "--compress-level=$COMPRESSION_LEVEL")
+fi
+if [[ -n "$BANDWIDTH_LIMIT" ]]; then
+    RSYNC_OPTS+=("--bwlimit=$BANDWIDTH_LIMIT")
+fi
+if [[ "$WHOLE_FILE" == "true" ]]; then
+    RSYNC_OPTS+=(--whole-file)
+fi
+if [[ "$CHECKSUM" == "true" ]]; then
+    RSYNC_OPTS+=(--checksum)
+fi
+if [[ "$PARTIAL" == "true" ]]; then
+    RSYNC_OPTS+=(--partial)
 fi
-stunnel -version "$STUNNEL_CONF"
 
-##############################
-## Start stunnel to wait for incoming connections
-stunnel "$STUNNEL_CONF"
-trap stop_stunnel EXIT
+DISKRSYNC_OPTS=()
+if [[ -n "$BANDWIDTH_LIMIT" ]]; then
+    DISKRSYNC_OPTS+=("--bwlimit=$BANDWIDTH_LIMIT")
+fi
+if [[ "$VERIFY_ONLY" == "true" ]]; then
+    # Compare the hashes of the source and destination without transferring
+    # any data. The destination follows the source's request, and keeps
+    # waiting for the next transfer afterwards.
+    DISKRSYNC_OPTS+=(--verify-only)
+fi
 
 # Sync files
 START_TIME=$SECONDS
@@ -94,19 +137,50 @@ RETRY=0
 DELAY=2
 FACTOR=2
 rc=1
+NO_SPACE=0
 set +e  # Don't exit on command failure
-echo "Syncing data to ${DESTINATION_ADDRESS}:${DESTINATION_PORT} ..."
+if [[ $MOVER_ROLE == "destination" ]]; then
+    echo "Syncing data from ${REMOTE_ADDRESS}:${REMOTE_PORT} ..."
+else
+    echo "Syncing data to ${REMOTE_ADDRESS}:${REMOTE_PORT} ..."
+fi
 while [[ $rc -ne 0 && $RETRY -lt $MAX_RETRIES ]]; do
     RETRY=$(( RETRY + 1 ))
-    if test -b $BLOCK_SOURCE; then
-      echo "calling diskrsync-tcp $BLOCK_SOURCE --source --target-address 127.0.0.1 --port $STUNNEL_LISTEN_PORT"
-      /diskrsync-tcp $BLOCK_SOURCE --source --target-address 127.0.0.1 --port $STUNNEL_LISTEN_PORT
+    if test -b $BLOCK_SOURCE && [[ $MOVER_ROLE == "destination" ]]; then
+      echo "calling diskrsync-tcp $BLOCK_SOURCE --target --source-address $REMOTE_ADDRESS --port $REMOTE_PORT"
+      /diskrsync-tcp $BLOCK_SOURCE --target --source-address "$REMOTE_ADDRESS" --port "$REMOTE_PORT" --tls-psk-file $PSK_FILE --control-file $CONTROL_FILE --checkpoint-file $CHECKPOINT_FILE "${DISKRSYNC_OPTS[@]}"
       rc=$?
+    elif test -b $BLOCK_SOURCE; then
+      echo "calling diskrsync-tcp $BLOCK_SOURCE --source --target-address $REMOTE_ADDRESS --port $REMOTE_PORT"
+      /diskrsync-tcp $BLOCK_SOURCE --source --target-address "$REMOTE_ADDRESS" --port "$REMOTE_PORT" --tls-psk-file $PSK_FILE --control-file $CONTROL_FILE --transfer-id "${TRANSFER_ID:-$HOSTNAME}" "${DISKRSYNC_OPTS[@]}"
+      rc=$?
+    elif [[ $MOVER_ROLE == "destination" ]]; then
+        # The listening source publishes the list of its top-level entries so
+        # that the 1st run can preserve as much as possible while still
+        # excluding the root directory (same as the push below)
+        rsync rsync://127.0.0.1:$STUNNEL_LISTEN_PORT/control/toplevel $TOPLEVEL_LIST
+        rc_l=$?
+        rc_a=0
+        if [[ $rc_l -eq 0 && -s $TOPLEVEL_LIST ]]; then
+            rsync "${RSYNC_OPTS[@]}" -r --from0 --files-from=$TOPLEVEL_LIST --exclude=lost+found --itemize-changes --info=stats2,misc2 rsync://127.0.0.1:$STUNNEL_LISTEN_PORT/data/ ${SOURCE}/ 2>&1 | tee "$RSYNC_OUTPUT"
+            rc_a=$?
+        elif [[ $rc_l -eq 0 ]]; then
+            echo "Skipping sync of empty source directory"
+        fi
+
+        # Delete extra files that exist on the destination but not on the source
+        rsync -rx --exclude=lost+found --ignore-existing --ignore-non-existing --delete --itemize-changes --info=stats2,misc2 rsync://127.0.0.1:$STUNNEL_LISTEN_PORT/data/ ${SOURCE}/
+        rc_b=$?
+        rc=$(( rc_l * 10000 + rc_a * 100 + rc_b ))
+    elif [[ "$VERIFY_ONLY" == "true" ]]; then
+        echo "Verifying data on the destination (no data will be transferred)"
+        rsync -aAHSx --checksum --dry-run --delete --stats --exclude=lost+found ${SOURCE}/ rsync://127.0.0.1:$STUNNEL_LISTEN_PORT/data
+        rc=$?
     else
         shopt -s dotglob  # Make * include dotfiles
         if [[ -n "$(ls -A -- ${SOURCE}/*)" ]]; then
             # 1st run preserves as much as possible, but excludes the root directory
-            rsync -aAhHSxz --exclude=lost+found --itemize-changes --info=stats2,misc2 ${SOURCE}/* rsync://127.0.0.1:$STUNNEL_LISTEN_PORT/data
+            rsync "${RSYNC_OPTS[@]}" --exclude=lost+found --itemize-changes --info=stats2,misc2 ${SOURCE}/* rsync://127.0.0.1:$STUNNEL_LISTEN_PORT/data 2>&1 | tee "$RSYNC_OUTPUT"
         else
             echo "Skipping sync of empty source directory"
         fi
@@ -120,7 +194,12 @@ while [[ $rc -ne 0 && $RETRY -lt $MAX_RETRIES ]]; do
         rc_b=$?
         rc=$(( rc_a * 100 + rc_b ))
     fi
-    if [[ $rc -ne 0 ]]; then
+    if [[ $rc -ne 0 && -s $RSYNC_OUTPUT ]] && grep -q "No space left on device" "$RSYNC_OUTPUT"; then
+        # Retrying won't help until the destination has been expanded
+        echo "The destination is out of space"
+        NO_SPACE=1
+        break
+    elif [[ $rc -ne 0 ]]; then
         echo "Syncronization failed. Retrying in $DELAY seconds. Retry ${RETRY}/${MAX_RETRIES}."
         sleep $DELAY
         DELAY=$(( DELAY * FACTOR ))
@@ -130,15 +209,45 @@ set -e  # Exit on command failure
 
 if test -b $BLOCK_SOURCE; then
     echo "diskrsync completed in $(( SECONDS - START_TIME ))s"
-else
+    if [[ $MOVER_ROLE != "destination" && -s $CONTROL_FILE ]]; then
+        cp "$CONTROL_FILE" "$RESULT_FILE"
+    fi
+elif [[ $MOVER_ROLE == "destination" ]]; then
     echo "rsync completed in $(( SECONDS - START_TIME ))s"
+    sync
 
     if [[ $rc -eq 0 ]]; then
+        # Tell the source to shutdown. Actual file contents don't matter
+        echo "Sending shutdown to remote..."
+        rsync "$SCRIPT" rsync://127.0.0.1:$STUNNEL_LISTEN_PORT/control/complete
+        echo "...done"
+        sleep 5  # Give time for the remote to shut down
+    elif [[ $NO_SPACE -eq 1 ]]; then
+        # Tell the controller the volume needs to be expanded. The size of the
+        # data isn't known here.
+        echo "Synchronization failed. rsync returned: $rc"
+        echo '{"noSpace":true}' > "$RESULT_FILE"
+    else
+        echo "Synchronization failed. rsync returned: $rc"
+    fi
+else
+    echo "rsync completed in $(( SECONDS - START_TIME ))s"
+
+    if [[ $rc -eq 0 && "$VERIFY_ONLY" == "true" ]]; then
+        # Nothing has been transferred, so the server is left waiting for the
+        # next synchronization instead of taking a new image
+        echo "Verification completed successfully"
+    elif [[ $rc -eq 0 ]]; then
         # Tell server to shutdown. Actual file contents don't matter
         echo "Sending shutdown to remote..."
         rsync "$SCRIPT" rsync://127.0.0.1:$STUNNEL_LISTEN_PORT/control/complete
         echo "...done"
         sleep 5  # Give time for the remote to shut down
+    elif [[ $NO_SPACE -eq 1 ]]; then
+        # Tell the server its volume is full and how much space the data needs
+        echo "Synchronization failed. rsync returned: $rc. Notifying remote..."
+        df --output=used -B1 ${SOURCE} | tail -n 1 | tr -d ' ' > "$RSYNC_OUTPUT"
+        rsync "$RSYNC_OUTPUT" rsync://127.0.0.1:$STUNNEL_LISTEN_PORT/control/nospace || echo "Unable to notify remote"
     else
         echo "Synchronization failed. rsync returned: $rc"
     fi
@@ -4,12 +4,30 @@ set -e -o pipefail
 
 RSYNC_PID_FILE=/tmp/rsyncd.pid
 CONTROL_FILE=/tmp/control/complete
+# Left by the source when the data doesn't fit on the destination volume. It
+# holds the number of bytes the data needs.
+NOSPACE_FILE=/tmp/control/nospace
+# The source publishes the result of a verification from the control file here,
+# where the controller can read it
+RESULT_FILE=/dev/termination-log
 RSYNCD_CONF=/tmp/rsyncd.conf
 STUNNEL_CONF=/tmp/stunnel.conf
 STUNNEL_PID_FILE=/tmp/stunnel.pid
 PSK_FILE=/keys/psk.txt
 STUNNEL_LISTEN_PORT=8000
 RSYNC_LOG=/tmp/rsyncd.log
+TOPLEVEL_LIST=/tmp/control/toplevel
+# Progress of a block transfer, so it can be resumed if the connection drops
+# or the Pod is replaced. The destination keeps it on its own volume.
+CHECKPOINT_FILE=/checkpoint/diskrsync.checkpoint
+if [[ ! -w "$(dirname "$CHECKPOINT_FILE")" ]]; then
+    CHECKPOINT_FILE=/tmp/diskrsync.checkpoint
+fi
+
+# The server normally runs on the destination and receives data from the
+# source. When MOVER_ROLE is "source", the destination will connect to us
+# instead and pull the data.
+MOVER_ROLE="${MOVER_ROLE:-destination}"
 
 SCRIPT_DIR="$(dirname "$(realpath "$0")")"
 cd "$SCRIPT_DIR"
@@ -23,14 +41,14 @@ TARGET="/data"
 BLOCK_TARGET="/dev/block"
 
 if [[ ! -d $TARGET ]] && ! test -b $BLOCK_TARGET; then
-    echo "ERROR: target location not found"
+    echo "ERROR: $MOVER_ROLE location not found"
     exit 1
 fi
 
 if [[ -d $TARGET ]]; then
     ##############################
     ## Filesystem volume, use rsync
-    echo "Destination PVC volumeMode is filesystem"
+    echo "${MOVER_ROLE^} PVC volumeMode is filesystem"
 
     mkdir -p "$(dirname "$CONTROL_FILE")"
 
@@ -45,6 +63,12 @@ if [[ -d $TARGET ]]; then
         RSYNC_GID=""
     fi
 
+    # Data must not be modified when it's being pulled from the source
+    DATA_READ_ONLY="false"
+    if [[ $MOVER_ROLE == "source" ]]; then
+        DATA_READ_ONLY="true"
+    fi
+
     cat - > "$RSYNCD_CONF" <<RSYNCD_CONF
 pid = $RSYNC_PID_FILE
 address = 127.0.0.1
@@ -63,6 +87,7 @@ $RSYNC_GID
 [data]
 comment = PVC data
 path = $TARGET
+read only = $DATA_READ_ONLY
 
 [control]
 comment = Control files for signaling
@@ -102,49 +127,52 @@ STUNNEL_CONF
     tail -f "$RSYNC_LOG" &
     TAIL_PID="$!"
 
-    rm -f "$CONTROL_FILE"
+    rm -f "$CONTROL_FILE" "$NOSPACE_FILE"
+
+    if [[ $MOVER_ROLE == "source" ]]; then
+        # List the top-level entries so the destination can preserve as much
+        # as possible while excluding the root directory itself
+        (cd "$TARGET" && find . -mindepth 1 -maxdepth 1 ! -name lost+found -printf '%P\0') > "$TOPLEVEL_LIST"
+    fi
 fi
 
 if test -b $BLOCK_TARGET; then
     ##############################
     ## block volume, use diskrsync-tcp
-    echo "Destination PVC volumeMode is block"
+    echo "${MOVER_ROLE^} PVC volumeMode is block"
+
+  DISKRSYNC_OPTS=()
+  if [[ -n "$BANDWIDTH_LIMIT" ]]; then
+    DISKRSYNC_OPTS+=("--bwlimit=$BANDWIDTH_LIMIT")
+  fi
+  if [[ "$VERIFY_ONLY" == "true" ]]; then
+    # Compare the hashes of the source and destination without transferring
+    # any data. The destination follows the source's request, and keeps
+    # waiting for the next transfer afterwards.
+    DISKRSYNC_OPTS+=(--verify-only)
+  fi
+
+  # diskrsync-tcp secures the connection itself using the pre-shared key, so
+  # stunnel isn't needed
+  if [[ $MOVER_ROLE == "source" ]]; then
+    /diskrsync-tcp $BLOCK_TARGET --source --listen --port $STUNNEL_LISTEN_PORT --tls-psk-file $PSK_FILE --control-file $CONTROL_FILE --transfer-id "${TRANSFER_ID:-$HOSTNAME}" "${DISKRSYNC_OPTS[@]}"&
+  else
+    /diskrsync-tcp $BLOCK_TARGET --target --port $STUNNEL_LISTEN_PORT --tls-psk-file $PSK_FILE --control-file $CONTROL_FILE --checkpoint-file $CHECKPOINT_FILE "${DISKRSYNC_OPTS[@]}"&
+  fi
+fi
 
+if [[ -d $TARGET ]]; then
     ##############################
-    ## Set up stunnel config
-    cat - > "$STUNNEL_CONF" <<STUNNEL_CONF
-; Global options
-debug = debug
-foreground = no
-output = /dev/stdout
-pid = $STUNNEL_PID_FILE
-syslog = no
-
-[diskrsync]
-ciphers = PSK
-PSKsecrets = $PSK_FILE
-; Port to listen for incoming connections from remote
-accept = :::$STUNNEL_LISTEN_PORT
-; We are the server
-client = no
-connect = 8888
-#exec = /diskrsync-tcp
-#execargs = diskrsync-tcp $BLOCK_TARGET --target --port 8888 --control-file $CONTROL_FILE
-STUNNEL_CONF
-
-  /diskrsync-tcp $BLOCK_TARGET --target --port 8888 --control-file $CONTROL_FILE&
+    ## Start stunnel to wait for incoming connections
+    echo "Starting stunnel..."
+    stunnel "$STUNNEL_CONF"
 fi
 
-##############################
-## Start stunnel to wait for incoming connections
-echo "Starting stunnel..."
-stunnel "$STUNNEL_CONF"
-
 ##############################
 ## Wait for the control file to be created, signaling that we should
 ## terminate
 echo "Waiting for control file to be created ($CONTROL_FILE)..."
-while [[ ! -e $CONTROL_FILE ]]; do
+while [[ ! -e $CONTROL_FILE && ! -e $NOSPACE_FILE ]]; do
     sleep 1
 done
 
@@ -153,10 +181,25 @@ sleep 5  # Give time for the rsync connection to finish
 ##############################
 ## Terminate stunnel
 echo "Shutting down..."
-kill -TERM "$(<"$STUNNEL_PID_FILE")"
 if [[ -d $TARGET ]]; then
+    kill -TERM "$(<"$STUNNEL_PID_FILE")"
     kill -TERM "$TAIL_PID"
 fi
 wait
 
+if [[ $MOVER_ROLE == "source" && -s $CONTROL_FILE ]]; then
+    cp "$CONTROL_FILE" "$RESULT_FILE"
+fi
+
 sync
+
+if [[ -e $NOSPACE_FILE ]]; then
+    # Tell the controller the volume needs to be expanded
+    REQUIRED_BYTES="$(<"$NOSPACE_FILE")"
+    if [[ ! $REQUIRED_BYTES =~ ^[0-9]+$ ]]; then
+        REQUIRED_BYTES=0
+    fi
+    echo "The volume is out of space, $REQUIRED_BYTES bytes are needed"
+    printf '{"noSpace":true,"requiredBytes":%d}' "$REQUIRED_BYTES" > "$RESULT_FILE"
+    exit 1
+fi
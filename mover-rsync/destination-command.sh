@@ -15,6 +15,13 @@ function do_shutdown {
     [[ -e "$PIDFILE" ]] && kill -SIGTERM "$(<"$PIDFILE")"
 }
 
+# The source found that the data doesn't fit on the destination volume. Record
+# how much space it needs where the controller can read it and shut down.
+function do_nospace {
+    printf '{"noSpace":true,"requiredBytes":%d}' "$1" > /tmp/nospace
+    do_shutdown 1
+}
+
 function do_rsync {
     # rsync changes are restricted to the target directory/block device of the container
     LANG=C rrsync /data
@@ -33,6 +40,9 @@ elif [[ "$SSH_ORIGINAL_COMMAND" =~ ^diskrsync( ) ]]; then
 # Source can tell us (destination) to shutdown & pass a numeric result code
 elif [[ "$SSH_ORIGINAL_COMMAND" =~ ^shutdown( )+([0-9]+)$ ]]; then
     do_shutdown "${BASH_REMATCH[2]}"
+# Source can tell us our volume is full & pass the number of bytes needed
+elif [[ "$SSH_ORIGINAL_COMMAND" =~ ^nospace( )+([0-9]+)$ ]]; then
+    do_nospace "${BASH_REMATCH[2]}"
 # Everything else is an error
 else
     echo "Invalid command: $SSH_ORIGINAL_COMMAND"
@@ -28,6 +28,10 @@ if [[ -e /tmp/exit_code ]]; then
         CODE="$CODE_IN"
     fi
 fi
+# Tell the controller the volume needs to be expanded
+if [[ -s /tmp/nospace ]]; then
+    cp /tmp/nospace /dev/termination-log
+fi
 sync
 echo "Exiting... Exit code: $CODE"
 exit "$CODE"
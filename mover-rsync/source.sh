@@ -12,6 +12,7 @@ if [[ -z "$DESTINATION_ADDRESS" ]]; then
 fi
 SOURCE="/data"
 BLOCK_SOURCE="/dev/block"
+RSYNC_OUTPUT=/tmp/rsync-output
 
 if [[ ! -d $SOURCE ]] && ! test -b $BLOCK_SOURCE; then
     echo "ERROR: source location not found"
@@ -69,11 +70,32 @@ if [[ "$DESTINATION_ADDRESS" =~ $IPV6_REGEX ]]; then
   fi
 fi
 
+# Build the rsync options from the requested transfer options
+RSYNC_OPTS=(-aAhHSx)
+if [[ -z "$COMPRESSION_LEVEL" ]]; then
+    RSYNC_OPTS+=(-z)
+elif [[ "$COMPRESSION_LEVEL" -ne 0 ]]; then
+    RSYNC_OPTS+=(-z "--compress-level=$COMPRESSION_LEVEL")
+fi
+if [[ -n "$BANDWIDTH_LIMIT" ]]; then
+    RSYNC_OPTS+=("--bwlimit=$BANDWIDTH_LIMIT")
+fi
+if [[ "$WHOLE_FILE" == "true" ]]; then
+    RSYNC_OPTS+=(--whole-file)
+fi
+if [[ "$CHECKSUM" == "true" ]]; then
+    RSYNC_OPTS+=(--checksum)
+fi
+if [[ "$PARTIAL" == "true" ]]; then
+    RSYNC_OPTS+=(--partial)
+fi
+
 MAX_RETRIES=5
 RETRY=0
 DELAY=2
 FACTOR=2
 rc=1
+NO_SPACE=0
 echo "Syncing data to ${URL_DESTINATION_ADDRESS}:${DESTINATION_PORT} ..."
 START_TIME=$SECONDS
 # Avoids exiting on rsync failure
@@ -84,11 +106,19 @@ do
     if test -b $BLOCK_SOURCE; then
       echo "calling diskrsync $BLOCK_SOURCE root@${URL_DESTINATION_ADDRESS}:/dev/block"
       diskrsync $BLOCK_SOURCE "root@${URL_DESTINATION_ADDRESS}":/dev/block
+    elif [[ "$VERIFY_ONLY" == "true" ]]; then
+      echo "Verifying data on the destination (no data will be transferred)"
+      rsync -aAHSx --checksum --dry-run --delete --stats $SOURCE/ "root@${URL_DESTINATION_ADDRESS}":.
     else
-      rsync -aAhHSxz --delete --itemize-changes --info=stats2,misc2 $SOURCE/ "root@${URL_DESTINATION_ADDRESS}":.
+      rsync "${RSYNC_OPTS[@]}" --delete --itemize-changes --info=stats2,misc2 $SOURCE/ "root@${URL_DESTINATION_ADDRESS}":. 2>&1 | tee "$RSYNC_OUTPUT"
     fi
     rc=$?
-    if [[ ${rc} -ne 0 ]]; then
+    if [[ ${rc} -ne 0 && -s $RSYNC_OUTPUT ]] && grep -q "No space left on device" "$RSYNC_OUTPUT"; then
+        # Retrying won't help until the destination has been expanded
+        echo "The destination is out of space"
+        NO_SPACE=1
+        break
+    elif [[ ${rc} -ne 0 ]]; then
         echo "Syncronization failed. Retrying in ${DELAY} seconds. Retry ${RETRY}/${MAX_RETRIES}."
         sleep ${DELAY}
         DELAY=$((DELAY * FACTOR ))
@@ -97,10 +127,20 @@ done
 set -e
 echo "Rsync completed in $(( SECONDS - START_TIME ))s"
 sync
-if [[ $rc -eq 0 ]]; then
+if [[ $rc -eq 0 && "$VERIFY_ONLY" == "true" ]]; then
+    # Nothing has been transferred, so the destination is left waiting for the
+    # next synchronization instead of taking a new image
+    echo "Verification completed successfully"
+elif [[ $rc -eq 0 ]]; then
     echo "Synchronization completed successfully. Notifying destination..."
     # ssh does not take [ip] format for ipv6, so use DESTINATION_ADDRESS rather than URL_DESTINATION_ADDRESS
     ssh "root@${DESTINATION_ADDRESS}" shutdown 0
+elif [[ $NO_SPACE -eq 1 ]]; then
+    # Let the destination report how much space the data needs, so its volume
+    # can be expanded
+    echo "Synchronization failed. rsync returned: $rc. Notifying destination..."
+    ssh "root@${DESTINATION_ADDRESS}" nospace "$(df --output=used -B1 $SOURCE | tail -n 1 | tr -d ' ')" || echo "Unable to notify destination"
+    exit $rc
 else
     echo "Synchronization failed. rsync returned: $rc"
     exit $rc